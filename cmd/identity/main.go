@@ -2,17 +2,22 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"time"
 
 	"github.com/ialekseychuk/my-place-identity/internal/config"
 	"github.com/ialekseychuk/my-place-identity/internal/handler"
 	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
 	"github.com/ialekseychuk/my-place-identity/internal/interceptor"
+	"github.com/ialekseychuk/my-place-identity/internal/policy"
+	"github.com/ialekseychuk/my-place-identity/internal/rebac"
 	"github.com/ialekseychuk/my-place-identity/internal/repository"
 	"github.com/ialekseychuk/my-place-identity/internal/usecase"
 
@@ -44,17 +49,181 @@ func main() {
 	// repositories
 	userRepo := repository.NewUserRepository(pool)
 	tokenRepo := repository.NewTokenRepository(pool)
+	membershipRepo := repository.NewMembershipRepository(pool)
+	invitationRepo := repository.NewInvitationRepository(pool)
+	permissionRepo := repository.NewPermissionRepository(pool)
+	customRoleRepo := repository.NewCustomRoleRepository(pool)
+	businessUnitRepo := repository.NewBusinessUnitRepository(pool)
+	ownershipTransferRepo := repository.NewOwnershipTransferRepository(pool)
+	verificationCodeRepo := repository.NewVerificationCodeRepository(pool)
+	auditRepo := repository.NewAuditRepository(pool)
+	relationTupleRepo := repository.NewRelationTupleRepository(pool)
+	policyConditionRepo := repository.NewPolicyConditionRepository(pool)
+	decisionLogRepo := repository.NewDecisionLogRepository(pool)
+	oauthClientRepo := repository.NewOAuthClientRepository(pool)
+	serviceAccountRepo := repository.NewServiceAccountRepository(pool)
+	authorizationCodeRepo := repository.NewAuthorizationCodeRepository(pool)
+	oauthConsentRepo := repository.NewOAuthConsentRepository(pool)
+	deviceAuthorizationRepo := repository.NewDeviceAuthorizationRepository(pool)
+	tokenExchangePolicyRepo := repository.NewTokenExchangePolicyRepository(pool)
+	qrLoginRepo := repository.NewQRLoginRepository(pool)
+	sharedDeviceRepo := repository.NewSharedDeviceRepository(pool)
+	linkedIdentityRepo := repository.NewLinkedIdentityRepository(pool)
+	federatedLoginStateRepo := repository.NewFederatedLoginStateRepository(pool)
+	accountMergeRequestRepo := repository.NewAccountMergeRequestRepository(pool)
+	userMergeRepo := repository.NewUserMergeRepository(pool)
+	userEventRepo := repository.NewUserEventRepository(pool)
+	transactor := repository.NewTransactor(pool)
+	pendingRegistrationRepo := repository.NewPendingRegistrationRepository(pool)
 
 	// jwt
 	jwtManager := infrastructure.NewJWTManager(config.JWT_SECRET)
+	idTokenSigner, err := infrastructure.LoadIDTokenSigner(config.OIDCSigningKeyPath)
+	if err != nil {
+		logrus.Fatalf("failed to load OIDC signing key: %v", err)
+	}
+	issuer := strings.TrimSuffix(config.OIDCIssuer, "/")
+	notifier := infrastructure.NewLogNotifier()
+	decisionLog := infrastructure.NewDecisionLogWriter(decisionLogRepo, config.DecisionLogBuffer, config.DecisionLogSampleRate)
+	go decisionLog.Run()
+
+	identityProviders, err := infrastructure.LoadIdentityProviders(config.FederationProvidersPath)
+	if err != nil {
+		logrus.Fatalf("failed to load identity providers: %v", err)
+	}
+	registrationPolicy, err := infrastructure.LoadRegistrationPolicy(config.RegistrationPolicyPath)
+	if err != nil {
+		logrus.Fatalf("failed to load registration policy: %v", err)
+	}
+	domainRules, err := infrastructure.NewDomainRuleStore(config.DomainRulesPath, config.Environment)
+	if err != nil {
+		logrus.Fatalf("failed to load domain rules: %v", err)
+	}
+	go domainRules.Run(config.DomainRulesReloadInterval)
+	trustedProxies, err := interceptor.ParseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		logrus.Fatalf("failed to parse trusted proxies: %v", err)
+	}
+
+	// relationship-based access control
+	rebacSchema, err := rebac.LoadSchema(config.RebacSchemaPath)
+	if err != nil {
+		logrus.Fatalf("failed to load relation schema: %v", err)
+	}
+	rebacEngine := rebac.NewEngine(rebacSchema, relationTupleRepo)
+
+	policyEvaluator, err := policy.NewEvaluator()
+	if err != nil {
+		logrus.Fatalf("failed to init policy evaluator: %v", err)
+	}
 
 	// usecases
 	loginUC := usecase.NewLogin(userRepo, tokenRepo, jwtManager, config.AccessTTL, config.RefreshTTL)
-	registerUC := usecase.NewRegister(userRepo, tokenRepo, jwtManager, config.AccessTTL, config.RefreshTTL)
-	refreshUC := usecase.NewRefresh(userRepo, tokenRepo, jwtManager, config.AccessTTL, config.RefreshTTL)
+	registerUC := usecase.NewRegister(userRepo, tokenRepo, pendingRegistrationRepo, transactor, registrationPolicy,
+		domainRules, jwtManager, config.AccessTTL, config.RefreshTTL)
+	refreshUC := usecase.NewRefresh(userRepo, tokenRepo, membershipRepo, jwtManager, config.AccessTTL, config.RefreshTTL)
 	validateUC := usecase.NewValidateToken(userRepo, jwtManager)
 	logoutUC := usecase.NewLogout(tokenRepo)
 	getMeUC := usecase.NewGetMe(userRepo)
+	createInvitationUC := usecase.NewCreateInvitation(membershipRepo, invitationRepo, notifier, domainRules,
+		config.InvitationTTL)
+	listInvitationsUC := usecase.NewListInvitations(membershipRepo, invitationRepo)
+	revokeInvitationUC := usecase.NewRevokeInvitation(membershipRepo, invitationRepo)
+	acceptInvitationUC := usecase.NewAcceptInvitation(userRepo, tokenRepo, membershipRepo, invitationRepo, domainRules,
+		transactor, jwtManager, config.AccessTTL, config.RefreshTTL)
+	switchBusinessUC := usecase.NewSwitchBusiness(userRepo, tokenRepo, membershipRepo, jwtManager, config.AccessTTL, config.RefreshTTL)
+	checkPermissionUC := usecase.NewCheckPermission(userRepo, membershipRepo, permissionRepo, policyConditionRepo,
+		policyEvaluator, decisionLog, jwtManager)
+	createCustomRoleUC := usecase.NewCreateCustomRole(membershipRepo, customRoleRepo, permissionRepo, transactor)
+	updateCustomRoleUC := usecase.NewUpdateCustomRole(membershipRepo, customRoleRepo, permissionRepo, transactor)
+	deleteCustomRoleUC := usecase.NewDeleteCustomRole(membershipRepo, customRoleRepo)
+	listCustomRolesUC := usecase.NewListCustomRoles(membershipRepo, customRoleRepo)
+	updateMembershipRoleUC := usecase.NewUpdateMembershipRole(membershipRepo, customRoleRepo)
+	setBusinessParentUC := usecase.NewSetBusinessParent(membershipRepo, businessUnitRepo, transactor)
+	listChildBusinessesUC := usecase.NewListChildBusinesses(membershipRepo, businessUnitRepo)
+	transferLimiter := infrastructure.NewRateLimiter(config.OwnershipTransferLimit, config.OwnershipTransferWindow)
+	startOwnershipTransferUC := usecase.NewStartOwnershipTransfer(userRepo, membershipRepo, ownershipTransferRepo, auditRepo,
+		transactor, notifier, config.OwnershipTransferTTL, verificationCodeRepo, transferLimiter)
+	acceptOwnershipTransferUC := usecase.NewAcceptOwnershipTransfer(userRepo, membershipRepo, ownershipTransferRepo, auditRepo,
+		transactor, notifier)
+	cancelOwnershipTransferUC := usecase.NewCancelOwnershipTransfer(userRepo, ownershipTransferRepo, auditRepo, transactor, notifier)
+	writeRelationsUC := usecase.NewWriteRelations(userRepo, relationTupleRepo, transactor, rebacSchema)
+	checkRelationUC := usecase.NewCheckRelation(relationTupleRepo, rebacEngine, decisionLog)
+	listObjectsUC := usecase.NewListObjects(relationTupleRepo, rebacEngine)
+	listSubjectsUC := usecase.NewListSubjects(relationTupleRepo, rebacEngine)
+	createPolicyConditionUC := usecase.NewCreatePolicyCondition(userRepo, membershipRepo, permissionRepo, customRoleRepo,
+		policyConditionRepo, policyEvaluator)
+	listPolicyConditionsUC := usecase.NewListPolicyConditions(userRepo, membershipRepo, policyConditionRepo)
+	deletePolicyConditionUC := usecase.NewDeletePolicyCondition(userRepo, membershipRepo, policyConditionRepo)
+	queryDecisionLogUC := usecase.NewQueryDecisionLog(userRepo, membershipRepo, decisionLogRepo)
+	registerPermissionsUC := usecase.NewRegisterPermissions(userRepo, permissionRepo, auditRepo, transactor)
+	registerOAuthClientUC := usecase.NewRegisterOAuthClient(userRepo, oauthClientRepo)
+	createServiceAccountUC := usecase.NewCreateServiceAccount(userRepo, serviceAccountRepo)
+	validateAuthorizationUC := usecase.NewValidateAuthorization(oauthClientRepo)
+	authorizeUC := usecase.NewAuthorize(userRepo, oauthClientRepo, authorizationCodeRepo, oauthConsentRepo, transactor,
+		config.OAuthCodeTTL)
+	oauthTokenUC := usecase.NewOAuthToken(userRepo, tokenRepo, oauthClientRepo, authorizationCodeRepo, serviceAccountRepo,
+		deviceAuthorizationRepo, jwtManager, idTokenSigner, issuer, config.AccessTTL, config.RefreshTTL)
+	userInfoUC := usecase.NewUserInfo(userRepo, jwtManager)
+	introspectTokenUC := usecase.NewIntrospectToken(userRepo, tokenRepo, oauthClientRepo, serviceAccountRepo, jwtManager)
+	revokeTokenUC := usecase.NewRevokeToken(tokenRepo, oauthClientRepo, serviceAccountRepo, jwtManager)
+	startDeviceUC := usecase.NewStartDeviceAuthorization(oauthClientRepo, deviceAuthorizationRepo, issuer+"/device",
+		config.OAuthDeviceCodeTTL)
+	approveDeviceUC := usecase.NewApproveDevice(userRepo, deviceAuthorizationRepo, oauthConsentRepo, transactor)
+	lookupDeviceUC := usecase.NewLookupDevice(deviceAuthorizationRepo)
+	listSessionsUC := usecase.NewListSessions(tokenRepo)
+	revokeSessionUC := usecase.NewRevokeSession(tokenRepo)
+	exchangeTokenUC := usecase.NewExchangeToken(userRepo, tokenExchangePolicyRepo, auditRepo, jwtManager,
+		config.TokenExchangeTTL)
+	setTokenExchangePolicyUC := usecase.NewSetTokenExchangePolicy(userRepo, tokenExchangePolicyRepo)
+	deleteTokenExchangePolicyUC := usecase.NewDeleteTokenExchangePolicy(userRepo, tokenExchangePolicyRepo)
+	qrStartLimiter := infrastructure.NewRateLimiter(config.QRLoginStartLimit, config.QRLoginWindow)
+	qrPollLimiter := infrastructure.NewRateLimiter(config.QRLoginPollLimit, config.QRLoginWindow)
+	startQRLoginUC := usecase.NewStartQRLogin(qrLoginRepo, config.QRLoginTTL, qrStartLimiter)
+	scanQRLoginUC := usecase.NewScanQRLogin(qrLoginRepo)
+	approveQRLoginUC := usecase.NewApproveQRLogin(qrLoginRepo)
+	pollQRLoginUC := usecase.NewPollQRLogin(userRepo, tokenRepo, qrLoginRepo, jwtManager, config.AccessTTL,
+		config.RefreshTTL, config.QRLoginMaxPolls, qrPollLimiter)
+	registerSharedDeviceUC := usecase.NewRegisterSharedDevice(membershipRepo, sharedDeviceRepo)
+	revokeSharedDeviceUC := usecase.NewRevokeSharedDevice(membershipRepo, sharedDeviceRepo)
+	setDevicePinUC := usecase.NewSetDevicePin(membershipRepo, sharedDeviceRepo)
+	listDeviceUsersUC := usecase.NewListDeviceUsers(membershipRepo, sharedDeviceRepo)
+	pinLoginUC := usecase.NewPinLogin(userRepo, membershipRepo, sharedDeviceRepo, jwtManager, config.PinLoginTTL)
+	listIdentityProvidersUC := usecase.NewListIdentityProviders(identityProviders)
+	startFederatedLoginUC := usecase.NewStartFederatedLogin(identityProviders, federatedLoginStateRepo,
+		config.FederatedLoginTTL)
+	completeFederatedLoginUC := usecase.NewCompleteFederatedLogin(identityProviders, federatedLoginStateRepo, userRepo,
+		linkedIdentityRepo, tokenRepo, transactor, registrationPolicy, domainRules, jwtManager, config.AccessTTL,
+		config.RefreshTTL)
+	var telegramVerifier *infrastructure.TelegramAuthVerifier
+	if config.TelegramBotToken != "" {
+		telegramVerifier = infrastructure.NewTelegramAuthVerifier(config.TelegramBotToken, config.TelegramAuthMaxAge)
+	}
+	telegramLoginUC := usecase.NewTelegramLogin(telegramVerifier, userRepo, linkedIdentityRepo, tokenRepo, transactor,
+		registrationPolicy, domainRules, jwtManager, config.AccessTTL, config.RefreshTTL)
+	listLoginMethodsUC := usecase.NewListLoginMethods(userRepo, linkedIdentityRepo)
+	startLinkIdentityUC := usecase.NewStartLinkIdentity(identityProviders, federatedLoginStateRepo,
+		config.FederatedLoginTTL)
+	completeLinkIdentityUC := usecase.NewCompleteLinkIdentity(identityProviders, federatedLoginStateRepo,
+		linkedIdentityRepo, accountMergeRequestRepo, auditRepo)
+	linkTelegramUC := usecase.NewLinkTelegram(telegramVerifier, linkedIdentityRepo, accountMergeRequestRepo, auditRepo)
+	unlinkIdentityUC := usecase.NewUnlinkIdentity(userRepo, linkedIdentityRepo, auditRepo, transactor)
+	listMergeRequestsUC := usecase.NewListMergeRequests(userRepo, accountMergeRequestRepo)
+	mergeAccountsUC := usecase.NewMergeAccounts(userRepo, linkedIdentityRepo, userMergeRepo, userEventRepo, auditRepo,
+		domainRules, transactor, relationTupleRepo)
+	resolveUserIDUC := usecase.NewResolveUserID(userRepo, userMergeRepo)
+	listUserEventsUC := usecase.NewListUserEvents(userEventRepo)
+	listPendingRegistrationsUC := usecase.NewListPendingRegistrations(userRepo, pendingRegistrationRepo)
+	resolveRegistrationUC := usecase.NewResolveRegistration(userRepo, pendingRegistrationRepo, auditRepo, transactor)
+	getDomainRulesUC := usecase.NewGetDomainRules(userRepo, domainRules)
+	setDomainRulesUC := usecase.NewSetDomainRules(userRepo, domainRules, auditRepo)
+	guestLimiter := infrastructure.NewRateLimiter(config.GuestCreateLimit, config.GuestCreateWindow)
+	createGuestUC := usecase.NewCreateGuest(userRepo, linkedIdentityRepo, tokenRepo, transactor, registrationPolicy,
+		guestLimiter, jwtManager, config.AccessTTL, config.RefreshTTL)
+	guestLoginUC := usecase.NewGuestLogin(userRepo, linkedIdentityRepo, tokenRepo, jwtManager, config.AccessTTL,
+		config.RefreshTTL)
+	upgradeGuestUC := usecase.NewUpgradeGuest(userRepo, linkedIdentityRepo, tokenRepo, userEventRepo, auditRepo,
+		registrationPolicy, domainRules, transactor, jwtManager, config.AccessTTL, config.RefreshTTL)
 
 	// services
 	identityHandler := handler.NewIdentityHandler(
@@ -64,7 +233,75 @@ func main() {
 		validateUC,
 		logoutUC,
 		getMeUC,
+		createInvitationUC,
+		listInvitationsUC,
+		revokeInvitationUC,
+		acceptInvitationUC,
+		switchBusinessUC,
+		checkPermissionUC,
+		createCustomRoleUC,
+		updateCustomRoleUC,
+		deleteCustomRoleUC,
+		listCustomRolesUC,
+		updateMembershipRoleUC,
+		setBusinessParentUC,
+		listChildBusinessesUC,
+		startOwnershipTransferUC,
+		acceptOwnershipTransferUC,
+		cancelOwnershipTransferUC,
+		writeRelationsUC,
+		checkRelationUC,
+		listObjectsUC,
+		listSubjectsUC,
+		createPolicyConditionUC,
+		listPolicyConditionsUC,
+		deletePolicyConditionUC,
+		queryDecisionLogUC,
+		registerPermissionsUC,
+		registerOAuthClientUC,
+		createServiceAccountUC,
+		introspectTokenUC,
+		revokeTokenUC,
+		approveDeviceUC,
+		listSessionsUC,
+		revokeSessionUC,
+		exchangeTokenUC,
+		setTokenExchangePolicyUC,
+		deleteTokenExchangePolicyUC,
+		startQRLoginUC,
+		scanQRLoginUC,
+		approveQRLoginUC,
+		pollQRLoginUC,
+		registerSharedDeviceUC,
+		revokeSharedDeviceUC,
+		setDevicePinUC,
+		listDeviceUsersUC,
+		pinLoginUC,
+		listIdentityProvidersUC,
+		startFederatedLoginUC,
+		completeFederatedLoginUC,
+		telegramLoginUC,
+		listLoginMethodsUC,
+		startLinkIdentityUC,
+		completeLinkIdentityUC,
+		linkTelegramUC,
+		unlinkIdentityUC,
+		listMergeRequestsUC,
+		mergeAccountsUC,
+		resolveUserIDUC,
+		listUserEventsUC,
+		createGuestUC,
+		guestLoginUC,
+		upgradeGuestUC,
+		listPendingRegistrationsUC,
+		resolveRegistrationUC,
+		getDomainRulesUC,
+		setDomainRulesUC,
+		lookupDeviceUC,
 	)
+	signInLimiter := infrastructure.NewRateLimiter(config.SignInPageLimit, config.SignInPageWindow)
+	oauthHandler := handler.NewOAuthHandler(validateAuthorizationUC, authorizeUC, oauthTokenUC, introspectTokenUC,
+		revokeTokenUC, startDeviceUC, approveDeviceUC, userInfoUC, idTokenSigner, issuer, lookupDeviceUC, signInLimiter)
 
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", config.Port))
 	if err != nil {
@@ -73,12 +310,25 @@ func main() {
 
 	grpcServer := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
+			interceptor.ClientInfo(trustedProxies, config.ClientLocationHeader),
 			interceptor.Auth(config.JWT_SECRET),
 		),
 	)
 	
 	identityv1.RegisterIdentityServer(grpcServer, identityHandler)
 
+	httpServer := &http.Server{
+		Addr:              fmt.Sprintf(":%d", config.HTTPPort),
+		Handler:           oauthHandler.Routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		logrus.Printf("Starting HTTP server on :%d", config.HTTPPort)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logrus.Fatalf("failed to serve http: %v", err)
+		}
+	}()
+
 	go func() {
 		logrus.Printf("Starting gRPC server on :%d", config.Port)
 		if err := grpcServer.Serve(lis); err != nil {
@@ -95,9 +345,15 @@ func main() {
 	ctxShutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if err := httpServer.Shutdown(ctxShutdown); err != nil {
+		logrus.Errorf("http server shutdown: %v", err)
+	}
 	grpcServer.GracefulStop()
 	logrus.Println("gRPC server stopped")
 
+	decisionLog.Close()
+	domainRules.Close()
+
 	select {
 	case <-ctxShutdown.Done():
 		logrus.Println("gRPC server stopped")
@@ -14,6 +14,65 @@ type Config struct {
 	POSTGRES_DSN string        `env:"POSTGRES_DSN"`
 	AccessTTL    time.Duration `env:"ACCESS_TTL"`
 	RefreshTTL   time.Duration `env:"REFRESH_TTL"`
+
+	InvitationTTL        time.Duration `env:"INVITATION_TTL" envDefault:"168h"`
+	OwnershipTransferTTL time.Duration `env:"OWNERSHIP_TRANSFER_TTL" envDefault:"72h"`
+	// StartOwnershipTransfer calls allowed per owner in each window, counting
+	// password and confirmation code attempts
+	OwnershipTransferLimit  int           `env:"OWNERSHIP_TRANSFER_LIMIT" envDefault:"5"`
+	OwnershipTransferWindow time.Duration `env:"OWNERSHIP_TRANSFER_WINDOW" envDefault:"15m"`
+	RebacSchemaPath         string        `env:"REBAC_SCHEMA_PATH" envDefault:""`
+
+	// share of allowed decisions written to the decision log; denials are always kept
+	DecisionLogSampleRate float64 `env:"DECISION_LOG_SAMPLE_RATE" envDefault:"1"`
+	DecisionLogBuffer     int     `env:"DECISION_LOG_BUFFER" envDefault:"4096"`
+
+	// OAuth 2.0 endpoints are served over HTTP next to the gRPC API
+	HTTPPort     int           `env:"HTTP_PORT" envDefault:"8080"`
+	OAuthCodeTTL time.Duration `env:"OAUTH_CODE_TTL" envDefault:"1m"`
+	// lifetime of device authorization codes shown on kiosks and TVs
+	OAuthDeviceCodeTTL time.Duration `env:"OAUTH_DEVICE_CODE_TTL" envDefault:"10m"`
+	// posts to the OAuth sign-in and device verification pages allowed per
+	// address, and sign-in attempts per email, in each window
+	SignInPageLimit  int           `env:"SIGN_IN_PAGE_LIMIT" envDefault:"10"`
+	SignInPageWindow time.Duration `env:"SIGN_IN_PAGE_WINDOW" envDefault:"15m"`
+	// how long a QR login code stays valid on the web sign-in page
+	QRLoginTTL time.Duration `env:"QR_LOGIN_TTL" envDefault:"2m"`
+	// lifetime of PIN login tokens on shared tablets; they have no refresh token
+	PinLoginTTL time.Duration `env:"PIN_LOGIN_TTL" envDefault:"15m"`
+	// JSON list of external identity providers for federated login; empty disables it
+	FederationProvidersPath string        `env:"FEDERATION_PROVIDERS_PATH" envDefault:""`
+	FederatedLoginTTL       time.Duration `env:"FEDERATED_LOGIN_TTL" envDefault:"10m"`
+	// Telegram Login Widget; empty bot token disables it
+	TelegramBotToken   string        `env:"TELEGRAM_BOT_TOKEN" envDefault:""`
+	TelegramAuthMaxAge time.Duration `env:"TELEGRAM_AUTH_MAX_AGE" envDefault:"10m"`
+	// JSON registration policy; empty lets clients and owners sign up freely
+	RegistrationPolicyPath string `env:"REGISTRATION_POLICY_PATH" envDefault:""`
+	// JSON email domain allow/deny lists, checked for changes every reload interval
+	DomainRulesPath           string        `env:"DOMAIN_RULES_PATH" envDefault:""`
+	DomainRulesReloadInterval time.Duration `env:"DOMAIN_RULES_RELOAD_INTERVAL" envDefault:"10s"`
+	// selects the per-environment overrides in the domain rules file
+	Environment string `env:"ENVIRONMENT" envDefault:""`
+	// CreateGuest calls allowed per client address in each window
+	GuestCreateLimit  int           `env:"GUEST_CREATE_LIMIT" envDefault:"10"`
+	GuestCreateWindow time.Duration `env:"GUEST_CREATE_WINDOW" envDefault:"1h"`
+	// comma separated CIDRs of proxies whose x-forwarded-for is believed, and
+	// the header they put the client's location in, e.g. cf-ipcity
+	TrustedProxies       string `env:"TRUSTED_PROXIES" envDefault:""`
+	ClientLocationHeader string `env:"CLIENT_LOCATION_HEADER" envDefault:""`
+	// concurrent QR login long polls; further polls are refused until one ends
+	QRLoginMaxPolls int `env:"QR_LOGIN_MAX_POLLS" envDefault:"1000"`
+	// StartQRLogin and PollQRLogin calls allowed per client address in each window
+	QRLoginStartLimit int           `env:"QR_LOGIN_START_LIMIT" envDefault:"10"`
+	QRLoginPollLimit  int           `env:"QR_LOGIN_POLL_LIMIT" envDefault:"60"`
+	QRLoginWindow     time.Duration `env:"QR_LOGIN_WINDOW" envDefault:"1m"`
+	// upper bound for tokens issued by token exchange; never past the subject token
+	TokenExchangeTTL time.Duration `env:"TOKEN_EXCHANGE_TTL" envDefault:"5m"`
+
+	// OpenID Connect: the public base URL of the HTTP endpoints and the RSA
+	// key ID tokens are signed with; without a key an ephemeral one is generated
+	OIDCIssuer         string `env:"OIDC_ISSUER" envDefault:"http://localhost:8080"`
+	OIDCSigningKeyPath string `env:"OIDC_SIGNING_KEY_PATH" envDefault:""`
 }
 
 func LoadConfig() (*Config, error) {
@@ -34,7 +93,14 @@ func LoadConfig() (*Config, error) {
 
 		envVal := os.Getenv(tag)
 		if envVal == "" {
-			return cfg, fmt.Errorf("environment variable %s is not set", tag)
+			def, hasDefault := fieldType.Tag.Lookup("envDefault")
+			if !hasDefault {
+				return cfg, fmt.Errorf("environment variable %s is not set", tag)
+			}
+			if def == "" {
+				continue
+			}
+			envVal = def
 		}
 
 		kind := field.Kind()
@@ -62,6 +128,13 @@ func LoadConfig() (*Config, error) {
 			}
 			field.SetInt(int64(intVal))
 
+		case reflect.Float64 == kind:
+			floatVal, err := strconv.ParseFloat(envVal, 64)
+			if err != nil {
+				return cfg, fmt.Errorf("environment variable %s is not a valid number", tag)
+			}
+			field.SetFloat(floatVal)
+
 		default:
 			return cfg, fmt.Errorf("unsupported type %s for field %s", field.Type(), fieldType.Name)
 		}
@@ -10,3 +10,106 @@ var (
 	ErrTokenMalformed       = errors.New("token malformed")
 	ErrUserNotActive        = errors.New("user not active")
 )
+
+var (
+	ErrPermissionDenied     = errors.New("permission denied")
+	ErrInvalidRole          = errors.New("invalid role")
+	ErrMembershipNotFound   = errors.New("membership not found")
+	ErrMembershipExists     = errors.New("user is already a member of this business")
+	ErrInvitationNotFound   = errors.New("invitation not found")
+	ErrInvitationNotPending = errors.New("invitation expired, revoked or already accepted")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrRoleNotFound         = errors.New("role not found")
+	ErrRoleExists           = errors.New("role with this name already exists")
+	ErrRoleInUse            = errors.New("role is assigned to members")
+	ErrUnknownPermission    = errors.New("unknown permission")
+	ErrHierarchyCycle       = errors.New("business cannot be placed under its own descendant")
+	ErrTransferNotFound     = errors.New("ownership transfer not found")
+	ErrTransferNotPending   = errors.New("ownership transfer expired or already resolved")
+	ErrTransferInProgress   = errors.New("business already has a pending ownership transfer")
+	ErrConfirmationRequired = errors.New("confirmation code sent, repeat the request with it")
+	ErrInvalidTuple         = errors.New("invalid relation tuple")
+	ErrSchemaViolation      = errors.New("relation tuple does not match the schema")
+	ErrInvalidConsistency   = errors.New("invalid consistency token")
+	ErrConsistencyAhead     = errors.New("consistency token is newer than the data available")
+	ErrInvalidCondition     = errors.New("invalid policy condition")
+	ErrConditionNotFound    = errors.New("policy condition not found")
+	ErrInvalidNamespace     = errors.New("invalid permission namespace")
+	ErrNamespaceOwned       = errors.New("permission namespace belongs to another service")
+	ErrPermissionDeprecated = errors.New("permission is deprecated")
+)
+
+var (
+	ErrOAuthClientNotFound      = errors.New("oauth client not found")
+	ErrInvalidRedirectURI       = errors.New("redirect uri is not registered for this client")
+	ErrAuthorizationCodeInvalid = errors.New("authorization code is invalid, expired or already used")
+	ErrServiceAccountNotFound   = errors.New("service account not found")
+	ErrDeviceCodeNotFound       = errors.New("device code not found, expired or already used")
+	ErrSessionNotFound          = errors.New("session not found")
+	ErrExchangeNotAllowed       = errors.New("token exchange not allowed by policy")
+	ErrExchangePolicyNotFound   = errors.New("token exchange policy not found")
+	ErrTooManyRequests          = errors.New("too many requests, try again later")
+	ErrQRLoginBusy              = errors.New("too many qr login polls in progress, retry later")
+	ErrQRLoginPolling           = errors.New("qr login session is already being polled")
+	ErrQRLoginNotFound          = errors.New("qr login session not found, expired or already used")
+	ErrSharedDeviceNotFound     = errors.New("shared device not found")
+	ErrInvalidDeviceName        = errors.New("device name must be 1 to 100 characters")
+	ErrInvalidPin               = errors.New("pin must be 4 to 8 digits")
+	ErrPinLocked                = errors.New("too many failed pin attempts")
+	ErrPinDisabled              = errors.New("pin disabled after repeated lockouts")
+	ErrIdentityProviderNotFound = errors.New("identity provider not found")
+	ErrFederatedStateInvalid    = errors.New("federated login state is invalid or expired")
+	ErrExternalLoginFailed      = errors.New("external sign-in failed")
+	ErrLinkedIdentityNotFound   = errors.New("linked identity not found")
+	ErrProviderAlreadyLinked    = errors.New("an identity from this provider is already linked")
+	ErrLastLoginMethod          = errors.New("cannot remove the last login method")
+	ErrInvalidMerge             = errors.New("accounts cannot be merged")
+	ErrPhoneExists              = errors.New("phone already registered")
+	ErrNotGuest                 = errors.New("user is not a guest")
+	ErrRegistrationClosed       = errors.New("registration is by invitation only")
+	ErrRoleNotSelfAssignable    = errors.New("role cannot be self-assigned")
+	ErrRoleRequiresInvitation   = errors.New("role is only granted through an invitation")
+	ErrEmailDomainNotAllowed    = errors.New("email domain is not allowed")
+	ErrRegistrationNotFound     = errors.New("pending registration not found")
+	ErrInvalidDomainRule        = errors.New("invalid domain rule")
+)
+
+// OAuthError is an error response defined by RFC 6749, returned to the client
+// as the error and error_description parameters.
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+func NewOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+const (
+	OAuthInvalidRequest          = "invalid_request"
+	OAuthInvalidClient           = "invalid_client"
+	OAuthInvalidGrant            = "invalid_grant"
+	OAuthUnauthorizedClient      = "unauthorized_client"
+	OAuthUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthUnsupportedResponseType = "unsupported_response_type"
+	OAuthInvalidScope            = "invalid_scope"
+	OAuthAccessDenied            = "access_denied"
+	OAuthServerError             = "server_error"
+	OAuthUnsupportedTokenType    = "unsupported_token_type"
+
+	// device authorization grant, RFC 8628 section 3.5
+	OAuthAuthorizationPending = "authorization_pending"
+	OAuthSlowDown             = "slow_down"
+	OAuthExpiredToken         = "expired_token"
+
+	// bearer token errors of RFC 6750, used by the userinfo endpoint
+	OAuthInvalidToken      = "invalid_token"
+	OAuthInsufficientScope = "insufficient_scope"
+)
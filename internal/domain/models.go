@@ -1,6 +1,24 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	UserRoleClient = "client"
+	UserRoleOwner  = "owner"
+	UserRoleAdmin  = "admin"
+	UserRoleMaster = "master"
+	// UserRoleService marks accounts used by downstream services rather than people.
+	// It cannot be self-registered.
+	UserRoleService = "service"
+)
+
+// UserRoles are the global roles people can hold.
+var UserRoles = []string{UserRoleClient, UserRoleOwner, UserRoleAdmin, UserRoleMaster}
 
 type User struct {
 	ID            string
@@ -13,10 +31,22 @@ type User struct {
 	IsActive      bool
 	EmailVerified bool
 	PhoneVerified bool
+	IsGuest       bool
+	ContactPhone  string // unverified phone a guest left, kept out of Phone
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 }
 
+// NoPassword is stored as the password hash of users who cannot sign in
+// with a password, such as service accounts and users created through an
+// external identity provider. bcrypt never matches it.
+const NoPassword = "!"
+
+// HasPassword reports whether the user can sign in with a password.
+func (u *User) HasPassword() bool {
+	return u.Password != "" && u.Password != NoPassword
+}
+
 type AuthToken struct {
 	AccessToken  string
 	RefreshToken string
@@ -38,15 +68,1019 @@ type RefreshTokenClaims struct {
 }
 
 type RefreshToken struct {
-	ID        string
+	ID         string
+	UserID     string
+	BusinessID string // business context selected via SwitchBusiness, empty for global tokens
+	ClientID   string // OAuth client the token was issued to, empty for first-party logins
+	Scope      string // granted OAuth scope, space separated
+	TokenHash  string
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+}
+
+type TokenClaims struct {
+	UserID         string
+	Email          string
+	Role           string
+	BusinessID     string
+	BusinessRole   string
+	BusinessRoleID string // set when BusinessRole is a custom role
+	ClientID       string // set on tokens issued through OAuth
+	Scope          string
+	ServiceAccount bool           // issued to a service account through client credentials
+	OAuth          bool           // issued to an OAuth client acting for the user
+	Audience       string         // set on tokens restricted to one downstream service
+	Act            map[string]any // RFC 8693 actor claim of delegated tokens
+	DeviceID       string         // set on PIN login tokens from a shared device
+	Guest          bool           // issued to a guest; limited to a few RPCs
+	IssuedAt       time.Time
+	ExpiresAt      time.Time
+}
+
+const (
+	MembershipRoleOwner  = "owner"
+	MembershipRoleAdmin  = "admin"
+	MembershipRoleMaster = "master"
+	MembershipRoleViewer = "viewer"
+)
+
+type Membership struct {
+	ID             string
+	UserID         string
+	BusinessID     string
+	Role           string // built-in role, empty when CustomRoleID is set
+	CustomRoleID   string
+	CustomRoleName string
+	InheritedFrom  string // ancestor business the membership was granted at, empty for direct memberships
+	IsActive       bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// RoleName is the built-in role or the name of the custom role.
+func (m *Membership) RoleName() string {
+	if m.CustomRoleID != "" {
+		return m.CustomRoleName
+	}
+	return m.Role
+}
+
+func IsBuiltinMembershipRole(role string) bool {
+	switch role {
+	case MembershipRoleOwner, MembershipRoleAdmin, MembershipRoleMaster, MembershipRoleViewer:
+		return true
+	}
+	return false
+}
+
+// CustomRole is a named permission set defined by a business owner.
+type CustomRole struct {
+	ID          string
+	BusinessID  string
+	Name        string
+	Description string
+	Permissions []Permission
+	CreatedBy   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+type Invitation struct {
+	ID         string
+	BusinessID string
+	Email      string
+	Phone      string
+	Role       string
+	TokenHash  string
+	InvitedBy  string
+	AcceptedBy string
+	ExpiresAt  time.Time
+	AcceptedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// Status derives the invitation state from its timestamps.
+func (i *Invitation) Status(now time.Time) string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationStatusAccepted
+	case i.RevokedAt != nil:
+		return InvitationStatusRevoked
+	case !now.Before(i.ExpiresAt):
+		return InvitationStatusExpired
+	default:
+		return InvitationStatusPending
+	}
+}
+
+type CreateInvitationRequest struct {
+	InvitedBy  string
+	BusinessID string
+	Email      string
+	Phone      string
+	Role       string
+}
+
+type AcceptInvitationRequest struct {
+	Token     string
+	Email     string // required when the invitation was sent to a phone and no account exists yet
+	Password  string // plaintext
+	FirstName string
+	LastName  string
+}
+
+type Notification struct {
+	Template string
+	Email    string
+	Phone    string
+	Data     map[string]string
+}
+
+const (
+	RoleScopeGlobal     = "global"
+	RoleScopeMembership = "membership"
+)
+
+type Permission struct {
+	Resource string
+	Action   string
+}
+
+func (p Permission) String() string {
+	return p.Resource + ":" + p.Action
+}
+
+type PermissionCheckRequest struct {
+	SubjectToken string // either the subject's access token...
+	UserID       string // ...or its user id
+	BusinessID   string // may be empty when SubjectToken is business-scoped
+	Permissions  []Permission
+
+	// context for policy conditions, supplied by the calling service
+	ClientIP           string
+	ResourceAttributes map[string]string
+
+	// the authenticated caller. Only service accounts may name a UserID
+	// other than their own, and a business-scoped caller token confines
+	// the check to its business.
+	CallerID         string
+	CallerService    bool
+	CallerBusinessID string
+}
+
+type PermissionDecision struct {
+	Permission Permission
+	Allowed    bool
+	Reason     string
+	MatchedBy  string // rule that decided, e.g. "membership_role:admin" or "condition:<id>"
+}
+
+type UpdateMembershipRoleRequest struct {
+	CallerID     string
+	BusinessID   string
+	UserID       string
+	Role         string // built-in role...
+	CustomRoleID string // ...or a custom role of the same business
+}
+
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusAccepted  = "accepted"
+	TransferStatusCancelled = "cancelled"
+	TransferStatusExpired   = "expired"
+)
+
+type OwnershipTransfer struct {
+	ID         string
+	BusinessID string
+	FromUserID string
+	ToUserID   string
+	Status     string
+	ExpiresAt  time.Time
+	ResolvedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// EffectiveStatus reports pending transfers past their expiry as expired.
+func (t *OwnershipTransfer) EffectiveStatus(now time.Time) string {
+	if t.Status == TransferStatusPending && !now.Before(t.ExpiresAt) {
+		return TransferStatusExpired
+	}
+	return t.Status
+}
+
+type StartOwnershipTransferRequest struct {
+	CallerID string
+	// the current owner re-authenticates with their password or with the
+	// code sent to them when both are empty
+	Password         string
+	ConfirmationCode string
+	BusinessID       string
+	RecipientEmail   string
+}
+
+// VerificationCode is a one-time code sent to a user's email or phone to
+// confirm a sensitive action, whatever way they sign in.
+type VerificationCode struct {
 	UserID    string
-	TokenHash string
+	Purpose   string // the action it confirms, e.g. ownership_transfer:<business id>
+	CodeHash  string
+	Attempts  int
 	ExpiresAt time.Time
+}
+
+type AuditEntry struct {
+	ActorID      string
+	Action       string
+	BusinessID   string
+	TargetUserID string
+	Details      map[string]string
+}
+
+// ObjectRef addresses an object in the relation tuple store, "type:id".
+type ObjectRef struct {
+	Type string
+	ID   string
+}
+
+func (o ObjectRef) String() string {
+	return o.Type + ":" + o.ID
+}
+
+// SubjectRef is a single subject ("user:42") or, with Relation set, the set
+// of subjects holding that relation on the object ("business:7#admin").
+type SubjectRef struct {
+	Type     string
+	ID       string
+	Relation string
+}
+
+func (s SubjectRef) Object() ObjectRef {
+	return ObjectRef{Type: s.Type, ID: s.ID}
+}
+
+func (s SubjectRef) String() string {
+	if s.Relation == "" {
+		return s.Type + ":" + s.ID
+	}
+	return s.Type + ":" + s.ID + "#" + s.Relation
+}
+
+// RelationTuple states object#relation@subject.
+type RelationTuple struct {
+	Object   ObjectRef
+	Relation string
+	Subject  SubjectRef
+}
+
+func (t RelationTuple) String() string {
+	return t.Object.String() + "#" + t.Relation + "@" + t.Subject.String()
+}
+
+func ParseObjectRef(s string) (ObjectRef, error) {
+	typ, id, ok := strings.Cut(s, ":")
+	if !ok || typ == "" || id == "" || strings.ContainsAny(id, "#@") {
+		return ObjectRef{}, fmt.Errorf("%w: object %q", ErrInvalidTuple, s)
+	}
+	return ObjectRef{Type: typ, ID: id}, nil
+}
+
+func ParseSubjectRef(s string) (SubjectRef, error) {
+	objectPart, relation, _ := strings.Cut(s, "#")
+	obj, err := ParseObjectRef(objectPart)
+	if err != nil {
+		return SubjectRef{}, err
+	}
+	if strings.Contains(s, "#") && relation == "" {
+		return SubjectRef{}, fmt.Errorf("%w: subject %q", ErrInvalidTuple, s)
+	}
+	return SubjectRef{Type: obj.Type, ID: obj.ID, Relation: relation}, nil
+}
+
+const (
+	ConditionModeEnforce = "enforce"
+	ConditionModeShadow  = "shadow"
+)
+
+// PolicyCondition restricts grants with a CEL expression. It targets one
+// permission, one role, or a permission only when granted by that role; an
+// empty Resource or Role matches any. Conditions without a BusinessID apply
+// platform-wide, business ones also to the business's child locations.
+// Shadow conditions are evaluated and logged but never deny.
+type PolicyCondition struct {
+	ID           string
+	BusinessID   string
+	Resource     string
+	Action       string
+	RoleScope    string
+	Role         string
+	CustomRoleID string
+	Expression   string
+	Mode         string
+	Description  string
+	CreatedBy    string
+	CreatedAt    time.Time
+}
+
+func (c *PolicyCondition) AppliesTo(p Permission) bool {
+	return (c.Resource == "" || c.Resource == p.Resource) && (c.Action == "" || c.Action == p.Action)
+}
+
+// DecisionRecord is one permission decision kept in the decision log.
+type DecisionRecord struct {
+	ID                 int64
+	UserID             string
+	BusinessID         string
+	Permission         Permission
+	Allowed            bool
+	Reason             string
+	MatchedBy          string
+	ClientIP           string
+	ResourceAttributes map[string]string
+	Latency            time.Duration
+	CreatedAt          time.Time
+}
+
+type DecisionLogFilter struct {
+	UserID     string
+	BusinessID string
+	From, To   time.Time // zero means unbounded
+	Limit      int
+}
+
+// CorePermissionNamespace holds the permissions seeded by identity itself.
+// No service can register into it.
+const CorePermissionNamespace = "core"
+
+// PermissionNamespace is the set of permissions one service registers. The
+// first service account to register a namespace owns it.
+type PermissionNamespace struct {
+	Name      string
+	OwnerID   string
+	Version   int
+	UpdatedAt time.Time
+}
+
+type PermissionDefinition struct {
+	Permission   Permission
+	Description  string
+	DefaultRoles []string // built-in membership roles granted the permission
+	Deprecated   bool
+}
+
+type RegisterPermissionsRequest struct {
+	CallerID    string
+	Namespace   string
+	Version     int
+	Permissions []PermissionDefinition
+}
+
+type RegisterPermissionsResult struct {
+	Version    int  // namespace version after the call
+	Applied    bool // false when a newer version was already registered
+	Deprecated []Permission
+}
+
+// OAuthClient is a registered OAuth 2.0 client. Public clients (mobile and
+// browser apps) have no secret and must use PKCE.
+type OAuthClient struct {
+	ID           string // client_id
+	SecretHash   string
+	Name         string
+	RedirectURIs []string
+	Scopes       []string // scopes the client may request
+	CreatedBy    string
+	CreatedAt    time.Time
+}
+
+func (c *OAuthClient) IsConfidential() bool {
+	return c.SecretHash != ""
+}
+
+// HasRedirectURI matches uri exactly against the registered URIs, as
+// required for clients that register their redirect URIs.
+func (c *OAuthClient) HasRedirectURI(uri string) bool {
+	for _, u := range c.RedirectURIs {
+		if u == uri {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *OAuthClient) AllowsScopes(scopes []string) bool {
+	for _, s := range scopes {
+		if !slices.Contains(c.Scopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// AuthorizationRequest holds the parameters of an authorization endpoint call.
+type AuthorizationRequest struct {
+	ClientID            string
+	RedirectURI         string
+	ResponseType        string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string // OpenID Connect, echoed in the ID token
+}
+
+type AuthorizationCode struct {
+	CodeHash      string
+	ClientID      string
+	UserID        string
+	RedirectURI   string
+	Scope         string
+	CodeChallenge string
+	Nonce         string
+	AuthTime      time.Time
+	ExpiresAt     time.Time
+}
+
+type OAuthConsent struct {
+	UserID    string
+	ClientID  string
+	Scopes    []string
+	GrantedAt time.Time
+}
+
+// TokenRequest holds the parameters of a token endpoint call.
+type TokenRequest struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+	DeviceCode   string
+}
+
+type OAuthTokenResponse struct {
+	AccessToken  string
+	TokenType    string
+	ExpiresIn    int64
+	RefreshToken string
+	Scope        string
+	IDToken      string // set when the openid scope was granted
+}
+
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+const (
+	DeviceStatusPending  = "pending"
+	DeviceStatusApproved = "approved"
+	DeviceStatusDenied   = "denied"
+	DeviceStatusConsumed = "consumed"
+)
+
+// DeviceAuthorization is a pending device authorization grant (RFC 8628).
+// The device polls with the secret device code; the user approves it by
+// entering the short UserCode on another device.
+type DeviceAuthorization struct {
+	DeviceCodeHash string
+	UserCode       string // normalized, without the dash shown to users
+	ClientID       string
+	ClientName     string
+	Scope          string
+	UserID         string // set once approved or denied
+	Status         string
+	Interval       int // minimum seconds between polls
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+}
+
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string
+	UserCode                string
+	VerificationURI         string
+	VerificationURIComplete string
+	ExpiresIn               int64
+	Interval                int
+}
+
+// DeviceApproval resolves a device authorization. UserID is set when the
+// user is already signed in (the mobile app); otherwise Email and Password
+// are checked.
+type DeviceApproval struct {
+	UserID   string
+	Email    string
+	Password string
+	UserCode string
+	// ClientID is the client the user was shown by LookupDevice. Approving
+	// a code without having looked it up fails.
+	ClientID string
+	Approve  bool
+}
+
+// Session is an active refresh token of a user, one per signed-in app or
+// device.
+type Session struct {
+	ID         string
+	ClientID   string // empty for first-party sign-ins
+	ClientName string
+	BusinessID string
+	Scope      string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+}
+
+const (
+	QRLoginStatusPending  = "pending"
+	QRLoginStatusScanned  = "scanned"
+	QRLoginStatusApproved = "approved"
+	QRLoginStatusDenied   = "denied"
+	QRLoginStatusConsumed = "consumed"
+)
+
+// ClientInfo describes where a request came from, as far as the server can
+// tell. It is shown to users before they approve a sign-in elsewhere.
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+	Location  string // as reported by a trusted proxy, empty when unknown
+}
+
+// QRLoginSession is a cross-device sign-in. A web client shows ID as a QR
+// code and waits with the secret poll token; a phone that is already signed
+// in scans the code and approves it.
+type QRLoginSession struct {
+	ID             string
+	PollSecretHash string
+	Status         string
+	UserID         string // set once scanned
+	Client         ClientInfo
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+}
+
+// QRLoginStart is returned to the web client. PollSecret is not stored and
+// is required to receive the tokens.
+type QRLoginStart struct {
+	SessionID  string
+	PollSecret string
+	ExpiresAt  time.Time
+}
+
+// QRLoginPoll is the state of a session seen by the web client. User and
+// Token are set only once, when an approved session is consumed.
+type QRLoginPoll struct {
+	Status string
+	User   *User
+	Token  *AuthToken
+}
+
+// SharedDevice is a tablet registered to one business location and shared
+// by its staff. Members sign in on it with a per-device PIN; the device
+// proves itself with a secret issued at registration.
+type SharedDevice struct {
+	ID         string
+	BusinessID string
+	Name       string
+	SecretHash string
+	CreatedBy  string
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// DevicePin is a member's PIN on a shared device. After too many failures it
+// is locked until LockedUntil; each lockout is longer than the last, and
+// after too many the PIN stays disabled until it is set again.
+type DevicePin struct {
+	DeviceID       string
+	UserID         string
+	PinHash        string // bcrypt hash
+	FailedAttempts int
+	Lockouts       int // lockouts since the PIN was set
+	LockedUntil    *time.Time
+}
+
+// DeviceUser is a member who can sign in on a shared device, as shown on the
+// device's sign-in screen.
+type DeviceUser struct {
+	UserID    string
+	FirstName string
+	LastName  string
+}
+
+type PinLoginRequest struct {
+	DeviceID     string
+	DeviceSecret string
+	UserID       string
+	Pin          string
+}
+
+// ExternalIdentity is a user as asserted by an external identity provider.
+// Subject is stable and unique within the provider; Email may be empty.
+type ExternalIdentity struct {
+	ProviderID    string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+}
+
+// LinkedIdentity connects an external identity to a user.
+type LinkedIdentity struct {
+	ProviderID  string
+	Subject     string
+	UserID      string
+	Email       string
+	CreatedAt   time.Time
+	LastLoginAt time.Time
+}
+
+// FederatedLoginState is kept between sending the user to a provider and
+// the provider's redirect back. StateHash is the hash of the state parameter.
+type FederatedLoginState struct {
+	StateHash    string
+	ProviderID   string
+	UserID       string // set when a signed-in user links the provider
+	RedirectURI  string
+	CodeVerifier string
+	Nonce        string
+	ExpiresAt    time.Time
+}
+
+type FederatedLoginStart struct {
+	AuthorizationURL string
+	State            string
+	ExpiresAt        time.Time
+}
+
+// FederatedLoginResult is a completed federated sign-in. Created is set when
+// the user was created just in time.
+type FederatedLoginResult struct {
+	User    *User
+	Token   *AuthToken
+	Created bool
+}
+
+const (
+	LoginMethodPassword = "password"
+	LoginMethodProvider = "provider"
+)
+
+// LoginMethod is one way a user can sign in: their password or an external
+// identity. ProviderID and the times are set for external identities.
+type LoginMethod struct {
+	Type        string
+	ProviderID  string
+	Email       string
+	CreatedAt   time.Time
+	LastLoginAt time.Time
+}
+
+const (
+	MergeRequestStatusPending   = "pending"
+	MergeRequestStatusCompleted = "completed"
+)
+
+// AccountMergeRequest records that the user TargetUserID tried to link an
+// external identity that already signs in to SourceUserID. Proving control of
+// that identity shows the same person holds both accounts; an admin merges
+// them.
+type AccountMergeRequest struct {
+	ID           string
+	SourceUserID string
+	TargetUserID string
+	ProviderID   string
+	Subject      string
+	Status       string
+	CreatedAt    time.Time
+}
+
+// LinkIdentityResult is the outcome of linking an external identity: either
+// the new link or, when the identity belongs to another user, a merge
+// request.
+type LinkIdentityResult struct {
+	Identity     *LinkedIdentity
+	MergeRequest *AccountMergeRequest
+}
+
+// UserMerge is the tombstone left by merging SourceUserID into
+// TargetUserID. The source user stays as an inactive row so its ID keeps
+// resolving to the surviving user.
+type UserMerge struct {
+	SourceUserID string
+	TargetUserID string
+	MergedBy     string
+	// MovePhone and MoveEmail hand the source's phone, and its email with the
+	// password, to a target that has none of its own.
+	MovePhone bool
+	MoveEmail bool
 	CreatedAt time.Time
 }
 
-type TokenClaims struct {
-	UserID string
-	Email  string
-	Role   string
+// UserEventMerged is published when a user is merged into another. Data
+// holds source_user_id and target_user_id.
+const UserEventMerged = "user.merged"
+
+// UserEvent is a change to a user that other services may need to follow.
+// IDs increase, so a consumer reads the feed from the last ID it saw.
+type UserEvent struct {
+	ID        int64
+	Type      string
+	UserID    string
+	Data      map[string]string
+	CreatedAt time.Time
+}
+
+// UserEventUpgraded is published when a guest becomes a full account. The
+// user keeps its ID.
+const UserEventUpgraded = "user.upgraded"
+
+// GuestDeviceProviderID is the linked identity a guest signs in with. Its
+// subject is the hash of a secret held by the guest's device.
+const GuestDeviceProviderID = "guest_device"
+
+// GuestRequest starts a guest account. The phone is optional contact data
+// and is not verified.
+type GuestRequest struct {
+	Phone     string // stored as the unverified ContactPhone
+	FirstName string
+	IPAddress string // callers are rate limited by address
+}
+
+// GuestSession is a signed-in guest. DeviceSecret is returned once; the
+// device keeps it to sign in again with GuestLogin.
+type GuestSession struct {
+	User         *User
+	Token        *AuthToken
+	DeviceSecret string
+}
+
+type UpgradeGuestRequest struct {
+	Email     string
+	Phone     string // takes the guest's contact phone when empty
+	Password  string // plaintext
+	FirstName string
+	LastName  string
+}
+
+// How a role may be taken at Register.
+const (
+	RegistrationOpen     = "open"     // the account is active at once
+	RegistrationApproval = "approval" // the account waits inactive for an admin
+	RegistrationInvite   = "invite"   // only through a business invitation
+)
+
+// RegistrationRule governs self-registration with one role. With
+// EmailDomains set, the email must be in one of them.
+type RegistrationRule struct {
+	Mode         string   `json:"mode"`
+	EmailDomains []string `json:"email_domains,omitempty"`
+}
+
+// RegistrationPolicy decides who may call Register and with which role.
+// Roles missing from Roles cannot be self-registered; admin never can.
+type RegistrationPolicy struct {
+	InviteOnly  bool                        `json:"invite_only"`
+	DefaultRole string                      `json:"default_role"`
+	Roles       map[string]RegistrationRule `json:"roles"`
+}
+
+// DefaultRegistrationPolicy lets clients and business owners sign up.
+func DefaultRegistrationPolicy() *RegistrationPolicy {
+	return &RegistrationPolicy{
+		DefaultRole: UserRoleClient,
+		Roles: map[string]RegistrationRule{
+			UserRoleClient: {Mode: RegistrationOpen},
+			UserRoleOwner:  {Mode: RegistrationOpen},
+		},
+	}
+}
+
+// Check returns the role to register with, an empty one meaning the
+// default, and the mode it is granted under, or the rule that refuses it.
+func (p *RegistrationPolicy) Check(role, email string) (string, string, error) {
+	if role == "" {
+		role = p.DefaultRole
+	}
+	if !slices.Contains(UserRoles, role) {
+		return "", "", ErrInvalidRole
+	}
+	if p.InviteOnly {
+		return "", "", ErrRegistrationClosed
+	}
+	rule, ok := p.Roles[role]
+	if !ok || role == UserRoleAdmin {
+		return "", "", fmt.Errorf("%w: %s", ErrRoleNotSelfAssignable, role)
+	}
+	if rule.Mode == RegistrationInvite {
+		return "", "", fmt.Errorf("%w: %s", ErrRoleRequiresInvitation, role)
+	}
+	if len(rule.EmailDomains) > 0 {
+		_, domain, _ := strings.Cut(email, "@")
+		if !slices.Contains(rule.EmailDomains, strings.ToLower(domain)) {
+			return "", "", fmt.Errorf("%w for role %s", ErrEmailDomainNotAllowed, role)
+		}
+	}
+	return role, rule.Mode, nil
+}
+
+// CheckOpen is Check for sign-ups no admin can hold for approval: guests
+// and first sign-ins through an external identity. role must be open.
+func (p *RegistrationPolicy) CheckOpen(role, email string) (string, error) {
+	role, mode, err := p.Check(role, email)
+	if err != nil {
+		return "", err
+	}
+	if mode != RegistrationOpen {
+		return "", fmt.Errorf("%w: %s accounts need approval", ErrRegistrationClosed, role)
+	}
+	return role, nil
+}
+
+// DomainRules decide which email domains may sign up or be invited. A
+// pattern is a domain, or *.domain for any of its subdomains. Deny wins;
+// when Allow is not empty, a domain must also match it.
+type DomainRules struct {
+	Environment string
+	Allow       []string
+	Deny        []string
+}
+
+// Check fails with ErrEmailDomainNotAllowed when the rules refuse email.
+func (r DomainRules) Check(email string) error {
+	_, d, _ := strings.Cut(strings.ToLower(strings.TrimSpace(email)), "@")
+	for _, p := range r.Deny {
+		if matchDomain(p, d) {
+			return fmt.Errorf("%w: %s is blocked", ErrEmailDomainNotAllowed, d)
+		}
+	}
+	if len(r.Allow) == 0 {
+		return nil
+	}
+	for _, p := range r.Allow {
+		if matchDomain(p, d) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is not on the allow list", ErrEmailDomainNotAllowed, d)
+}
+
+func matchDomain(pattern, domain string) bool {
+	if rest, ok := strings.CutPrefix(pattern, "*"); ok {
+		return strings.HasSuffix(domain, rest)
+	}
+	return domain == pattern
+}
+
+// NormalizeDomainPattern lowercases a pattern and reports whether it is a
+// domain with at least two labels, optionally prefixed by "*.".
+func NormalizeDomainPattern(pattern string) (string, bool) {
+	p := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(pattern), "@"))
+	d := strings.TrimPrefix(p, "*.")
+	if !strings.Contains(d, ".") || strings.HasPrefix(d, ".") || strings.HasSuffix(d, ".") ||
+		strings.ContainsAny(d, "*@ \t/") {
+		return "", false
+	}
+	return p, true
+}
+
+// PendingRegistration is a self-registered user waiting for admin approval.
+type PendingRegistration struct {
+	User      *User
+	CreatedAt time.Time
+}
+
+// OpenID Connect scopes. Each of profile, email and phone releases the
+// matching standard claims in the ID token and from userinfo.
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+	ScopePhone   = "phone"
+)
+
+// Values of the token_type_hint parameter of introspection and revocation.
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
+// ClientTokenRequest is an introspection (RFC 7662) or revocation (RFC 7009)
+// call made by a registered client or service account about Token.
+type ClientTokenRequest struct {
+	ClientID      string
+	ClientSecret  string
+	Token         string
+	TokenTypeHint string
+}
+
+// TokenIntrospection describes a token; all other fields are empty when
+// Active is false.
+type TokenIntrospection struct {
+	Active     bool
+	TokenType  string // "Bearer" for access tokens, "refresh_token" otherwise
+	Scope      string
+	ClientID   string
+	Subject    string
+	BusinessID string
+	Audience   string
+	ActorID    string // current actor of a delegated token
+	IssuedAt   time.Time
+	ExpiresAt  time.Time
+}
+
+// TokenTypeAccessToken identifies tokens in token exchange (RFC 8693).
+const TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+
+// TokenExchangeRequest swaps SubjectToken for a short-lived token limited to
+// one audience. With ActorToken the new token is issued on behalf of the
+// subject and records the actor in its act claim.
+type TokenExchangeRequest struct {
+	CallerClientID string // service account making the exchange
+	SubjectToken   string
+	ActorToken     string
+	Audience       string
+	Scope          string
+}
+
+type TokenExchangeResult struct {
+	AccessToken     string
+	IssuedTokenType string
+	Scope           string
+	ExpiresAt       time.Time
+}
+
+// TokenExchangePolicy allows a service account to exchange tokens for
+// Audience. Requested scopes must be within Scopes; delegation is allowed
+// only for actors whose global role is in ActorRoles.
+type TokenExchangePolicy struct {
+	ClientID   string
+	Audience   string
+	Scopes     []string
+	ActorRoles []string
+	CreatedBy  string
+	CreatedAt  time.Time
+}
+
+// ServiceAccount is a principal for service-to-service calls. It signs in
+// with the client credentials grant and acts through a user with role
+// "service", whose id is UserID.
+type ServiceAccount struct {
+	ClientID   string
+	UserID     string
+	Name       string
+	SecretHash string
+	Scopes     []string // scopes the account may request, see ServiceScopes
+	IsActive   bool
+	CreatedBy  string
+	CreatedAt  time.Time
+}
+
+func (a *ServiceAccount) AllowsScopes(scopes []string) bool {
+	for _, s := range scopes {
+		if !slices.Contains(a.Scopes, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// Scopes of service account tokens. Each one opens a group of RPCs; service
+// tokens cannot call anything else.
+const (
+	ScopeTokensValidate      = "identity.tokens.validate"
+	ScopePermissionsCheck    = "identity.permissions.check"
+	ScopePermissionsRegister = "identity.permissions.register"
+	ScopeRelationsRead       = "identity.relations.read"
+	ScopeRelationsWrite      = "identity.relations.write"
+	ScopeTokensExchange      = "identity.tokens.exchange"
+	ScopeUsersRead           = "identity.users.read"
+)
+
+var ServiceScopes = []string{ScopeTokensValidate, ScopePermissionsCheck, ScopePermissionsRegister, ScopeRelationsRead,
+	ScopeRelationsWrite, ScopeTokensExchange, ScopeUsersRead}
+
+// ParseScope splits a space separated scope string, dropping duplicates.
+func ParseScope(scope string) []string {
+	var out []string
+	for _, s := range strings.Fields(scope) {
+		if !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
 }
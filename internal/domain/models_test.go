@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegistrationPolicyCheck(t *testing.T) {
+	p := &RegistrationPolicy{
+		DefaultRole: UserRoleClient,
+		Roles: map[string]RegistrationRule{
+			UserRoleClient: {Mode: RegistrationOpen},
+			UserRoleOwner:  {Mode: RegistrationApproval},
+			UserRoleMaster: {Mode: RegistrationInvite},
+		},
+	}
+	tests := []struct {
+		role, email string
+		want, mode  string
+		err         error
+	}{
+		{"", "", UserRoleClient, RegistrationOpen, nil},
+		{UserRoleClient, "ann@example.com", UserRoleClient, RegistrationOpen, nil},
+		{UserRoleOwner, "ann@example.com", UserRoleOwner, RegistrationApproval, nil},
+		{UserRoleMaster, "", "", "", ErrRoleRequiresInvitation},
+		{UserRoleAdmin, "", "", "", ErrRoleNotSelfAssignable},
+		{"superuser", "", "", "", ErrInvalidRole},
+	}
+	for _, tt := range tests {
+		got, mode, err := p.Check(tt.role, tt.email)
+		if got != tt.want || mode != tt.mode || !errors.Is(err, tt.err) {
+			t.Errorf("Check(%q, %q) = %q, %q, %v, want %q, %q, %v",
+				tt.role, tt.email, got, mode, err, tt.want, tt.mode, tt.err)
+		}
+	}
+
+	restricted := &RegistrationPolicy{Roles: map[string]RegistrationRule{
+		UserRoleOwner: {Mode: RegistrationOpen, EmailDomains: []string{"corp.example.com"}},
+	}}
+	if _, _, err := restricted.Check(UserRoleOwner, "ann@Corp.Example.com"); err != nil {
+		t.Errorf("Check with an allowed domain = %v, want nil", err)
+	}
+	if _, _, err := restricted.Check(UserRoleOwner, "ann@example.com"); !errors.Is(err, ErrEmailDomainNotAllowed) {
+		t.Errorf("Check with another domain = %v, want ErrEmailDomainNotAllowed", err)
+	}
+
+	closed := *p
+	closed.InviteOnly = true
+	if _, _, err := closed.Check("", ""); !errors.Is(err, ErrRegistrationClosed) {
+		t.Errorf("Check on an invite-only policy = %v, want ErrRegistrationClosed", err)
+	}
+}
+
+func TestDomainRulesCheck(t *testing.T) {
+	rules := DomainRules{
+		Allow: []string{"example.com", "*.corp.example.org"},
+		Deny:  []string{"*.blocked.example.com"},
+	}
+	tests := []struct {
+		email string
+		rules DomainRules
+		ok    bool
+	}{
+		{"ann@example.com", rules, true},
+		{"Ann@Example.COM ", rules, true},
+		{"ann@sub.example.com", rules, false},
+		{"ann@evilexample.com", rules, false},
+		{"ann@a.corp.example.org", rules, true},
+		{"ann@corp.example.org", rules, false},
+		{"ann@xcorp.example.org", rules, false},
+		{"ann@other.org", rules, false},
+		{"ann@x.blocked.example.com", DomainRules{Deny: rules.Deny}, false},
+		{"ann@blocked.example.com", DomainRules{Deny: rules.Deny}, true},
+		{"ann@other.org", DomainRules{Deny: rules.Deny}, true},
+		{"ann@mail.example.com", DomainRules{Allow: []string{"*.example.com"}, Deny: []string{"mail.example.com"}}, false},
+		{"", DomainRules{}, true},
+		{"", rules, false},
+	}
+	for _, tt := range tests {
+		err := tt.rules.Check(tt.email)
+		if tt.ok && err != nil {
+			t.Errorf("Check(%q) = %v, want nil", tt.email, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrEmailDomainNotAllowed) {
+			t.Errorf("Check(%q) = %v, want ErrEmailDomainNotAllowed", tt.email, err)
+		}
+	}
+}
+
+func TestNormalizeDomainPattern(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"Example.COM", "example.com", true},
+		{" @example.com ", "example.com", true},
+		{"*.Example.com", "*.example.com", true},
+		{"com", "", false},
+		{"*.com", "", false},
+		{"*example.com", "", false},
+		{".example.com", "", false},
+		{"example.com.", "", false},
+		{"a*.example.com", "", false},
+		{"ann@example.com", "", false},
+		{"example.com/path", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizeDomainPattern(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeDomainPattern(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRegistrationPolicyCheckOpen(t *testing.T) {
+	p := &RegistrationPolicy{
+		DefaultRole: UserRoleClient,
+		Roles: map[string]RegistrationRule{
+			UserRoleClient: {Mode: RegistrationOpen},
+			UserRoleOwner:  {Mode: RegistrationApproval},
+			UserRoleMaster: {Mode: RegistrationOpen, EmailDomains: []string{"corp.example.com"}},
+		},
+	}
+	tests := []struct {
+		role, email string
+		want        string
+		err         error
+	}{
+		{"", "", UserRoleClient, nil},
+		{UserRoleClient, "ann@example.com", UserRoleClient, nil},
+		{UserRoleOwner, "ann@example.com", "", ErrRegistrationClosed},
+		{UserRoleMaster, "ann@Corp.Example.com", UserRoleMaster, nil},
+		{UserRoleMaster, "ann@example.com", "", ErrEmailDomainNotAllowed},
+		{UserRoleAdmin, "", "", ErrRoleNotSelfAssignable},
+		{"superuser", "", "", ErrInvalidRole},
+	}
+	for _, tt := range tests {
+		got, err := p.CheckOpen(tt.role, tt.email)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("CheckOpen(%q, %q) = %q, %v, want %q, %v", tt.role, tt.email, got, err, tt.want, tt.err)
+		}
+	}
+
+	closed := *p
+	closed.InviteOnly = true
+	if _, err := closed.CheckOpen("", ""); !errors.Is(err, ErrRegistrationClosed) {
+		t.Errorf("CheckOpen on an invite-only policy = %v, want ErrRegistrationClosed", err)
+	}
+}
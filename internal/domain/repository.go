@@ -9,13 +9,301 @@ type UserRepository interface {
 	Create(ctx context.Context, user *User) error
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	GetByID(ctx context.Context, id string) (*User, error)
+	GetByPhone(ctx context.Context, phone string) (*User, error)
+	// UpgradeGuest stores the email, phone, password and names of a guest
+	// and clears IsGuest. It fails with ErrNotGuest for other users.
+	UpgradeGuest(ctx context.Context, user *User) error
+	// Lock holds the user row until the transaction ends, serializing
+	// changes to what the user signs in with.
+	Lock(ctx context.Context, id string) error
 	//Update(ctx context.Context, user *domain.User) error
 	//Delete(ctx context.Context, id string) error
 }
 
 type TokenRepository interface {
 	Create(ctx context.Context, userID, tokenHash string, expiresAt time.Time) error
+	CreateForBusiness(ctx context.Context, userID, businessID, tokenHash string, expiresAt time.Time) error
+	CreateForClient(ctx context.Context, userID, clientID, scope, tokenHash string, expiresAt time.Time) error
 	FindByHash(ctx context.Context, hash string) (*RefreshToken, error)
 	DeleteByHash(ctx context.Context, hash string) error
 	DeleteAllByUserID(ctx context.Context, userID string) error
+	ListSessions(ctx context.Context, userID string) ([]*Session, error)
+	// DeleteSession removes one of the user's refresh tokens by id.
+	DeleteSession(ctx context.Context, userID, sessionID string) error
+}
+
+// Transactor runs fn in a single database transaction. Repositories called
+// with the ctx passed to fn take part in that transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type MembershipRepository interface {
+	Create(ctx context.Context, m *Membership) error
+	GetByUserAndBusiness(ctx context.Context, userID, businessID string) (*Membership, error)
+	// GetEffective returns the membership closest to businessID walking up the
+	// business hierarchy, so a location-level membership overrides one granted
+	// at the organization.
+	GetEffective(ctx context.Context, userID, businessID string) (*Membership, error)
+	UpdateRole(ctx context.Context, m *Membership) error
+	// Grant creates an active membership with a built-in role, or reactivates
+	// and overwrites the existing one.
+	Grant(ctx context.Context, m *Membership) error
+}
+
+type InvitationRepository interface {
+	Create(ctx context.Context, inv *Invitation) error
+	GetByID(ctx context.Context, id string) (*Invitation, error)
+	GetByTokenHash(ctx context.Context, hash string) (*Invitation, error)
+	ListByBusiness(ctx context.Context, businessID string) ([]*Invitation, error)
+	Revoke(ctx context.Context, id string) error
+	MarkAccepted(ctx context.Context, id, userID string) error
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// DecisionLogger records permission decisions off the request path. Log
+// must not block.
+type DecisionLogger interface {
+	Log(r DecisionRecord)
+}
+
+type PermissionRepository interface {
+	// Known reports which of perms are defined in the catalog.
+	Known(ctx context.Context, perms []Permission) (map[Permission]bool, error)
+	ListByRole(ctx context.Context, scope, role string) ([]Permission, error)
+	ListByCustomRole(ctx context.Context, roleID string) ([]Permission, error)
+	// Deprecated reports which of perms are marked deprecated.
+	Deprecated(ctx context.Context, perms []Permission) (map[Permission]bool, error)
+
+	// ClaimNamespace returns the namespace locked for update, creating it at
+	// version 0 and owned by ownerID if it does not exist. Must run in a tx.
+	ClaimNamespace(ctx context.Context, name, ownerID string) (*PermissionNamespace, error)
+	SetNamespaceVersion(ctx context.Context, name string, version int) error
+	UpsertDefinitions(ctx context.Context, namespace string, defs []PermissionDefinition) error
+	// SetDefaultGrants replaces the built-in membership roles granted p.
+	SetDefaultGrants(ctx context.Context, p Permission, roles []string) error
+	// DeprecateMissing marks the namespace's permissions not in keep as
+	// deprecated and returns the ones newly marked.
+	DeprecateMissing(ctx context.Context, namespace string, keep []Permission) ([]Permission, error)
+}
+
+type CustomRoleRepository interface {
+	Create(ctx context.Context, role *CustomRole) error
+	Update(ctx context.Context, role *CustomRole) error
+	Delete(ctx context.Context, id string) error
+	GetByID(ctx context.Context, id string) (*CustomRole, error)
+	ListByBusiness(ctx context.Context, businessID string) ([]*CustomRole, error)
+	SetPermissions(ctx context.Context, roleID string, perms []Permission) error
+}
+
+type BusinessUnitRepository interface {
+	// LockHierarchy makes concurrent hierarchy changes wait for this
+	// transaction. Reads are not blocked.
+	LockHierarchy(ctx context.Context) error
+	SetParent(ctx context.Context, businessID, parentID string) error
+	// Ancestors returns businessID followed by its parents, nearest first.
+	Ancestors(ctx context.Context, businessID string) ([]string, error)
+	Children(ctx context.Context, businessID string) ([]string, error)
+}
+
+type OwnershipTransferRepository interface {
+	Create(ctx context.Context, t *OwnershipTransfer) error
+	GetByID(ctx context.Context, id string) (*OwnershipTransfer, error)
+	MarkAccepted(ctx context.Context, id string) error
+	Cancel(ctx context.Context, id string) error
+}
+
+type VerificationCodeRepository interface {
+	// Create replaces any pending code of the user for the same purpose.
+	Create(ctx context.Context, c *VerificationCode) error
+	// ReserveAttempt counts an attempt before the code is compared. It fails
+	// with ErrInvalidCredentials when no unexpired code has attempts left.
+	ReserveAttempt(ctx context.Context, userID, purpose string, maxAttempts int) (*VerificationCode, error)
+	Delete(ctx context.Context, userID, purpose string) error
+}
+
+type AuditRepository interface {
+	Record(ctx context.Context, e AuditEntry) error
+}
+
+type RelationTupleRepository interface {
+	Write(ctx context.Context, writes, deletes []RelationTuple) (int64, error)
+	// ReassignUser moves the tuples whose subject is user fromUserID to
+	// toUserID and returns the revision of the change.
+	ReassignUser(ctx context.Context, fromUserID, toUserID string) (int64, error)
+	ReadSubjects(ctx context.Context, object ObjectRef, relation string) ([]SubjectRef, error)
+	ReadObjects(ctx context.Context, subject ObjectRef) ([]ObjectRef, error)
+	CurrentRevision(ctx context.Context) (int64, error)
+}
+
+type PolicyConditionRepository interface {
+	Create(ctx context.Context, c *PolicyCondition) error
+	GetByID(ctx context.Context, id string) (*PolicyCondition, error)
+	Delete(ctx context.Context, id string) error
+	// ListByBusiness returns the conditions defined on businessID itself, or
+	// the platform-wide ones when businessID is empty.
+	ListByBusiness(ctx context.Context, businessID string) ([]*PolicyCondition, error)
+	// ListApplicable returns the platform-wide conditions plus those defined
+	// on businessID and its ancestors.
+	ListApplicable(ctx context.Context, businessID string) ([]*PolicyCondition, error)
+}
+
+type DecisionLogRepository interface {
+	InsertBatch(ctx context.Context, records []DecisionRecord) error
+	Query(ctx context.Context, f DecisionLogFilter) ([]*DecisionRecord, error)
+}
+
+type OAuthClientRepository interface {
+	Create(ctx context.Context, c *OAuthClient) error
+	GetByID(ctx context.Context, clientID string) (*OAuthClient, error)
+}
+
+type AuthorizationCodeRepository interface {
+	Create(ctx context.Context, code *AuthorizationCode) error
+	// Consume returns the code and marks it used. Unknown, expired and
+	// already used codes give ErrAuthorizationCodeInvalid.
+	Consume(ctx context.Context, codeHash string) (*AuthorizationCode, error)
+}
+
+type OAuthConsentRepository interface {
+	Get(ctx context.Context, userID, clientID string) (*OAuthConsent, error)
+	// Grant adds scopes to the user's consent for the client.
+	Grant(ctx context.Context, userID, clientID string, scopes []string) error
+}
+
+type ServiceAccountRepository interface {
+	// Create stores the account together with its backing service user and
+	// sets UserID and CreatedAt.
+	Create(ctx context.Context, a *ServiceAccount) error
+	GetByClientID(ctx context.Context, clientID string) (*ServiceAccount, error)
+}
+
+type DeviceAuthorizationRepository interface {
+	Create(ctx context.Context, d *DeviceAuthorization) error
+	// GetPending returns a pending, unexpired authorization by user code.
+	GetPending(ctx context.Context, userCode string) (*DeviceAuthorization, error)
+	// Resolve approves or denies a pending authorization on behalf of userID.
+	Resolve(ctx context.Context, userCode, userID string, approve bool) error
+	// Poll records a poll of the device code. slowDown reports that the
+	// previous poll was less than the interval ago; the interval is then
+	// raised by five seconds.
+	Poll(ctx context.Context, deviceCodeHash string) (d *DeviceAuthorization, slowDown bool, err error)
+	// Consume marks an approved authorization used, so tokens are issued once.
+	Consume(ctx context.Context, deviceCodeHash string) error
+}
+
+type QRLoginRepository interface {
+	Create(ctx context.Context, s *QRLoginSession) error
+	// Get returns an unexpired session that has not been consumed.
+	Get(ctx context.Context, id string) (*QRLoginSession, error)
+	// Scan binds a pending session to userID and marks it scanned.
+	Scan(ctx context.Context, id, userID string) (*QRLoginSession, error)
+	// Resolve approves or denies a session scanned by userID.
+	Resolve(ctx context.Context, id, userID string, approve bool) error
+	// Consume marks an approved session used and returns its user, so
+	// tokens are issued once.
+	Consume(ctx context.Context, id string) (userID string, err error)
+}
+
+type SharedDeviceRepository interface {
+	Create(ctx context.Context, d *SharedDevice) error
+	// GetByID returns a device that has not been revoked.
+	GetByID(ctx context.Context, id string) (*SharedDevice, error)
+	Revoke(ctx context.Context, id string) error
+	// SetPin creates or replaces the PIN of userID and clears any lockout.
+	SetPin(ctx context.Context, deviceID, userID, pinHash string) error
+	// ReservePinAttempt counts an attempt before the PIN is compared and
+	// locks the PIN once maxAttempts is reached, for lockout doubled with
+	// every earlier lockout. The maxLockouts-th lockout disables the PIN. It
+	// fails with ErrPinLocked while the PIN is locked and ErrPinDisabled
+	// once it is disabled.
+	ReservePinAttempt(ctx context.Context, deviceID, userID string, maxAttempts int, lockout time.Duration,
+		maxLockouts int) (*DevicePin, error)
+	ResetPinFailures(ctx context.Context, deviceID, userID string) error
+	// ListUsers returns active members with a PIN on the device.
+	ListUsers(ctx context.Context, deviceID string) ([]*DeviceUser, error)
+}
+
+// RateLimiter counts calls per key, such as a client address.
+type RateLimiter interface {
+	Allow(key string) bool
+}
+
+// DomainRuleStore holds the active DomainRules, which may change at run
+// time.
+type DomainRuleStore interface {
+	Rules() DomainRules
+	// Set replaces the allow and deny lists of the active environment.
+	Set(allow, deny []string) (DomainRules, error)
+}
+
+// IdentityProvider is an external OpenID Connect or OAuth 2.0 provider users
+// can sign in with.
+type IdentityProvider interface {
+	ID() string
+	Name() string
+	AllowsRedirectURI(uri string) bool
+	AuthorizationURL(ctx context.Context, state, nonce, codeChallenge, redirectURI string) (string, error)
+	// Exchange redeems the authorization code and returns the verified
+	// identity. Failures caused by the provider or the code wrap
+	// ErrExternalLoginFailed.
+	Exchange(ctx context.Context, code, codeVerifier, redirectURI, nonce string) (*ExternalIdentity, error)
+}
+
+type LinkedIdentityRepository interface {
+	Create(ctx context.Context, l *LinkedIdentity) error
+	Get(ctx context.Context, providerID, subject string) (*LinkedIdentity, error)
+	TouchLogin(ctx context.Context, providerID, subject string) error
+	ListByUser(ctx context.Context, userID string) ([]*LinkedIdentity, error)
+	Delete(ctx context.Context, userID, providerID string) error
+}
+
+type AccountMergeRequestRepository interface {
+	// Create stores a pending request, or returns the pending one for the
+	// same pair of users, and sets ID, Status and CreatedAt.
+	Create(ctx context.Context, r *AccountMergeRequest) error
+	ListPending(ctx context.Context) ([]*AccountMergeRequest, error)
+}
+
+type PendingRegistrationRepository interface {
+	Create(ctx context.Context, userID string) error
+	List(ctx context.Context) ([]*PendingRegistration, error)
+	// Approve activates the user; Reject deletes it. Both fail with
+	// ErrRegistrationNotFound unless the user is pending.
+	Approve(ctx context.Context, userID string) error
+	Reject(ctx context.Context, userID string) error
+}
+
+type UserMergeRepository interface {
+	// LockUsers holds the user rows for the rest of the transaction, so two
+	// merges of the same users run one after the other.
+	LockUsers(ctx context.Context, userIDs ...string) error
+	// Merge moves everything the source user holds, except relation tuples,
+	// to the target and leaves the tombstone. Run it inside a transaction.
+	Merge(ctx context.Context, m *UserMerge) error
+	// Resolve returns the user a merged user was folded into, or
+	// ErrUserNotFound when userID was never merged.
+	Resolve(ctx context.Context, userID string) (string, error)
+}
+
+type UserEventRepository interface {
+	Publish(ctx context.Context, e *UserEvent) error
+	// List returns up to limit events with an ID above afterID, oldest first.
+	List(ctx context.Context, afterID int64, limit int) ([]*UserEvent, error)
+}
+
+type FederatedLoginStateRepository interface {
+	Create(ctx context.Context, s *FederatedLoginState) error
+	// Consume deletes and returns an unexpired state for the provider.
+	Consume(ctx context.Context, stateHash, providerID string) (*FederatedLoginState, error)
+}
+
+type TokenExchangePolicyRepository interface {
+	// Upsert creates or replaces the policy for the client and audience.
+	Upsert(ctx context.Context, p *TokenExchangePolicy) error
+	Get(ctx context.Context, clientID, audience string) (*TokenExchangePolicy, error)
+	Delete(ctx context.Context, clientID, audience string) error
 }
@@ -7,6 +7,8 @@ type LoginUseCase interface {
 }
 
 type RegisterUseCase interface {
+	// Execute applies the registration policy. The token is nil when the
+	// account waits for admin approval.
 	Execute(ctx context.Context, req RegisterRequest) (*User, *AuthToken, error)
 }
 
@@ -25,3 +27,323 @@ type LogoutUseCase interface {
 type GetMeUseCase interface {
 	Execute(ctx context.Context, userID string) (*User, error)
 }
+
+type CreateInvitationUseCase interface {
+	Execute(ctx context.Context, req CreateInvitationRequest) (*Invitation, error)
+}
+
+type ListInvitationsUseCase interface {
+	Execute(ctx context.Context, callerID, businessID string) ([]*Invitation, error)
+}
+
+type RevokeInvitationUseCase interface {
+	Execute(ctx context.Context, callerID, invitationID string) error
+}
+
+type AcceptInvitationUseCase interface {
+	Execute(ctx context.Context, req AcceptInvitationRequest) (*User, *AuthToken, error)
+}
+
+type SwitchBusinessUseCase interface {
+	Execute(ctx context.Context, userID, businessID string) (*AuthToken, error)
+}
+
+type CheckPermissionUseCase interface {
+	Execute(ctx context.Context, req PermissionCheckRequest) ([]PermissionDecision, error)
+}
+
+type CreateCustomRoleUseCase interface {
+	Execute(ctx context.Context, callerID string, role *CustomRole) (*CustomRole, error)
+}
+
+type UpdateCustomRoleUseCase interface {
+	Execute(ctx context.Context, callerID string, role *CustomRole) (*CustomRole, error)
+}
+
+type DeleteCustomRoleUseCase interface {
+	Execute(ctx context.Context, callerID, roleID string) error
+}
+
+type ListCustomRolesUseCase interface {
+	Execute(ctx context.Context, callerID, businessID string) ([]*CustomRole, error)
+}
+
+type UpdateMembershipRoleUseCase interface {
+	Execute(ctx context.Context, req UpdateMembershipRoleRequest) (*Membership, error)
+}
+
+type SetBusinessParentUseCase interface {
+	Execute(ctx context.Context, callerID, businessID, parentID string) error
+}
+
+type ListChildBusinessesUseCase interface {
+	Execute(ctx context.Context, callerID, businessID string) ([]string, error)
+}
+
+type StartOwnershipTransferUseCase interface {
+	Execute(ctx context.Context, req StartOwnershipTransferRequest) (*OwnershipTransfer, error)
+}
+
+type AcceptOwnershipTransferUseCase interface {
+	Execute(ctx context.Context, callerID, transferID string) (*OwnershipTransfer, error)
+}
+
+type CancelOwnershipTransferUseCase interface {
+	Execute(ctx context.Context, callerID, transferID string) error
+}
+
+// The relation use cases accept an optional consistency token returned by an
+// earlier write and fail if the data they read is older than that write.
+
+type WriteRelationsUseCase interface {
+	Execute(ctx context.Context, callerID string, writes, deletes []RelationTuple) (string, error)
+}
+
+type CheckRelationUseCase interface {
+	// Execute records the decision in the decision log under callerID.
+	Execute(ctx context.Context, object ObjectRef, permission string, subject SubjectRef, consistencyToken string,
+		callerID string, client ClientInfo) (bool, string, error)
+}
+
+type ListObjectsUseCase interface {
+	Execute(ctx context.Context, objectType, permission string, subject SubjectRef, consistencyToken string) ([]string, string, error)
+}
+
+type ListSubjectsUseCase interface {
+	Execute(ctx context.Context, object ObjectRef, permission, subjectType, consistencyToken string) ([]string, string, error)
+}
+
+type CreatePolicyConditionUseCase interface {
+	Execute(ctx context.Context, callerID string, c *PolicyCondition) (*PolicyCondition, error)
+}
+
+type ListPolicyConditionsUseCase interface {
+	Execute(ctx context.Context, callerID, businessID string) ([]*PolicyCondition, error)
+}
+
+type DeletePolicyConditionUseCase interface {
+	Execute(ctx context.Context, callerID, conditionID string) error
+}
+
+type QueryDecisionLogUseCase interface {
+	Execute(ctx context.Context, callerID string, f DecisionLogFilter) ([]*DecisionRecord, error)
+}
+
+type RegisterPermissionsUseCase interface {
+	Execute(ctx context.Context, req RegisterPermissionsRequest) (*RegisterPermissionsResult, error)
+}
+
+type RegisterOAuthClientUseCase interface {
+	// Execute returns the client and, for confidential clients, its secret.
+	// The secret is not stored and cannot be shown again.
+	Execute(ctx context.Context, callerID string, client *OAuthClient, confidential bool) (*OAuthClient, string, error)
+}
+
+// ValidateAuthorizationUseCase checks an authorization request before the
+// user is asked to sign in. ErrOAuthClientNotFound and ErrInvalidRedirectURI
+// must be shown to the user; an *OAuthError is sent to the redirect URI.
+type ValidateAuthorizationUseCase interface {
+	Execute(ctx context.Context, req AuthorizationRequest) (*OAuthClient, error)
+}
+
+type AuthorizeUseCase interface {
+	// Execute signs the user in, records consent and returns an authorization code.
+	Execute(ctx context.Context, req AuthorizationRequest, email, password string) (string, error)
+}
+
+type OAuthTokenUseCase interface {
+	Execute(ctx context.Context, req TokenRequest) (*OAuthTokenResponse, error)
+}
+
+type CreateServiceAccountUseCase interface {
+	// Execute returns the account and its client secret. The secret is not
+	// stored and cannot be shown again.
+	Execute(ctx context.Context, callerID string, account *ServiceAccount) (*ServiceAccount, string, error)
+}
+
+type StartDeviceAuthorizationUseCase interface {
+	Execute(ctx context.Context, clientID, clientSecret, scope string) (*DeviceAuthorizationResponse, error)
+}
+
+type LookupDeviceUseCase interface {
+	// Execute returns the pending authorization of userCode, so the client
+	// name and scope can be shown before the user approves it.
+	Execute(ctx context.Context, userCode string) (*DeviceAuthorization, error)
+}
+
+type ApproveDeviceUseCase interface {
+	// Execute returns the resolved authorization, so the client name and
+	// scope can be shown to the user.
+	Execute(ctx context.Context, req DeviceApproval) (*DeviceAuthorization, error)
+}
+
+type StartQRLoginUseCase interface {
+	Execute(ctx context.Context, client ClientInfo) (*QRLoginStart, error)
+}
+
+type ScanQRLoginUseCase interface {
+	// Execute returns the session, so the phone can show where the sign-in
+	// request comes from before the user approves it.
+	Execute(ctx context.Context, userID, sessionID string) (*QRLoginSession, error)
+}
+
+type ApproveQRLoginUseCase interface {
+	Execute(ctx context.Context, userID, sessionID string, approve bool) error
+}
+
+type PollQRLoginUseCase interface {
+	// Execute waits until the session status differs from lastStatus or the
+	// context is done, and returns the current state.
+	Execute(ctx context.Context, client ClientInfo, sessionID, pollSecret, lastStatus string) (*QRLoginPoll, error)
+}
+
+type RegisterSharedDeviceUseCase interface {
+	// Execute returns the device and its secret. The secret is not stored and
+	// cannot be shown again.
+	Execute(ctx context.Context, callerID string, d *SharedDevice) (*SharedDevice, string, error)
+}
+
+type RevokeSharedDeviceUseCase interface {
+	Execute(ctx context.Context, callerID, deviceID string) error
+}
+
+type SetDevicePinUseCase interface {
+	Execute(ctx context.Context, userID, deviceID, pin string) error
+}
+
+type ListDeviceUsersUseCase interface {
+	Execute(ctx context.Context, deviceID, deviceSecret string) ([]*DeviceUser, error)
+}
+
+type PinLoginUseCase interface {
+	// Execute returns a short-lived access token bound to the device's
+	// business. No refresh token is issued.
+	Execute(ctx context.Context, req PinLoginRequest) (*User, *AuthToken, error)
+}
+
+type ListIdentityProvidersUseCase interface {
+	Execute(ctx context.Context) ([]IdentityProvider, error)
+}
+
+type StartFederatedLoginUseCase interface {
+	Execute(ctx context.Context, providerID, redirectURI string) (*FederatedLoginStart, error)
+}
+
+type CompleteFederatedLoginUseCase interface {
+	Execute(ctx context.Context, providerID, state, code string) (*FederatedLoginResult, error)
+}
+
+type TelegramLoginUseCase interface {
+	// Execute verifies a Telegram Login Widget payload and signs in the
+	// linked user, creating one on first sign-in.
+	Execute(ctx context.Context, data map[string]string) (*FederatedLoginResult, error)
+}
+
+type ListLoginMethodsUseCase interface {
+	Execute(ctx context.Context, userID string) ([]*LoginMethod, error)
+}
+
+type StartLinkIdentityUseCase interface {
+	Execute(ctx context.Context, userID, providerID, redirectURI string) (*FederatedLoginStart, error)
+}
+
+type CompleteLinkIdentityUseCase interface {
+	Execute(ctx context.Context, userID, providerID, state, code string) (*LinkIdentityResult, error)
+}
+
+type LinkTelegramUseCase interface {
+	Execute(ctx context.Context, userID string, data map[string]string) (*LinkIdentityResult, error)
+}
+
+type UnlinkIdentityUseCase interface {
+	// Execute fails with ErrLastLoginMethod rather than leave the user
+	// without a way to sign in.
+	Execute(ctx context.Context, userID, providerID string) error
+}
+
+type ListMergeRequestsUseCase interface {
+	Execute(ctx context.Context, callerID string) ([]*AccountMergeRequest, error)
+}
+
+type MergeAccountsUseCase interface {
+	// Execute folds sourceUserID into targetUserID and publishes
+	// UserEventMerged.
+	Execute(ctx context.Context, callerID, sourceUserID, targetUserID string) (*UserMerge, error)
+}
+
+type ResolveUserIDUseCase interface {
+	// Execute returns the current ID of a user, following merges.
+	Execute(ctx context.Context, userID string) (string, error)
+}
+
+type ListUserEventsUseCase interface {
+	Execute(ctx context.Context, afterID int64, limit int) ([]*UserEvent, error)
+}
+
+type CreateGuestUseCase interface {
+	Execute(ctx context.Context, req GuestRequest) (*GuestSession, error)
+}
+
+type GuestLoginUseCase interface {
+	Execute(ctx context.Context, deviceSecret string) (*User, *AuthToken, error)
+}
+
+type UpgradeGuestUseCase interface {
+	// Execute turns the guest userID into a full account without changing
+	// its ID, so whatever it did as a guest stays attached.
+	Execute(ctx context.Context, userID string, req UpgradeGuestRequest) (*User, *AuthToken, error)
+}
+
+type ListPendingRegistrationsUseCase interface {
+	Execute(ctx context.Context, callerID string) ([]*PendingRegistration, error)
+}
+
+type ResolveRegistrationUseCase interface {
+	// Execute activates the pending user when approve is set and deletes it
+	// otherwise.
+	Execute(ctx context.Context, callerID, userID string, approve bool) error
+}
+
+type GetDomainRulesUseCase interface {
+	Execute(ctx context.Context, callerID string) (DomainRules, error)
+}
+
+type SetDomainRulesUseCase interface {
+	Execute(ctx context.Context, callerID string, allow, deny []string) (DomainRules, error)
+}
+
+type ListSessionsUseCase interface {
+	Execute(ctx context.Context, userID string) ([]*Session, error)
+}
+
+type RevokeSessionUseCase interface {
+	Execute(ctx context.Context, userID, sessionID string) error
+}
+
+type IntrospectTokenUseCase interface {
+	Execute(ctx context.Context, req ClientTokenRequest) (*TokenIntrospection, error)
+}
+
+type RevokeTokenUseCase interface {
+	// Execute revokes a refresh token issued to the calling client. Unknown
+	// and expired tokens are not an error.
+	Execute(ctx context.Context, req ClientTokenRequest) error
+}
+
+type ExchangeTokenUseCase interface {
+	Execute(ctx context.Context, req TokenExchangeRequest) (*TokenExchangeResult, error)
+}
+
+type SetTokenExchangePolicyUseCase interface {
+	Execute(ctx context.Context, callerID string, p *TokenExchangePolicy) (*TokenExchangePolicy, error)
+}
+
+type DeleteTokenExchangePolicyUseCase interface {
+	Execute(ctx context.Context, callerID, clientID, audience string) error
+}
+
+type UserInfoUseCase interface {
+	// Execute returns the standard claims of the user an OAuth access token was
+	// issued for, limited to the scopes granted to the client.
+	Execute(ctx context.Context, accessToken string) (map[string]any, error)
+}
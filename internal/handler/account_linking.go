@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *IdentityHandler) ListLoginMethods(ctx context.Context, req *identityv1.ListLoginMethodsRequest) (*identityv1.ListLoginMethodsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	methods, err := h.listLoginMethodsUC.Execute(ctx, userID)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	resp := &identityv1.ListLoginMethodsResponse{}
+	for _, m := range methods {
+		pm := &identityv1.LoginMethod{
+			Type:       m.Type,
+			ProviderId: m.ProviderID,
+			Email:      m.Email,
+		}
+		if !m.CreatedAt.IsZero() {
+			pm.CreatedAt = timestamppb.New(m.CreatedAt)
+		}
+		if !m.LastLoginAt.IsZero() {
+			pm.LastLoginAt = timestamppb.New(m.LastLoginAt)
+		}
+		resp.Methods = append(resp.Methods, pm)
+	}
+	return resp, nil
+}
+
+// StartLinkIdentity is StartFederatedLogin for a signed-in user; the state it
+// returns is only accepted by CompleteLinkIdentity for the same user.
+func (h *IdentityHandler) StartLinkIdentity(ctx context.Context, req *identityv1.StartFederatedLoginRequest) (*identityv1.StartFederatedLoginResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.ProviderId == "" || req.RedirectUri == "" {
+		return nil, status.Error(codes.InvalidArgument, "provider id and redirect uri required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	start, err := h.startLinkIdentityUC.Execute(ctx, userID, req.ProviderId, req.RedirectUri)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.StartFederatedLoginResponse{
+		AuthorizationUrl: start.AuthorizationURL,
+		State:            start.State,
+		ExpiresAt:        timestamppb.New(start.ExpiresAt),
+	}, nil
+}
+
+// CompleteLinkIdentity links the provider account to the caller. When that
+// account already signs in to another user, nothing is linked and the
+// response carries the id of the merge request filed instead.
+func (h *IdentityHandler) CompleteLinkIdentity(ctx context.Context, req *identityv1.CompleteFederatedLoginRequest) (*identityv1.LinkIdentityResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.ProviderId == "" || req.State == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "provider id, state and code required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	result, err := h.completeLinkIdentityUC.Execute(ctx, userID, req.ProviderId, req.State, req.Code)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return mapLinkIdentityResult(result), nil
+}
+
+func (h *IdentityHandler) LinkTelegram(ctx context.Context, req *identityv1.TelegramLoginRequest) (*identityv1.LinkIdentityResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Data) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "telegram auth data required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	result, err := h.linkTelegramUC.Execute(ctx, userID, req.Data)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return mapLinkIdentityResult(result), nil
+}
+
+func (h *IdentityHandler) UnlinkIdentity(ctx context.Context, req *identityv1.UnlinkIdentityRequest) (*identityv1.UnlinkIdentityResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.ProviderId == "" {
+		return nil, status.Error(codes.InvalidArgument, "provider id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := h.unlinkIdentityUC.Execute(ctx, userID, req.ProviderId); err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.UnlinkIdentityResponse{}, nil
+}
+
+func mapLinkIdentityResult(r *domain.LinkIdentityResult) *identityv1.LinkIdentityResponse {
+	resp := &identityv1.LinkIdentityResponse{}
+	if r.Identity != nil {
+		resp.ProviderId = r.Identity.ProviderID
+		resp.Email = r.Identity.Email
+	}
+	if r.MergeRequest != nil {
+		resp.MergeRequestId = r.MergeRequest.ID
+	}
+	return resp
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *IdentityHandler) SetBusinessParent(ctx context.Context, req *identityv1.SetBusinessParentRequest) (*identityv1.SetBusinessParentResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.BusinessId == "" {
+		return nil, status.Error(codes.InvalidArgument, "business id required")
+	}
+	if req.BusinessId == req.ParentId {
+		return nil, status.Error(codes.InvalidArgument, "business cannot be its own parent")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := h.setBusinessParentUC.Execute(ctx, userID, req.BusinessId, req.ParentId); err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.SetBusinessParentResponse{}, nil
+}
+
+func (h *IdentityHandler) ListChildBusinesses(ctx context.Context, req *identityv1.ListChildBusinessesRequest) (*identityv1.ListChildBusinessesResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.BusinessId == "" {
+		return nil, status.Error(codes.InvalidArgument, "business id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	ids, err := h.listChildBusinessesUC.Execute(ctx, userID, req.BusinessId)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.ListChildBusinessesResponse{BusinessIds: ids}, nil
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/interceptor"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func userIDFromContext(ctx context.Context) (string, error) {
+	userID, ok := ctx.Value(interceptor.UserIDKey).(string)
+	if !ok || userID == "" {
+		return "", status.Error(codes.Unauthenticated, "user not found in context")
+	}
+	return userID, nil
+}
+
+// businessFromContext returns the business the caller's token is scoped to,
+// empty for tokens without one.
+func businessFromContext(ctx context.Context) string {
+	businessID, _ := ctx.Value(interceptor.BusinessIDKey).(string)
+	return businessID
+}
+
+// serviceAccountFromContext returns the client_id of a service account caller.
+func serviceAccountFromContext(ctx context.Context) (string, error) {
+	clientID, ok := ctx.Value(interceptor.ServiceAccountKey).(string)
+	if !ok || clientID == "" {
+		return "", status.Error(codes.PermissionDenied, "only service accounts may call this method")
+	}
+	return clientID, nil
+}
+
+func isServiceAccount(ctx context.Context) bool {
+	_, err := serviceAccountFromContext(ctx)
+	return err == nil
+}
+
+// clientInfoFromContext describes the caller as recorded by the
+// ClientInfo interceptor.
+func clientInfoFromContext(ctx context.Context) domain.ClientInfo {
+	info, _ := ctx.Value(interceptor.ClientInfoKey).(domain.ClientInfo)
+	return info
+}
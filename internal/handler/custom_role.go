@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *IdentityHandler) CreateBusinessRole(ctx context.Context, req *identityv1.CreateBusinessRoleRequest) (*identityv1.CreateBusinessRoleResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.BusinessId == "" || req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "business id and name required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	role, err := h.createCustomRoleUC.Execute(ctx, userID, &domain.CustomRole{
+		BusinessID:  req.BusinessId,
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: mapPermissionsFromProto(req.Permissions),
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.CreateBusinessRoleResponse{Role: mapCustomRoleToProto(role)}, nil
+}
+
+func (h *IdentityHandler) UpdateBusinessRole(ctx context.Context, req *identityv1.UpdateBusinessRoleRequest) (*identityv1.UpdateBusinessRoleResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.RoleId == "" || req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "role id and name required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	role, err := h.updateCustomRoleUC.Execute(ctx, userID, &domain.CustomRole{
+		ID:          req.RoleId,
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: mapPermissionsFromProto(req.Permissions),
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.UpdateBusinessRoleResponse{Role: mapCustomRoleToProto(role)}, nil
+}
+
+func (h *IdentityHandler) DeleteBusinessRole(ctx context.Context, req *identityv1.DeleteBusinessRoleRequest) (*identityv1.DeleteBusinessRoleResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.RoleId == "" {
+		return nil, status.Error(codes.InvalidArgument, "role id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := h.deleteCustomRoleUC.Execute(ctx, userID, req.RoleId); err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.DeleteBusinessRoleResponse{}, nil
+}
+
+func (h *IdentityHandler) ListBusinessRoles(ctx context.Context, req *identityv1.ListBusinessRolesRequest) (*identityv1.ListBusinessRolesResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.BusinessId == "" {
+		return nil, status.Error(codes.InvalidArgument, "business id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	roles, err := h.listCustomRolesUC.Execute(ctx, userID, req.BusinessId)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	resp := &identityv1.ListBusinessRolesResponse{}
+	for _, role := range roles {
+		resp.Roles = append(resp.Roles, mapCustomRoleToProto(role))
+	}
+	return resp, nil
+}
+
+func (h *IdentityHandler) UpdateMembershipRole(ctx context.Context, req *identityv1.UpdateMembershipRoleRequest) (*identityv1.UpdateMembershipRoleResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.BusinessId == "" || req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "business id and user id required")
+	}
+	if (req.Role == "") == (req.CustomRoleId == "") {
+		return nil, status.Error(codes.InvalidArgument, "exactly one of role and custom role id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	m, err := h.updateMembershipRoleUC.Execute(ctx, domain.UpdateMembershipRoleRequest{
+		CallerID:     userID,
+		BusinessID:   req.BusinessId,
+		UserID:       req.UserId,
+		Role:         req.Role,
+		CustomRoleID: req.CustomRoleId,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.UpdateMembershipRoleResponse{Membership: mapMembershipToProto(m)}, nil
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *IdentityHandler) QueryDecisionLog(ctx context.Context, req *identityv1.QueryDecisionLogRequest) (*identityv1.QueryDecisionLogResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f := domain.DecisionLogFilter{
+		UserID:     req.UserId,
+		BusinessID: req.BusinessId,
+		Limit:      int(req.Limit),
+	}
+	if req.From != nil {
+		f.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		f.To = req.To.AsTime()
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return nil, status.Error(codes.InvalidArgument, "from must be before to")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	records, err := h.queryDecisionLogUC.Execute(ctx, userID, f)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	resp := &identityv1.QueryDecisionLogResponse{}
+	for _, r := range records {
+		resp.Decisions = append(resp.Decisions, mapDecisionRecordToProto(r))
+	}
+	return resp, nil
+}
@@ -4,11 +4,17 @@ import (
 	"errors"
 
 	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/rebac"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 func handleError(err error) error {
+	// use cases that already speak gRPC statuses pass through as is
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var oerr *domain.OAuthError
 	switch {
 	case errors.Is(err, domain.ErrInvalidCredentials):
 		return status.Error(codes.Unauthenticated, "invalid credentials")
@@ -16,7 +22,128 @@ func handleError(err error) error {
 		return status.Error(codes.AlreadyExists, "email already registered")
 	case errors.Is(err, domain.ErrRefreshTokenNotFound):
 		return status.Error(codes.Unauthenticated, "refresh token not found or expired")
+	case errors.Is(err, domain.ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, "permission denied")
+	case errors.Is(err, domain.ErrInvalidRole):
+		return status.Error(codes.InvalidArgument, "invalid role")
+	case errors.Is(err, domain.ErrMembershipExists):
+		return status.Error(codes.AlreadyExists, "user is already a member of this business")
+	case errors.Is(err, domain.ErrInvitationNotFound):
+		return status.Error(codes.NotFound, "invitation not found")
+	case errors.Is(err, domain.ErrInvitationNotPending):
+		return status.Error(codes.FailedPrecondition, "invitation expired, revoked or already accepted")
+	case errors.Is(err, domain.ErrTokenExpired), errors.Is(err, domain.ErrTokenMalformed):
+		return status.Error(codes.Unauthenticated, "invalid or expired token")
+	case errors.Is(err, domain.ErrUserNotFound):
+		return status.Error(codes.NotFound, "user not found")
+	case errors.Is(err, domain.ErrUserNotActive):
+		return status.Error(codes.PermissionDenied, "user not active")
+	case errors.Is(err, domain.ErrMembershipNotFound):
+		return status.Error(codes.NotFound, "membership not found")
+	case errors.Is(err, domain.ErrRoleNotFound):
+		return status.Error(codes.NotFound, "role not found")
+	case errors.Is(err, domain.ErrRoleExists):
+		return status.Error(codes.AlreadyExists, "role with this name already exists")
+	case errors.Is(err, domain.ErrRoleInUse):
+		return status.Error(codes.FailedPrecondition, "role is assigned to members")
+	case errors.Is(err, domain.ErrUnknownPermission):
+		return status.Error(codes.InvalidArgument, "unknown permission")
+	case errors.Is(err, domain.ErrHierarchyCycle):
+		return status.Error(codes.FailedPrecondition, "business cannot be placed under its own descendant")
+	case errors.Is(err, domain.ErrTransferNotFound):
+		return status.Error(codes.NotFound, "ownership transfer not found")
+	case errors.Is(err, domain.ErrTransferNotPending):
+		return status.Error(codes.FailedPrecondition, "ownership transfer expired or already resolved")
+	case errors.Is(err, domain.ErrTransferInProgress):
+		return status.Error(codes.AlreadyExists, "business already has a pending ownership transfer")
+	case errors.Is(err, domain.ErrConfirmationRequired):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrInvalidTuple):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrSchemaViolation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrInvalidConsistency):
+		return status.Error(codes.InvalidArgument, "invalid consistency token")
+	case errors.Is(err, domain.ErrConsistencyAhead):
+		return status.Error(codes.Unavailable, "consistency token is newer than the data available, retry")
+	case errors.Is(err, domain.ErrInvalidCondition):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrConditionNotFound):
+		return status.Error(codes.NotFound, "policy condition not found")
+	case errors.Is(err, domain.ErrInvalidNamespace):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrNamespaceOwned):
+		return status.Error(codes.PermissionDenied, "permission namespace belongs to another service")
+	case errors.Is(err, domain.ErrPermissionDeprecated):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrOAuthClientNotFound):
+		return status.Error(codes.NotFound, "oauth client not found")
+	case errors.Is(err, domain.ErrDeviceCodeNotFound):
+		return status.Error(codes.NotFound, "device code is invalid or expired")
+	case errors.Is(err, domain.ErrSessionNotFound):
+		return status.Error(codes.NotFound, "session not found")
+	case errors.Is(err, domain.ErrQRLoginNotFound):
+		return status.Error(codes.NotFound, "qr login session is invalid or expired")
+	case errors.Is(err, domain.ErrQRLoginPolling):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, domain.ErrSharedDeviceNotFound):
+		return status.Error(codes.NotFound, "shared device not found")
+	case errors.Is(err, domain.ErrInvalidDeviceName), errors.Is(err, domain.ErrInvalidPin):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrQRLoginBusy), errors.Is(err, domain.ErrTooManyRequests):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, domain.ErrPinLocked):
+		return status.Error(codes.ResourceExhausted, "too many failed pin attempts, try again later")
+	case errors.Is(err, domain.ErrPinDisabled):
+		return status.Error(codes.FailedPrecondition, "pin disabled after repeated lockouts, set a new one")
+	case errors.Is(err, domain.ErrIdentityProviderNotFound):
+		return status.Error(codes.NotFound, "identity provider not found")
+	case errors.Is(err, domain.ErrFederatedStateInvalid):
+		return status.Error(codes.InvalidArgument, "federated login state is invalid or expired")
+	case errors.Is(err, domain.ErrExternalLoginFailed):
+		return status.Error(codes.Unauthenticated, "external sign-in failed")
+	case errors.Is(err, domain.ErrInvalidRedirectURI):
+		return status.Error(codes.InvalidArgument, "redirect uri is not registered")
+	case errors.Is(err, domain.ErrLinkedIdentityNotFound):
+		return status.Error(codes.NotFound, "provider is not linked")
+	case errors.Is(err, domain.ErrProviderAlreadyLinked):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, domain.ErrLastLoginMethod):
+		return status.Error(codes.FailedPrecondition, "cannot remove the last login method")
+	case errors.Is(err, domain.ErrPhoneExists):
+		return status.Error(codes.AlreadyExists, "phone already registered")
+	case errors.Is(err, domain.ErrNotGuest):
+		return status.Error(codes.FailedPrecondition, "user is not a guest")
+	case errors.Is(err, domain.ErrRegistrationClosed):
+		return status.Error(codes.PermissionDenied, "registration is by invitation only")
+	case errors.Is(err, domain.ErrRoleNotSelfAssignable), errors.Is(err, domain.ErrRoleRequiresInvitation),
+		errors.Is(err, domain.ErrEmailDomainNotAllowed):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrInvalidDomainRule):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrRegistrationNotFound):
+		return status.Error(codes.NotFound, "pending registration not found")
+	case errors.Is(err, domain.ErrInvalidMerge):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, domain.ErrServiceAccountNotFound):
+		return status.Error(codes.NotFound, "service account not found")
+	case errors.Is(err, domain.ErrExchangePolicyNotFound):
+		return status.Error(codes.NotFound, "token exchange policy not found")
+	case errors.Is(err, domain.ErrExchangeNotAllowed):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.As(err, &oerr):
+		switch oerr.Code {
+		case domain.OAuthInvalidClient:
+			return status.Error(codes.Unauthenticated, oerr.Error())
+		case domain.OAuthUnauthorizedClient:
+			return status.Error(codes.PermissionDenied, oerr.Error())
+		case domain.OAuthUnsupportedTokenType:
+			return status.Error(codes.Unimplemented, oerr.Error())
+		}
+		return status.Error(codes.InvalidArgument, oerr.Error())
+	case errors.Is(err, rebac.ErrMaxDepth):
+		return status.Error(codes.FailedPrecondition, "relation graph too deep")
 	default:
 		return status.Error(codes.Internal, "internal error")
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *IdentityHandler) ListIdentityProviders(ctx context.Context, req *identityv1.ListIdentityProvidersRequest) (*identityv1.ListIdentityProvidersResponse, error) {
+	providers, err := h.listIdentityProvidersUC.Execute(ctx)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	resp := &identityv1.ListIdentityProvidersResponse{}
+	for _, p := range providers {
+		resp.Providers = append(resp.Providers, &identityv1.IdentityProvider{
+			Id:   p.ID(),
+			Name: p.Name(),
+		})
+	}
+	return resp, nil
+}
+
+// StartFederatedLogin returns the provider URL to open in a browser. The
+// provider redirects back to redirect_uri, which must be registered for it.
+func (h *IdentityHandler) StartFederatedLogin(ctx context.Context, req *identityv1.StartFederatedLoginRequest) (*identityv1.StartFederatedLoginResponse, error) {
+	if req.ProviderId == "" || req.RedirectUri == "" {
+		return nil, status.Error(codes.InvalidArgument, "provider id and redirect uri required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	start, err := h.startFederatedLoginUC.Execute(ctx, req.ProviderId, req.RedirectUri)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.StartFederatedLoginResponse{
+		AuthorizationUrl: start.AuthorizationURL,
+		State:            start.State,
+		ExpiresAt:        timestamppb.New(start.ExpiresAt),
+	}, nil
+}
+
+func (h *IdentityHandler) CompleteFederatedLogin(ctx context.Context, req *identityv1.CompleteFederatedLoginRequest) (*identityv1.CompleteFederatedLoginResponse, error) {
+	if req.ProviderId == "" || req.State == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "provider id, state and code required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	result, err := h.completeFederatedLoginUC.Execute(ctx, req.ProviderId, req.State, req.Code)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.CompleteFederatedLoginResponse{
+		User:      mapUserToProto(result.User),
+		AuthToken: mapTokenToProto(result.Token),
+		Created:   result.Created,
+	}, nil
+}
+
+// TelegramLogin signs in with the fields returned by the Telegram Login
+// Widget, passed unchanged in data.
+func (h *IdentityHandler) TelegramLogin(ctx context.Context, req *identityv1.TelegramLoginRequest) (*identityv1.CompleteFederatedLoginResponse, error) {
+	if len(req.Data) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "telegram auth data required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	result, err := h.telegramLoginUC.Execute(ctx, req.Data)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.CompleteFederatedLoginResponse{
+		User:      mapUserToProto(result.User),
+		AuthToken: mapTokenToProto(result.Token),
+		Created:   result.Created,
+	}, nil
+}
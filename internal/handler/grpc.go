@@ -18,6 +18,81 @@ type IdentityHandler struct {
 	validateUC domain.ValidateUseCase
 	logoutUC   domain.LogoutUseCase
 	getMeUC    domain.GetMeUseCase
+
+	createInvitationUC domain.CreateInvitationUseCase
+	listInvitationsUC  domain.ListInvitationsUseCase
+	revokeInvitationUC domain.RevokeInvitationUseCase
+	acceptInvitationUC domain.AcceptInvitationUseCase
+
+	switchBusinessUC  domain.SwitchBusinessUseCase
+	checkPermissionUC domain.CheckPermissionUseCase
+
+	createCustomRoleUC     domain.CreateCustomRoleUseCase
+	updateCustomRoleUC     domain.UpdateCustomRoleUseCase
+	deleteCustomRoleUC     domain.DeleteCustomRoleUseCase
+	listCustomRolesUC      domain.ListCustomRolesUseCase
+	updateMembershipRoleUC domain.UpdateMembershipRoleUseCase
+
+	setBusinessParentUC   domain.SetBusinessParentUseCase
+	listChildBusinessesUC domain.ListChildBusinessesUseCase
+
+	startOwnershipTransferUC  domain.StartOwnershipTransferUseCase
+	acceptOwnershipTransferUC domain.AcceptOwnershipTransferUseCase
+	cancelOwnershipTransferUC domain.CancelOwnershipTransferUseCase
+
+	writeRelationsUC domain.WriteRelationsUseCase
+	checkRelationUC  domain.CheckRelationUseCase
+	listObjectsUC    domain.ListObjectsUseCase
+	listSubjectsUC   domain.ListSubjectsUseCase
+
+	createPolicyConditionUC domain.CreatePolicyConditionUseCase
+	listPolicyConditionsUC  domain.ListPolicyConditionsUseCase
+	deletePolicyConditionUC domain.DeletePolicyConditionUseCase
+	queryDecisionLogUC      domain.QueryDecisionLogUseCase
+	registerPermissionsUC   domain.RegisterPermissionsUseCase
+	registerOAuthClientUC   domain.RegisterOAuthClientUseCase
+	createServiceAccountUC  domain.CreateServiceAccountUseCase
+	introspectTokenUC       domain.IntrospectTokenUseCase
+	revokeTokenUC           domain.RevokeTokenUseCase
+	approveDeviceUC         domain.ApproveDeviceUseCase
+	lookupDeviceUC          domain.LookupDeviceUseCase
+	listSessionsUC          domain.ListSessionsUseCase
+	revokeSessionUC         domain.RevokeSessionUseCase
+	exchangeTokenUC         domain.ExchangeTokenUseCase
+	startQRLoginUC          domain.StartQRLoginUseCase
+	scanQRLoginUC           domain.ScanQRLoginUseCase
+	approveQRLoginUC        domain.ApproveQRLoginUseCase
+	pollQRLoginUC           domain.PollQRLoginUseCase
+	registerSharedDeviceUC  domain.RegisterSharedDeviceUseCase
+	revokeSharedDeviceUC    domain.RevokeSharedDeviceUseCase
+	setDevicePinUC          domain.SetDevicePinUseCase
+	listDeviceUsersUC       domain.ListDeviceUsersUseCase
+	pinLoginUC              domain.PinLoginUseCase
+
+	listIdentityProvidersUC  domain.ListIdentityProvidersUseCase
+	startFederatedLoginUC    domain.StartFederatedLoginUseCase
+	completeFederatedLoginUC domain.CompleteFederatedLoginUseCase
+	telegramLoginUC          domain.TelegramLoginUseCase
+	listLoginMethodsUC       domain.ListLoginMethodsUseCase
+	startLinkIdentityUC      domain.StartLinkIdentityUseCase
+	completeLinkIdentityUC   domain.CompleteLinkIdentityUseCase
+	linkTelegramUC           domain.LinkTelegramUseCase
+	unlinkIdentityUC         domain.UnlinkIdentityUseCase
+	listMergeRequestsUC      domain.ListMergeRequestsUseCase
+	mergeAccountsUC          domain.MergeAccountsUseCase
+	resolveUserIDUC          domain.ResolveUserIDUseCase
+	listUserEventsUC         domain.ListUserEventsUseCase
+	createGuestUC            domain.CreateGuestUseCase
+	guestLoginUC             domain.GuestLoginUseCase
+	upgradeGuestUC           domain.UpgradeGuestUseCase
+
+	listPendingRegistrationsUC domain.ListPendingRegistrationsUseCase
+	resolveRegistrationUC      domain.ResolveRegistrationUseCase
+	getDomainRulesUC           domain.GetDomainRulesUseCase
+	setDomainRulesUC           domain.SetDomainRulesUseCase
+
+	setTokenExchangePolicyUC    domain.SetTokenExchangePolicyUseCase
+	deleteTokenExchangePolicyUC domain.DeleteTokenExchangePolicyUseCase
 }
 
 func NewIdentityHandler(
@@ -27,6 +102,71 @@ func NewIdentityHandler(
 	validateUC domain.ValidateUseCase,
 	logoutUC domain.LogoutUseCase,
 	getMeUC domain.GetMeUseCase,
+	createInvitationUC domain.CreateInvitationUseCase,
+	listInvitationsUC domain.ListInvitationsUseCase,
+	revokeInvitationUC domain.RevokeInvitationUseCase,
+	acceptInvitationUC domain.AcceptInvitationUseCase,
+	switchBusinessUC domain.SwitchBusinessUseCase,
+	checkPermissionUC domain.CheckPermissionUseCase,
+	createCustomRoleUC domain.CreateCustomRoleUseCase,
+	updateCustomRoleUC domain.UpdateCustomRoleUseCase,
+	deleteCustomRoleUC domain.DeleteCustomRoleUseCase,
+	listCustomRolesUC domain.ListCustomRolesUseCase,
+	updateMembershipRoleUC domain.UpdateMembershipRoleUseCase,
+	setBusinessParentUC domain.SetBusinessParentUseCase,
+	listChildBusinessesUC domain.ListChildBusinessesUseCase,
+	startOwnershipTransferUC domain.StartOwnershipTransferUseCase,
+	acceptOwnershipTransferUC domain.AcceptOwnershipTransferUseCase,
+	cancelOwnershipTransferUC domain.CancelOwnershipTransferUseCase,
+	writeRelationsUC domain.WriteRelationsUseCase,
+	checkRelationUC domain.CheckRelationUseCase,
+	listObjectsUC domain.ListObjectsUseCase,
+	listSubjectsUC domain.ListSubjectsUseCase,
+	createPolicyConditionUC domain.CreatePolicyConditionUseCase,
+	listPolicyConditionsUC domain.ListPolicyConditionsUseCase,
+	deletePolicyConditionUC domain.DeletePolicyConditionUseCase,
+	queryDecisionLogUC domain.QueryDecisionLogUseCase,
+	registerPermissionsUC domain.RegisterPermissionsUseCase,
+	registerOAuthClientUC domain.RegisterOAuthClientUseCase,
+	createServiceAccountUC domain.CreateServiceAccountUseCase,
+	introspectTokenUC domain.IntrospectTokenUseCase,
+	revokeTokenUC domain.RevokeTokenUseCase,
+	approveDeviceUC domain.ApproveDeviceUseCase,
+	listSessionsUC domain.ListSessionsUseCase,
+	revokeSessionUC domain.RevokeSessionUseCase,
+	exchangeTokenUC domain.ExchangeTokenUseCase,
+	setTokenExchangePolicyUC domain.SetTokenExchangePolicyUseCase,
+	deleteTokenExchangePolicyUC domain.DeleteTokenExchangePolicyUseCase,
+	startQRLoginUC domain.StartQRLoginUseCase,
+	scanQRLoginUC domain.ScanQRLoginUseCase,
+	approveQRLoginUC domain.ApproveQRLoginUseCase,
+	pollQRLoginUC domain.PollQRLoginUseCase,
+	registerSharedDeviceUC domain.RegisterSharedDeviceUseCase,
+	revokeSharedDeviceUC domain.RevokeSharedDeviceUseCase,
+	setDevicePinUC domain.SetDevicePinUseCase,
+	listDeviceUsersUC domain.ListDeviceUsersUseCase,
+	pinLoginUC domain.PinLoginUseCase,
+	listIdentityProvidersUC domain.ListIdentityProvidersUseCase,
+	startFederatedLoginUC domain.StartFederatedLoginUseCase,
+	completeFederatedLoginUC domain.CompleteFederatedLoginUseCase,
+	telegramLoginUC domain.TelegramLoginUseCase,
+	listLoginMethodsUC domain.ListLoginMethodsUseCase,
+	startLinkIdentityUC domain.StartLinkIdentityUseCase,
+	completeLinkIdentityUC domain.CompleteLinkIdentityUseCase,
+	linkTelegramUC domain.LinkTelegramUseCase,
+	unlinkIdentityUC domain.UnlinkIdentityUseCase,
+	listMergeRequestsUC domain.ListMergeRequestsUseCase,
+	mergeAccountsUC domain.MergeAccountsUseCase,
+	resolveUserIDUC domain.ResolveUserIDUseCase,
+	listUserEventsUC domain.ListUserEventsUseCase,
+	createGuestUC domain.CreateGuestUseCase,
+	guestLoginUC domain.GuestLoginUseCase,
+	upgradeGuestUC domain.UpgradeGuestUseCase,
+	listPendingRegistrationsUC domain.ListPendingRegistrationsUseCase,
+	resolveRegistrationUC domain.ResolveRegistrationUseCase,
+	getDomainRulesUC domain.GetDomainRulesUseCase,
+	setDomainRulesUC domain.SetDomainRulesUseCase,
+	lookupDeviceUC domain.LookupDeviceUseCase,
 ) *IdentityHandler {
 	return &IdentityHandler{
 		loginUC:    loginUC,
@@ -35,6 +175,81 @@ func NewIdentityHandler(
 		validateUC: validateUC,
 		logoutUC:   logoutUC,
 		getMeUC:    getMeUC,
+
+		createInvitationUC: createInvitationUC,
+		listInvitationsUC:  listInvitationsUC,
+		revokeInvitationUC: revokeInvitationUC,
+		acceptInvitationUC: acceptInvitationUC,
+
+		switchBusinessUC:  switchBusinessUC,
+		checkPermissionUC: checkPermissionUC,
+
+		createCustomRoleUC:     createCustomRoleUC,
+		updateCustomRoleUC:     updateCustomRoleUC,
+		deleteCustomRoleUC:     deleteCustomRoleUC,
+		listCustomRolesUC:      listCustomRolesUC,
+		updateMembershipRoleUC: updateMembershipRoleUC,
+
+		setBusinessParentUC:   setBusinessParentUC,
+		listChildBusinessesUC: listChildBusinessesUC,
+
+		startOwnershipTransferUC:  startOwnershipTransferUC,
+		acceptOwnershipTransferUC: acceptOwnershipTransferUC,
+		cancelOwnershipTransferUC: cancelOwnershipTransferUC,
+
+		writeRelationsUC: writeRelationsUC,
+		checkRelationUC:  checkRelationUC,
+		listObjectsUC:    listObjectsUC,
+		listSubjectsUC:   listSubjectsUC,
+
+		createPolicyConditionUC: createPolicyConditionUC,
+		listPolicyConditionsUC:  listPolicyConditionsUC,
+		deletePolicyConditionUC: deletePolicyConditionUC,
+		queryDecisionLogUC:      queryDecisionLogUC,
+		registerPermissionsUC:   registerPermissionsUC,
+		registerOAuthClientUC:   registerOAuthClientUC,
+		createServiceAccountUC:  createServiceAccountUC,
+		introspectTokenUC:       introspectTokenUC,
+		revokeTokenUC:           revokeTokenUC,
+		approveDeviceUC:         approveDeviceUC,
+		listSessionsUC:          listSessionsUC,
+		revokeSessionUC:         revokeSessionUC,
+		exchangeTokenUC:         exchangeTokenUC,
+		startQRLoginUC:          startQRLoginUC,
+		scanQRLoginUC:           scanQRLoginUC,
+		approveQRLoginUC:        approveQRLoginUC,
+		pollQRLoginUC:           pollQRLoginUC,
+		registerSharedDeviceUC:  registerSharedDeviceUC,
+		revokeSharedDeviceUC:    revokeSharedDeviceUC,
+		setDevicePinUC:          setDevicePinUC,
+		listDeviceUsersUC:       listDeviceUsersUC,
+		pinLoginUC:              pinLoginUC,
+
+		listIdentityProvidersUC:  listIdentityProvidersUC,
+		startFederatedLoginUC:    startFederatedLoginUC,
+		completeFederatedLoginUC: completeFederatedLoginUC,
+		telegramLoginUC:          telegramLoginUC,
+		listLoginMethodsUC:       listLoginMethodsUC,
+		startLinkIdentityUC:      startLinkIdentityUC,
+		completeLinkIdentityUC:   completeLinkIdentityUC,
+		linkTelegramUC:           linkTelegramUC,
+		unlinkIdentityUC:         unlinkIdentityUC,
+		listMergeRequestsUC:      listMergeRequestsUC,
+		mergeAccountsUC:          mergeAccountsUC,
+		resolveUserIDUC:          resolveUserIDUC,
+		listUserEventsUC:         listUserEventsUC,
+		createGuestUC:            createGuestUC,
+		guestLoginUC:             guestLoginUC,
+		upgradeGuestUC:           upgradeGuestUC,
+
+		listPendingRegistrationsUC: listPendingRegistrationsUC,
+		resolveRegistrationUC:      resolveRegistrationUC,
+		getDomainRulesUC:           getDomainRulesUC,
+		setDomainRulesUC:           setDomainRulesUC,
+		lookupDeviceUC:             lookupDeviceUC,
+
+		setTokenExchangePolicyUC:    setTokenExchangePolicyUC,
+		deleteTokenExchangePolicyUC: deleteTokenExchangePolicyUC,
 	}
 }
 
@@ -76,6 +291,13 @@ func (h *IdentityHandler) Register(ctx context.Context, req *identityv1.Register
 	if err != nil {
 		return nil, handleError(err)
 	}
+	// accounts waiting for approval get no token until an admin activates them
+	if token == nil {
+		return &identityv1.RegisterResponse{
+			User:            mapUserToProto(user),
+			PendingApproval: true,
+		}, nil
+	}
 	return &identityv1.RegisterResponse{
 		User:      mapUserToProto(user),
 		AuthToken: mapTokenToProto(token),
@@ -124,9 +346,9 @@ func (h *IdentityHandler) Logout(ctx context.Context, req *identityv1.LogoutRequ
 }
 
 func (h *IdentityHandler) GetMe(ctx context.Context, _ *identityv1.GetMeRequest) (*identityv1.GetMeResponse, error) {
-	userID, ok := ctx.Value("user_id").(string)
-	if !ok || userID == "" {
-		return nil, status.Error(codes.Unauthenticated, "user not found in context")
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 	ctx, cancel := context.WithTimeout(ctx, 1*time.Second)
 	defer cancel()
@@ -137,3 +359,18 @@ func (h *IdentityHandler) GetMe(ctx context.Context, _ *identityv1.GetMeRequest)
 	}
 	return &identityv1.GetMeResponse{User: mapUserToProto(user)}, nil
 }
+
+func (h *IdentityHandler) SwitchBusiness(ctx context.Context, req *identityv1.SwitchBusinessRequest) (*identityv1.SwitchBusinessResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	token, err := h.switchBusinessUC.Execute(ctx, userID, req.BusinessId)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.SwitchBusinessResponse{AuthToken: mapTokenToProto(token)}, nil
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateGuest signs in a walk-in client without registration. The device
+// keeps device_secret to sign in again with GuestLogin.
+func (h *IdentityHandler) CreateGuest(ctx context.Context, req *identityv1.CreateGuestRequest) (*identityv1.CreateGuestResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	session, err := h.createGuestUC.Execute(ctx, domain.GuestRequest{
+		Phone:     req.Phone,
+		FirstName: req.FirstName,
+		IPAddress: clientInfoFromContext(ctx).IPAddress,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.CreateGuestResponse{
+		User:         mapUserToProto(session.User),
+		AuthToken:    mapTokenToProto(session.Token),
+		DeviceSecret: session.DeviceSecret,
+	}, nil
+}
+
+func (h *IdentityHandler) GuestLogin(ctx context.Context, req *identityv1.GuestLoginRequest) (*identityv1.LoginResponse, error) {
+	if req.DeviceSecret == "" {
+		return nil, status.Error(codes.InvalidArgument, "device secret required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	user, token, err := h.guestLoginUC.Execute(ctx, req.DeviceSecret)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.LoginResponse{
+		User:      mapUserToProto(user),
+		AuthToken: mapTokenToProto(token),
+	}, nil
+}
+
+func (h *IdentityHandler) UpgradeGuest(ctx context.Context, req *identityv1.UpgradeGuestRequest) (*identityv1.LoginResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Email == "" || req.Password == "" {
+		return nil, status.Error(codes.InvalidArgument, "email and password required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	user, token, err := h.upgradeGuestUC.Execute(ctx, userID, domain.UpgradeGuestRequest{
+		Email:     req.Email,
+		Phone:     req.Phone,
+		Password:  req.Password,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.LoginResponse{
+		User:      mapUserToProto(user),
+		AuthToken: mapTokenToProto(token),
+	}, nil
+}
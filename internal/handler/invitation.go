@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *IdentityHandler) CreateInvitation(ctx context.Context, req *identityv1.CreateInvitationRequest) (*identityv1.CreateInvitationResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.BusinessId == "" || req.Role == "" {
+		return nil, status.Error(codes.InvalidArgument, "business id and role required")
+	}
+	if req.Email == "" && req.Phone == "" {
+		return nil, status.Error(codes.InvalidArgument, "email or phone required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	inv, err := h.createInvitationUC.Execute(ctx, domain.CreateInvitationRequest{
+		InvitedBy:  userID,
+		BusinessID: req.BusinessId,
+		Email:      req.Email,
+		Phone:      req.Phone,
+		Role:       req.Role,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.CreateInvitationResponse{Invitation: mapInvitationToProto(inv)}, nil
+}
+
+func (h *IdentityHandler) ListInvitations(ctx context.Context, req *identityv1.ListInvitationsRequest) (*identityv1.ListInvitationsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.BusinessId == "" {
+		return nil, status.Error(codes.InvalidArgument, "business id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	invitations, err := h.listInvitationsUC.Execute(ctx, userID, req.BusinessId)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	resp := &identityv1.ListInvitationsResponse{}
+	for _, inv := range invitations {
+		resp.Invitations = append(resp.Invitations, mapInvitationToProto(inv))
+	}
+	return resp, nil
+}
+
+func (h *IdentityHandler) RevokeInvitation(ctx context.Context, req *identityv1.RevokeInvitationRequest) (*identityv1.RevokeInvitationResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.InvitationId == "" {
+		return nil, status.Error(codes.InvalidArgument, "invitation id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := h.revokeInvitationUC.Execute(ctx, userID, req.InvitationId); err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.RevokeInvitationResponse{}, nil
+}
+
+func (h *IdentityHandler) AcceptInvitation(ctx context.Context, req *identityv1.AcceptInvitationRequest) (*identityv1.AcceptInvitationResponse, error) {
+	if req.Token == "" || req.Password == "" {
+		return nil, status.Error(codes.InvalidArgument, "token and password required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	user, token, err := h.acceptInvitationUC.Execute(ctx, domain.AcceptInvitationRequest{
+		Token:     req.Token,
+		Email:     req.Email,
+		Password:  req.Password,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.AcceptInvitationResponse{
+		User:      mapUserToProto(user),
+		AuthToken: mapTokenToProto(token),
+	}, nil
+}
@@ -1,22 +1,30 @@
 package handler
 
 import (
-	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"time"
+
 	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func mapUserToProto(u *domain.User) *identityv1.User {
+	phone := u.Phone
+	if u.IsGuest {
+		// never verified, as phone_verified says
+		phone = u.ContactPhone
+	}
 	return &identityv1.User{
 		Id:            u.ID,
 		Email:         u.Email,
-		Phone:         u.Phone,
+		Phone:         phone,
 		FirstName:     u.FirstName,
 		LastName:      u.LastName,
 		Role:          u.Role,
 		IsActive:      u.IsActive,
 		EmailVerified: u.EmailVerified,
 		PhoneVerified: u.PhoneVerified,
+		IsGuest:       u.IsGuest,
 		CreatedAt:     timestamppb.New(u.CreatedAt),
 		UpdatedAt:     timestamppb.New(u.UpdatedAt),
 	}
@@ -29,4 +37,158 @@ func mapTokenToProto(t *domain.AuthToken) *identityv1.AuthToken {
 		ExpiredAt:    timestamppb.New(t.ExpiredAt),
 		TokenType:    t.TokenType,
 	}
-}
\ No newline at end of file
+}
+
+func mapInvitationToProto(inv *domain.Invitation) *identityv1.Invitation {
+	return &identityv1.Invitation{
+		Id:         inv.ID,
+		BusinessId: inv.BusinessID,
+		Email:      inv.Email,
+		Phone:      inv.Phone,
+		Role:       inv.Role,
+		Status:     inv.Status(time.Now()),
+		InvitedBy:  inv.InvitedBy,
+		ExpiresAt:  timestamppb.New(inv.ExpiresAt),
+		CreatedAt:  timestamppb.New(inv.CreatedAt),
+	}
+}
+
+func mapPermissionFromProto(p *identityv1.Permission) domain.Permission {
+	return domain.Permission{
+		Resource: p.Resource,
+		Action:   p.Action,
+	}
+}
+
+func mapDecisionToProto(d domain.PermissionDecision) *identityv1.PermissionDecision {
+	return &identityv1.PermissionDecision{
+		Permission: &identityv1.Permission{
+			Resource: d.Permission.Resource,
+			Action:   d.Permission.Action,
+		},
+		Allowed: d.Allowed,
+		Reason:  d.Reason,
+	}
+}
+
+func mapPermissionsFromProto(perms []*identityv1.Permission) []domain.Permission {
+	out := make([]domain.Permission, 0, len(perms))
+	for _, p := range perms {
+		out = append(out, mapPermissionFromProto(p))
+	}
+	return out
+}
+
+func mapPermissionsToProto(perms []domain.Permission) []*identityv1.Permission {
+	out := make([]*identityv1.Permission, 0, len(perms))
+	for _, p := range perms {
+		out = append(out, &identityv1.Permission{Resource: p.Resource, Action: p.Action})
+	}
+	return out
+}
+
+func mapCustomRoleToProto(r *domain.CustomRole) *identityv1.CustomRole {
+	role := &identityv1.CustomRole{
+		Id:          r.ID,
+		BusinessId:  r.BusinessID,
+		Name:        r.Name,
+		Description: r.Description,
+		CreatedAt:   timestamppb.New(r.CreatedAt),
+		UpdatedAt:   timestamppb.New(r.UpdatedAt),
+	}
+	for _, p := range r.Permissions {
+		role.Permissions = append(role.Permissions, &identityv1.Permission{Resource: p.Resource, Action: p.Action})
+	}
+	return role
+}
+
+func mapPolicyConditionToProto(c *domain.PolicyCondition) *identityv1.PolicyCondition {
+	pc := &identityv1.PolicyCondition{
+		Id:           c.ID,
+		BusinessId:   c.BusinessID,
+		RoleScope:    c.RoleScope,
+		Role:         c.Role,
+		CustomRoleId: c.CustomRoleID,
+		Expression:   c.Expression,
+		Mode:         c.Mode,
+		Description:  c.Description,
+		CreatedBy:    c.CreatedBy,
+		CreatedAt:    timestamppb.New(c.CreatedAt),
+	}
+	if c.Resource != "" {
+		pc.Permission = &identityv1.Permission{Resource: c.Resource, Action: c.Action}
+	}
+	return pc
+}
+
+func mapDecisionRecordToProto(r *domain.DecisionRecord) *identityv1.DecisionLogEntry {
+	return &identityv1.DecisionLogEntry{
+		Id:                 r.ID,
+		UserId:             r.UserID,
+		BusinessId:         r.BusinessID,
+		Permission:         &identityv1.Permission{Resource: r.Permission.Resource, Action: r.Permission.Action},
+		Allowed:            r.Allowed,
+		Reason:             r.Reason,
+		MatchedBy:          r.MatchedBy,
+		ClientIp:           r.ClientIP,
+		ResourceAttributes: r.ResourceAttributes,
+		LatencyUs:          r.Latency.Microseconds(),
+		CreatedAt:          timestamppb.New(r.CreatedAt),
+	}
+}
+
+func mapMembershipToProto(m *domain.Membership) *identityv1.Membership {
+	return &identityv1.Membership{
+		Id:           m.ID,
+		UserId:       m.UserID,
+		BusinessId:   m.BusinessID,
+		Role:         m.RoleName(),
+		CustomRoleId: m.CustomRoleID,
+		IsActive:     m.IsActive,
+		CreatedAt:    timestamppb.New(m.CreatedAt),
+		UpdatedAt:    timestamppb.New(m.UpdatedAt),
+	}
+}
+
+func mapOwnershipTransferToProto(t *domain.OwnershipTransfer) *identityv1.OwnershipTransfer {
+	return &identityv1.OwnershipTransfer{
+		Id:         t.ID,
+		BusinessId: t.BusinessID,
+		FromUserId: t.FromUserID,
+		ToUserId:   t.ToUserID,
+		Status:     t.EffectiveStatus(time.Now()),
+		ExpiresAt:  timestamppb.New(t.ExpiresAt),
+		CreatedAt:  timestamppb.New(t.CreatedAt),
+	}
+}
+
+func mapTuplesFromProto(tuples []*identityv1.RelationTuple) ([]domain.RelationTuple, error) {
+	out := make([]domain.RelationTuple, 0, len(tuples))
+	for _, t := range tuples {
+		object, err := domain.ParseObjectRef(t.Object)
+		if err != nil {
+			return nil, err
+		}
+		subject, err := domain.ParseSubjectRef(t.Subject)
+		if err != nil {
+			return nil, err
+		}
+		if t.Relation == "" {
+			return nil, domain.ErrInvalidTuple
+		}
+		out = append(out, domain.RelationTuple{Object: object, Relation: t.Relation, Subject: subject})
+	}
+	return out, nil
+}
+
+func mapSessionToProto(s *domain.Session) *identityv1.Session {
+	return &identityv1.Session{
+		Id:         s.ID,
+		ClientId:   s.ClientID,
+		ClientName: s.ClientName,
+		BusinessId: s.BusinessID,
+		Scope:      s.Scope,
+		CreatedAt:  timestamppb.New(s.CreatedAt),
+		ExpiresAt:  timestamppb.New(s.ExpiresAt),
+	}
+}
@@ -0,0 +1,449 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+	"github.com/sirupsen/logrus"
+)
+
+// OAuthHandler serves the OAuth 2.0 and OpenID Connect endpoints over HTTP.
+// The authorization endpoint renders a sign-in and consent page; there is no
+// browser session, so the user signs in on every authorization.
+type OAuthHandler struct {
+	validateAuthorizationUC domain.ValidateAuthorizationUseCase
+	authorizeUC             domain.AuthorizeUseCase
+	tokenUC                 domain.OAuthTokenUseCase
+	introspectUC            domain.IntrospectTokenUseCase
+	revokeUC                domain.RevokeTokenUseCase
+	startDeviceUC           domain.StartDeviceAuthorizationUseCase
+	approveDeviceUC         domain.ApproveDeviceUseCase
+	userInfoUC              domain.UserInfoUseCase
+	idTokens                *infrastructure.IDTokenSigner
+	issuer                  string
+	lookupDeviceUC          domain.LookupDeviceUseCase
+	signInLimiter           domain.RateLimiter
+}
+
+func NewOAuthHandler(
+	validateAuthorizationUC domain.ValidateAuthorizationUseCase,
+	authorizeUC domain.AuthorizeUseCase,
+	tokenUC domain.OAuthTokenUseCase,
+	introspectUC domain.IntrospectTokenUseCase,
+	revokeUC domain.RevokeTokenUseCase,
+	startDeviceUC domain.StartDeviceAuthorizationUseCase,
+	approveDeviceUC domain.ApproveDeviceUseCase,
+	userInfoUC domain.UserInfoUseCase,
+	idTokens *infrastructure.IDTokenSigner,
+	issuer string,
+	lookupDeviceUC domain.LookupDeviceUseCase,
+	signInLimiter domain.RateLimiter,
+) *OAuthHandler {
+	return &OAuthHandler{
+		validateAuthorizationUC: validateAuthorizationUC,
+		authorizeUC:             authorizeUC,
+		tokenUC:                 tokenUC,
+		introspectUC:            introspectUC,
+		revokeUC:                revokeUC,
+		startDeviceUC:           startDeviceUC,
+		approveDeviceUC:         approveDeviceUC,
+		userInfoUC:              userInfoUC,
+		idTokens:                idTokens,
+		issuer:                  issuer,
+		lookupDeviceUC:          lookupDeviceUC,
+		signInLimiter:           signInLimiter,
+	}
+}
+
+func (h *OAuthHandler) Routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /oauth/authorize", h.authorizePage)
+	mux.HandleFunc("POST /oauth/authorize", h.authorize)
+	mux.HandleFunc("POST /oauth/token", h.token)
+	mux.HandleFunc("POST /oauth/introspect", h.introspect)
+	mux.HandleFunc("POST /oauth/revoke", h.revoke)
+	mux.HandleFunc("POST /oauth/device_authorization", h.deviceAuthorization)
+	mux.HandleFunc("GET /device", h.devicePage)
+	mux.HandleFunc("POST /device", h.approveDevice)
+	mux.HandleFunc("GET /oauth/userinfo", h.userInfo)
+	mux.HandleFunc("POST /oauth/userinfo", h.userInfo)
+	mux.HandleFunc("GET /oauth/jwks", h.jwks)
+	mux.HandleFunc("GET /.well-known/openid-configuration", h.discovery)
+	return mux
+}
+
+var authorizeTemplate = template.Must(template.New("authorize").Parse(`<!doctype html>
+<html>
+<head><meta charset="utf-8"><title>Sign in to {{.Client.Name}}</title></head>
+<body>
+<h1>{{.Client.Name}} wants to access your My Place account</h1>
+{{if .Scopes}}<p>It asks for:</p>
+<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="post" action="/oauth/authorize">
+{{range $k, $v := .Params}}<input type="hidden" name="{{$k}}" value="{{$v}}">
+{{end}}<label>Email <input type="email" name="email" autocomplete="username" required></label>
+<label>Password <input type="password" name="password" autocomplete="current-password" required></label>
+<button type="submit" name="decision" value="approve">Allow</button>
+<button type="submit" name="decision" value="deny" formnovalidate>Deny</button>
+</form>
+</body>
+</html>
+`))
+
+var authorizeParams = []string{"client_id", "redirect_uri", "response_type", "scope", "state", "code_challenge", "code_challenge_method", "nonce"}
+
+func authorizationRequestFromForm(form url.Values) domain.AuthorizationRequest {
+	return domain.AuthorizationRequest{
+		ClientID:            form.Get("client_id"),
+		RedirectURI:         form.Get("redirect_uri"),
+		ResponseType:        form.Get("response_type"),
+		Scope:               form.Get("scope"),
+		State:               form.Get("state"),
+		CodeChallenge:       form.Get("code_challenge"),
+		CodeChallengeMethod: form.Get("code_challenge_method"),
+		Nonce:               form.Get("nonce"),
+	}
+}
+
+func (h *OAuthHandler) authorizePage(w http.ResponseWriter, r *http.Request) {
+	req := authorizationRequestFromForm(r.URL.Query())
+	client, err := h.validateAuthorizationUC.Execute(r.Context(), req)
+	if err != nil {
+		h.authorizationError(w, r, req, err)
+		return
+	}
+	h.renderAuthorize(w, http.StatusOK, client, req, r.URL.Query(), "")
+}
+
+func (h *OAuthHandler) authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	req := authorizationRequestFromForm(r.PostForm)
+	approve := r.PostForm.Get("decision") == "approve"
+
+	if !h.allowSignIn(r, approve, r.PostForm.Get("email")) {
+		client, err := h.validateAuthorizationUC.Execute(r.Context(), req)
+		if err != nil {
+			h.authorizationError(w, r, req, err)
+			return
+		}
+		h.renderAuthorize(w, http.StatusTooManyRequests, client, req, r.PostForm, "Too many attempts. Try again later.")
+		return
+	}
+	if !approve {
+		// only redirect the denial once the client and redirect uri are known good
+		if _, err := h.validateAuthorizationUC.Execute(r.Context(), req); err != nil {
+			h.authorizationError(w, r, req, err)
+			return
+		}
+		redirectWithError(w, r, req, domain.NewOAuthError(domain.OAuthAccessDenied, "the user denied the request"))
+		return
+	}
+
+	code, err := h.authorizeUC.Execute(r.Context(), req, r.PostForm.Get("email"), r.PostForm.Get("password"))
+	if errors.Is(err, domain.ErrInvalidCredentials) || errors.Is(err, domain.ErrUserNotActive) {
+		client, verr := h.validateAuthorizationUC.Execute(r.Context(), req)
+		if verr != nil {
+			h.authorizationError(w, r, req, verr)
+			return
+		}
+		h.renderAuthorize(w, http.StatusUnauthorized, client, req, r.PostForm, "Wrong email or password.")
+		return
+	}
+	if err != nil {
+		h.authorizationError(w, r, req, err)
+		return
+	}
+
+	redirect, _ := url.Parse(req.RedirectURI)
+	q := redirect.Query()
+	q.Set("code", code)
+	if req.State != "" {
+		q.Set("state", req.State)
+	}
+	redirect.RawQuery = q.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// allowSignIn rate limits posts to the sign-in pages by address and, when
+// they carry credentials, by email, so neither page can be used to guess
+// passwords.
+func (h *OAuthHandler) allowSignIn(r *http.Request, withCredentials bool, email string) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !h.signInLimiter.Allow("ip:" + host) {
+		return false
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	return !withCredentials || h.signInLimiter.Allow("email:"+email)
+}
+
+func (h *OAuthHandler) renderAuthorize(w http.ResponseWriter, code int, client *domain.OAuthClient,
+	req domain.AuthorizationRequest, form url.Values, errMsg string) {
+	params := make(map[string]string, len(authorizeParams))
+	for _, k := range authorizeParams {
+		if v := form.Get(k); v != "" {
+			params[k] = v
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "frame-ancestors 'none'")
+	w.WriteHeader(code)
+	err := authorizeTemplate.Execute(w, map[string]any{
+		"Client": client,
+		"Scopes": domain.ParseScope(req.Scope),
+		"Params": params,
+		"Error":  errMsg,
+	})
+	if err != nil {
+		logrus.Errorf("oauth: render authorize page: %v", err)
+	}
+}
+
+// authorizationError redirects OAuth errors back to the client. Problems with
+// the client or redirect uri itself are shown to the user instead, so the
+// endpoint cannot be used as an open redirector.
+func (h *OAuthHandler) authorizationError(w http.ResponseWriter, r *http.Request, req domain.AuthorizationRequest, err error) {
+	var oerr *domain.OAuthError
+	switch {
+	case errors.Is(err, domain.ErrOAuthClientNotFound):
+		http.Error(w, "unknown client", http.StatusBadRequest)
+	case errors.Is(err, domain.ErrInvalidRedirectURI):
+		http.Error(w, "redirect uri is not registered for this client", http.StatusBadRequest)
+	case errors.As(err, &oerr):
+		redirectWithError(w, r, req, oerr)
+	default:
+		logrus.Errorf("oauth: authorize: %v", err)
+		redirectWithError(w, r, req, domain.NewOAuthError(domain.OAuthServerError, ""))
+	}
+}
+
+func redirectWithError(w http.ResponseWriter, r *http.Request, req domain.AuthorizationRequest, oerr *domain.OAuthError) {
+	redirect, _ := url.Parse(req.RedirectURI)
+	q := redirect.Query()
+	q.Set("error", oerr.Code)
+	if oerr.Description != "" {
+		q.Set("error_description", oerr.Description)
+	}
+	if req.State != "" {
+		q.Set("state", req.State)
+	}
+	redirect.RawQuery = q.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (h *OAuthHandler) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, domain.NewOAuthError(domain.OAuthInvalidRequest, "invalid form"))
+		return
+	}
+	req := domain.TokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+		Scope:        r.PostForm.Get("scope"),
+		DeviceCode:   r.PostForm.Get("device_code"),
+	}
+	req.ClientID, req.ClientSecret = clientCredentials(r)
+
+	resp, err := h.tokenUC.Execute(r.Context(), req)
+	if err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+	body := map[string]any{
+		"access_token": resp.AccessToken,
+		"token_type":   resp.TokenType,
+		"expires_in":   resp.ExpiresIn,
+	}
+	if resp.RefreshToken != "" {
+		body["refresh_token"] = resp.RefreshToken
+	}
+	if resp.Scope != "" {
+		body["scope"] = resp.Scope
+	}
+	if resp.IDToken != "" {
+		body["id_token"] = resp.IDToken
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// introspect implements RFC 7662. Inactive tokens carry no other member.
+func (h *OAuthHandler) introspect(w http.ResponseWriter, r *http.Request) {
+	req, ok := clientTokenRequest(w, r)
+	if !ok {
+		return
+	}
+	result, err := h.introspectUC.Execute(r.Context(), req)
+	if err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+	body := map[string]any{"active": result.Active}
+	if result.Active {
+		body["token_type"] = result.TokenType
+		body["sub"] = result.Subject
+		body["exp"] = result.ExpiresAt.Unix()
+		body["iat"] = result.IssuedAt.Unix()
+		if result.Scope != "" {
+			body["scope"] = result.Scope
+		}
+		if result.ClientID != "" {
+			body["client_id"] = result.ClientID
+		}
+		if result.BusinessID != "" {
+			body["business_id"] = result.BusinessID
+		}
+		if result.Audience != "" {
+			body["aud"] = result.Audience
+		}
+		if result.ActorID != "" {
+			body["act"] = map[string]any{"sub": result.ActorID}
+		}
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// revoke implements RFC 7009; success has an empty body.
+func (h *OAuthHandler) revoke(w http.ResponseWriter, r *http.Request) {
+	req, ok := clientTokenRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := h.revokeUC.Execute(r.Context(), req); err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+func clientTokenRequest(w http.ResponseWriter, r *http.Request) (domain.ClientTokenRequest, bool) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, domain.NewOAuthError(domain.OAuthInvalidRequest, "invalid form"))
+		return domain.ClientTokenRequest{}, false
+	}
+	req := domain.ClientTokenRequest{
+		Token:         r.PostForm.Get("token"),
+		TokenTypeHint: r.PostForm.Get("token_type_hint"),
+	}
+	req.ClientID, req.ClientSecret = clientCredentials(r)
+	return req, true
+}
+
+// clientCredentials reads client_secret_basic, falling back to
+// client_secret_post.
+func clientCredentials(r *http.Request) (string, string) {
+	if id, secret, ok := r.BasicAuth(); ok {
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+		return id, secret
+	}
+	return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+}
+
+func (h *OAuthHandler) userInfo(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		// RFC 6750 section 3.1: no error code when credentials are missing
+		w.Header().Set("WWW-Authenticate", `Bearer realm="oauth"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	claims, err := h.userInfoUC.Execute(r.Context(), token)
+	if err != nil {
+		writeBearerError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, claims)
+}
+
+func (h *OAuthHandler) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.idTokens.JWKS())
+}
+
+func (h *OAuthHandler) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                h.issuer,
+		"authorization_endpoint":                h.issuer + "/oauth/authorize",
+		"token_endpoint":                        h.issuer + "/oauth/token",
+		"userinfo_endpoint":                     h.issuer + "/oauth/userinfo",
+		"introspection_endpoint":                h.issuer + "/oauth/introspect",
+		"revocation_endpoint":                   h.issuer + "/oauth/revoke",
+		"device_authorization_endpoint":         h.issuer + "/oauth/device_authorization",
+		"jwks_uri":                              h.issuer + "/oauth/jwks",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{domain.GrantTypeAuthorizationCode, domain.GrantTypeRefreshToken, domain.GrantTypeClientCredentials, domain.GrantTypeDeviceCode},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"scopes_supported":                      []string{domain.ScopeOpenID, domain.ScopeProfile, domain.ScopeEmail, domain.ScopePhone},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"claims_supported": []string{"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce", "at_hash",
+			"given_name", "family_name", "email", "email_verified", "phone_number", "phone_number_verified"},
+	})
+}
+
+func writeOAuthError(w http.ResponseWriter, err error) {
+	var oerr *domain.OAuthError
+	if !errors.As(err, &oerr) {
+		logrus.Errorf("oauth: token: %v", err)
+		oerr = domain.NewOAuthError(domain.OAuthServerError, "")
+	}
+	code := http.StatusBadRequest
+	switch oerr.Code {
+	case domain.OAuthInvalidClient:
+		code = http.StatusUnauthorized
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	case domain.OAuthServerError:
+		code = http.StatusInternalServerError
+	}
+	body := map[string]string{"error": oerr.Code}
+	if oerr.Description != "" {
+		body["error_description"] = oerr.Description
+	}
+	writeJSON(w, code, body)
+}
+
+func writeBearerError(w http.ResponseWriter, err error) {
+	var oerr *domain.OAuthError
+	if !errors.As(err, &oerr) {
+		logrus.Errorf("oauth: userinfo: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": domain.OAuthServerError})
+		return
+	}
+	code := http.StatusUnauthorized
+	if oerr.Code == domain.OAuthInsufficientScope {
+		code = http.StatusForbidden
+	}
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="oauth", error=%q, error_description=%q`,
+		oerr.Code, oerr.Description))
+	writeJSON(w, code, map[string]string{"error": oerr.Code, "error_description": oerr.Description})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logrus.Errorf("oauth: write response: %v", err)
+	}
+}
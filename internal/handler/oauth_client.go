@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *IdentityHandler) RegisterOAuthClient(ctx context.Context, req *identityv1.RegisterOAuthClientRequest) (*identityv1.RegisterOAuthClientResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name == "" || len(req.RedirectUris) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "name and redirect uris required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	client, secret, err := h.registerOAuthClientUC.Execute(ctx, userID, &domain.OAuthClient{
+		Name:         req.Name,
+		RedirectURIs: req.RedirectUris,
+		Scopes:       req.Scopes,
+	}, req.Confidential)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.RegisterOAuthClientResponse{
+		ClientId:     client.ID,
+		ClientSecret: secret,
+		CreatedAt:    timestamppb.New(client.CreatedAt),
+	}, nil
+}
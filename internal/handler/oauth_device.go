@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// deviceAuthorization starts a device authorization grant (RFC 8628
+// section 3.1). Kiosks are usually public clients and send only client_id.
+func (h *OAuthHandler) deviceAuthorization(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, domain.NewOAuthError(domain.OAuthInvalidRequest, "invalid form"))
+		return
+	}
+	clientID, secret := clientCredentials(r)
+	resp, err := h.startDeviceUC.Execute(r.Context(), clientID, secret, r.PostForm.Get("scope"))
+	if err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"device_code":               resp.DeviceCode,
+		"user_code":                 resp.UserCode,
+		"verification_uri":          resp.VerificationURI,
+		"verification_uri_complete": resp.VerificationURIComplete,
+		"expires_in":                resp.ExpiresIn,
+		"interval":                  resp.Interval,
+	})
+}
+
+var deviceTemplate = template.Must(template.New("device").Parse(`<!doctype html>
+<html>
+<head><meta charset="utf-8"><title>Connect a device</title></head>
+<body>
+{{if .Done}}<h1>{{if .Approved}}Device connected{{else}}Request denied{{end}}</h1>
+<p>You can return to {{.ClientName}}.</p>
+{{else if .Device}}<h1>{{.Device.ClientName}} wants to connect to your My Place account</h1>
+{{if .Scopes}}<p>It asks for:</p>
+<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+<p>Only allow it if this is the device in front of you.</p>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="post" action="/device">
+<input type="hidden" name="user_code" value="{{.UserCode}}">
+<input type="hidden" name="client_id" value="{{.Device.ClientID}}">
+<label>Email <input type="email" name="email" autocomplete="username" required></label>
+<label>Password <input type="password" name="password" autocomplete="current-password" required></label>
+<button type="submit" name="decision" value="approve">Allow</button>
+<button type="submit" name="decision" value="deny">Deny</button>
+</form>
+{{else}}<h1>Connect a device to your My Place account</h1>
+<p>Enter the code shown on the screen.</p>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="post" action="/device">
+<label>Code <input name="user_code" value="{{.UserCode}}" autocomplete="off" autocapitalize="characters" required></label>
+<button type="submit">Continue</button>
+</form>
+{{end}}</body>
+</html>
+`))
+
+// devicePage is the verification_uri. Users who are signed in to the app
+// approve from there instead, through LookupDevice and ApproveDevice.
+func (h *OAuthHandler) devicePage(w http.ResponseWriter, r *http.Request) {
+	renderDevice(w, http.StatusOK, map[string]any{"UserCode": r.URL.Query().Get("user_code")})
+}
+
+// approveDevice handles both steps of the page: a code alone shows the
+// client and the scopes it asks for; the second post carries the client
+// that was shown and the user's credentials. Posts are rate limited by
+// address, and sign-in attempts also by email.
+func (h *OAuthHandler) approveDevice(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	userCode := r.PostForm.Get("user_code")
+	clientID := r.PostForm.Get("client_id")
+	email := strings.ToLower(strings.TrimSpace(r.PostForm.Get("email")))
+
+	if !h.allowSignIn(r, clientID != "", email) {
+		renderDevice(w, http.StatusTooManyRequests, map[string]any{"UserCode": userCode,
+			"Error": "Too many attempts. Try again later."})
+		return
+	}
+
+	if clientID == "" {
+		device, err := h.lookupDeviceUC.Execute(r.Context(), userCode)
+		switch {
+		case errors.Is(err, domain.ErrDeviceCodeNotFound):
+			renderDevice(w, http.StatusBadRequest, map[string]any{"UserCode": userCode, "Error": "This code is invalid or has expired."})
+		case err != nil:
+			logrus.Errorf("oauth: look up device: %v", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		default:
+			renderDeviceConfirm(w, http.StatusOK, userCode, device, "")
+		}
+		return
+	}
+
+	device, err := h.approveDeviceUC.Execute(r.Context(), domain.DeviceApproval{
+		Email:    email,
+		Password: r.PostForm.Get("password"),
+		UserCode: userCode,
+		ClientID: clientID,
+		Approve:  r.PostForm.Get("decision") == "approve",
+	})
+	switch {
+	case errors.Is(err, domain.ErrInvalidCredentials), errors.Is(err, domain.ErrUserNotActive):
+		shown, err := h.lookupDeviceUC.Execute(r.Context(), userCode)
+		if err != nil || shown.ClientID != clientID {
+			renderDevice(w, http.StatusBadRequest, map[string]any{"UserCode": userCode, "Error": "This code is invalid or has expired."})
+			return
+		}
+		renderDeviceConfirm(w, http.StatusUnauthorized, userCode, shown, "Wrong email or password.")
+	case errors.Is(err, domain.ErrDeviceCodeNotFound):
+		renderDevice(w, http.StatusBadRequest, map[string]any{"UserCode": userCode, "Error": "This code is invalid or has expired."})
+	case err != nil:
+		logrus.Errorf("oauth: approve device: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	default:
+		renderDevice(w, http.StatusOK, map[string]any{
+			"Done":       true,
+			"Approved":   device.Status == domain.DeviceStatusApproved,
+			"ClientName": device.ClientName,
+		})
+	}
+}
+
+func renderDeviceConfirm(w http.ResponseWriter, code int, userCode string, device *domain.DeviceAuthorization, msg string) {
+	renderDevice(w, code, map[string]any{
+		"UserCode": userCode,
+		"Device":   device,
+		"Scopes":   domain.ParseScope(device.Scope),
+		"Error":    msg,
+	})
+}
+
+func renderDevice(w http.ResponseWriter, code int, data map[string]any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "frame-ancestors 'none'")
+	w.WriteHeader(code)
+	if err := deviceTemplate.Execute(w, data); err != nil {
+		logrus.Errorf("oauth: render device page: %v", err)
+	}
+}
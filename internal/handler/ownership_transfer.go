@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *IdentityHandler) StartOwnershipTransfer(ctx context.Context, req *identityv1.StartOwnershipTransferRequest) (*identityv1.StartOwnershipTransferResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.BusinessId == "" || req.RecipientEmail == "" {
+		return nil, status.Error(codes.InvalidArgument, "business id and recipient email required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	t, err := h.startOwnershipTransferUC.Execute(ctx, domain.StartOwnershipTransferRequest{
+		CallerID:         userID,
+		Password:         req.Password,
+		ConfirmationCode: req.ConfirmationCode,
+		BusinessID:       req.BusinessId,
+		RecipientEmail:   req.RecipientEmail,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.StartOwnershipTransferResponse{Transfer: mapOwnershipTransferToProto(t)}, nil
+}
+
+func (h *IdentityHandler) AcceptOwnershipTransfer(ctx context.Context, req *identityv1.AcceptOwnershipTransferRequest) (*identityv1.AcceptOwnershipTransferResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.TransferId == "" {
+		return nil, status.Error(codes.InvalidArgument, "transfer id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	t, err := h.acceptOwnershipTransferUC.Execute(ctx, userID, req.TransferId)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.AcceptOwnershipTransferResponse{Transfer: mapOwnershipTransferToProto(t)}, nil
+}
+
+func (h *IdentityHandler) CancelOwnershipTransfer(ctx context.Context, req *identityv1.CancelOwnershipTransferRequest) (*identityv1.CancelOwnershipTransferResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.TransferId == "" {
+		return nil, status.Error(codes.InvalidArgument, "transfer id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := h.cancelOwnershipTransferUC.Execute(ctx, userID, req.TransferId); err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.CancelOwnershipTransferResponse{}, nil
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *IdentityHandler) CheckPermission(ctx context.Context, req *identityv1.CheckPermissionRequest) (*identityv1.CheckPermissionResponse, error) {
+	if req.SubjectToken == "" && req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "subject token or user id required")
+	}
+	if req.Permission == nil || req.Permission.Resource == "" || req.Permission.Action == "" {
+		return nil, status.Error(codes.InvalidArgument, "permission resource and action required")
+	}
+	callerID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 1*time.Second)
+	defer cancel()
+
+	decisions, err := h.checkPermissionUC.Execute(ctx, domain.PermissionCheckRequest{
+		SubjectToken: req.SubjectToken,
+		UserID:       req.UserId,
+		BusinessID:   req.BusinessId,
+		Permissions:  []domain.Permission{mapPermissionFromProto(req.Permission)},
+
+		ClientIP:           req.ClientIp,
+		ResourceAttributes: req.ResourceAttributes,
+
+		CallerID:         callerID,
+		CallerService:    isServiceAccount(ctx),
+		CallerBusinessID: businessFromContext(ctx),
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.CheckPermissionResponse{
+		Allowed: decisions[0].Allowed,
+		Reason:  decisions[0].Reason,
+	}, nil
+}
+
+func (h *IdentityHandler) BatchCheckPermission(ctx context.Context, req *identityv1.BatchCheckPermissionRequest) (*identityv1.BatchCheckPermissionResponse, error) {
+	if req.SubjectToken == "" && req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "subject token or user id required")
+	}
+	if len(req.Permissions) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "permissions required")
+	}
+	perms := make([]domain.Permission, 0, len(req.Permissions))
+	for _, p := range req.Permissions {
+		if p.Resource == "" || p.Action == "" {
+			return nil, status.Error(codes.InvalidArgument, "permission resource and action required")
+		}
+		perms = append(perms, mapPermissionFromProto(p))
+	}
+	callerID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	decisions, err := h.checkPermissionUC.Execute(ctx, domain.PermissionCheckRequest{
+		SubjectToken: req.SubjectToken,
+		UserID:       req.UserId,
+		BusinessID:   req.BusinessId,
+		Permissions:  perms,
+
+		ClientIP:           req.ClientIp,
+		ResourceAttributes: req.ResourceAttributes,
+
+		CallerID:         callerID,
+		CallerService:    isServiceAccount(ctx),
+		CallerBusinessID: businessFromContext(ctx),
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	resp := &identityv1.BatchCheckPermissionResponse{}
+	for _, d := range decisions {
+		resp.Decisions = append(resp.Decisions, mapDecisionToProto(d))
+	}
+	return resp, nil
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *IdentityHandler) RegisterPermissions(ctx context.Context, req *identityv1.RegisterPermissionsRequest) (*identityv1.RegisterPermissionsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Namespace == "" || req.Version <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "namespace and positive version required")
+	}
+	defs := make([]domain.PermissionDefinition, 0, len(req.Permissions))
+	for _, d := range req.Permissions {
+		if d.Permission == nil || d.Permission.Resource == "" || d.Permission.Action == "" {
+			return nil, status.Error(codes.InvalidArgument, "permission resource and action required")
+		}
+		defs = append(defs, domain.PermissionDefinition{
+			Permission:   mapPermissionFromProto(d.Permission),
+			Description:  d.Description,
+			DefaultRoles: d.DefaultRoles,
+			Deprecated:   d.Deprecated,
+		})
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := h.registerPermissionsUC.Execute(ctx, domain.RegisterPermissionsRequest{
+		CallerID:    userID,
+		Namespace:   req.Namespace,
+		Version:     int(req.Version),
+		Permissions: defs,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.RegisterPermissionsResponse{
+		Version:    int32(result.Version),
+		Applied:    result.Applied,
+		Deprecated: mapPermissionsToProto(result.Deprecated),
+	}, nil
+}
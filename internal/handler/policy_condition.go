@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *IdentityHandler) CreatePolicyCondition(ctx context.Context, req *identityv1.CreatePolicyConditionRequest) (*identityv1.CreatePolicyConditionResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Expression == "" {
+		return nil, status.Error(codes.InvalidArgument, "expression required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	c := &domain.PolicyCondition{
+		BusinessID:   req.BusinessId,
+		RoleScope:    req.RoleScope,
+		Role:         req.Role,
+		CustomRoleID: req.CustomRoleId,
+		Expression:   req.Expression,
+		Mode:         req.Mode,
+		Description:  req.Description,
+	}
+	if req.Permission != nil {
+		c.Resource, c.Action = req.Permission.Resource, req.Permission.Action
+	}
+	c, err = h.createPolicyConditionUC.Execute(ctx, userID, c)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.CreatePolicyConditionResponse{Condition: mapPolicyConditionToProto(c)}, nil
+}
+
+func (h *IdentityHandler) ListPolicyConditions(ctx context.Context, req *identityv1.ListPolicyConditionsRequest) (*identityv1.ListPolicyConditionsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	conditions, err := h.listPolicyConditionsUC.Execute(ctx, userID, req.BusinessId)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	resp := &identityv1.ListPolicyConditionsResponse{}
+	for _, c := range conditions {
+		resp.Conditions = append(resp.Conditions, mapPolicyConditionToProto(c))
+	}
+	return resp, nil
+}
+
+func (h *IdentityHandler) DeletePolicyCondition(ctx context.Context, req *identityv1.DeletePolicyConditionRequest) (*identityv1.DeletePolicyConditionResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.ConditionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "condition id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := h.deletePolicyConditionUC.Execute(ctx, userID, req.ConditionId); err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.DeletePolicyConditionResponse{}, nil
+}
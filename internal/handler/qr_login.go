@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// StartQRLogin is called by the web client. It shows session_id as a QR code
+// and keeps poll_token to itself.
+func (h *IdentityHandler) StartQRLogin(ctx context.Context, req *identityv1.StartQRLoginRequest) (*identityv1.StartQRLoginResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	start, err := h.startQRLoginUC.Execute(ctx, clientInfoFromContext(ctx))
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.StartQRLoginResponse{
+		SessionId: start.SessionID,
+		PollToken: start.PollSecret,
+		ExpiresAt: timestamppb.New(start.ExpiresAt),
+	}, nil
+}
+
+// ScanQRLogin is called by the phone after scanning. The response tells the
+// user where the sign-in request comes from, before they approve it.
+func (h *IdentityHandler) ScanQRLogin(ctx context.Context, req *identityv1.ScanQRLoginRequest) (*identityv1.ScanQRLoginResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	session, err := h.scanQRLoginUC.Execute(ctx, userID, req.SessionId)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.ScanQRLoginResponse{
+		IpAddress: session.Client.IPAddress,
+		UserAgent: session.Client.UserAgent,
+		Location:  session.Client.Location,
+		CreatedAt: timestamppb.New(session.CreatedAt),
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+	}, nil
+}
+
+func (h *IdentityHandler) ApproveQRLogin(ctx context.Context, req *identityv1.ApproveQRLoginRequest) (*identityv1.ApproveQRLoginResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := h.approveQRLoginUC.Execute(ctx, userID, req.SessionId, req.Approve); err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.ApproveQRLoginResponse{}, nil
+}
+
+// PollQRLogin is a long poll: it returns as soon as the status differs from
+// last_status, or with the unchanged status after about 25 seconds. Tokens
+// are returned once, with status "consumed".
+func (h *IdentityHandler) PollQRLogin(ctx context.Context, req *identityv1.PollQRLoginRequest) (*identityv1.PollQRLoginResponse, error) {
+	if req.SessionId == "" || req.PollToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "session id and poll token required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	poll, err := h.pollQRLoginUC.Execute(ctx, clientInfoFromContext(ctx), req.SessionId, req.PollToken, req.LastStatus)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	resp := &identityv1.PollQRLoginResponse{Status: poll.Status}
+	if poll.Token != nil {
+		resp.User = mapUserToProto(poll.User)
+		resp.AuthToken = mapTokenToProto(poll.Token)
+	}
+	return resp, nil
+}
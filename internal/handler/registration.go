@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *IdentityHandler) ListPendingRegistrations(ctx context.Context, req *identityv1.ListPendingRegistrationsRequest) (*identityv1.ListPendingRegistrationsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	pending, err := h.listPendingRegistrationsUC.Execute(ctx, userID)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	resp := &identityv1.ListPendingRegistrationsResponse{}
+	for _, p := range pending {
+		resp.Registrations = append(resp.Registrations, &identityv1.PendingRegistration{
+			User:      mapUserToProto(p.User),
+			CreatedAt: timestamppb.New(p.CreatedAt),
+		})
+	}
+	return resp, nil
+}
+
+// ResolveRegistration activates a registration waiting for approval, or
+// deletes the account when approve is false.
+func (h *IdentityHandler) ResolveRegistration(ctx context.Context, req *identityv1.ResolveRegistrationRequest) (*identityv1.ResolveRegistrationResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := h.resolveRegistrationUC.Execute(ctx, userID, req.UserId, req.Approve); err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.ResolveRegistrationResponse{}, nil
+}
+
+func (h *IdentityHandler) GetDomainRules(ctx context.Context, req *identityv1.GetDomainRulesRequest) (*identityv1.DomainRules, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	rules, err := h.getDomainRulesUC.Execute(ctx, userID)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return mapDomainRulesToProto(rules), nil
+}
+
+// SetDomainRules replaces the email domain allow and deny lists of the
+// running environment. Patterns are domains or *.domain for subdomains.
+func (h *IdentityHandler) SetDomainRules(ctx context.Context, req *identityv1.SetDomainRulesRequest) (*identityv1.DomainRules, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	rules, err := h.setDomainRulesUC.Execute(ctx, userID, req.Allow, req.Deny)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return mapDomainRulesToProto(rules), nil
+}
+
+func mapDomainRulesToProto(r domain.DomainRules) *identityv1.DomainRules {
+	return &identityv1.DomainRules{
+		Environment: r.Environment,
+		Allow:       r.Allow,
+		Deny:        r.Deny,
+	}
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *IdentityHandler) WriteRelations(ctx context.Context, req *identityv1.WriteRelationsRequest) (*identityv1.WriteRelationsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Writes) == 0 && len(req.Deletes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "writes or deletes required")
+	}
+	writes, err := mapTuplesFromProto(req.Writes)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	deletes, err := mapTuplesFromProto(req.Deletes)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	token, err := h.writeRelationsUC.Execute(ctx, userID, writes, deletes)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.WriteRelationsResponse{ConsistencyToken: token}, nil
+}
+
+func (h *IdentityHandler) Check(ctx context.Context, req *identityv1.CheckRequest) (*identityv1.CheckResponse, error) {
+	if req.Permission == "" {
+		return nil, status.Error(codes.InvalidArgument, "permission required")
+	}
+	object, err := domain.ParseObjectRef(req.Object)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	subject, err := domain.ParseSubjectRef(req.Subject)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	if err := requireSelfOrService(ctx, subject); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	callerID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allowed, token, err := h.checkRelationUC.Execute(ctx, object, req.Permission, subject, req.ConsistencyToken,
+		callerID, clientInfoFromContext(ctx))
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.CheckResponse{Allowed: allowed, ConsistencyToken: token}, nil
+}
+
+func (h *IdentityHandler) ListObjects(ctx context.Context, req *identityv1.ListObjectsRequest) (*identityv1.ListObjectsResponse, error) {
+	if req.ObjectType == "" || req.Permission == "" {
+		return nil, status.Error(codes.InvalidArgument, "object type and permission required")
+	}
+	subject, err := domain.ParseSubjectRef(req.Subject)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	if err := requireSelfOrService(ctx, subject); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	ids, token, err := h.listObjectsUC.Execute(ctx, req.ObjectType, req.Permission, subject, req.ConsistencyToken)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.ListObjectsResponse{ObjectIds: ids, ConsistencyToken: token}, nil
+}
+
+// ListSubjects is for service accounts only: it would let anyone list who
+// has access to an object.
+func (h *IdentityHandler) ListSubjects(ctx context.Context, req *identityv1.ListSubjectsRequest) (*identityv1.ListSubjectsResponse, error) {
+	if _, err := serviceAccountFromContext(ctx); err != nil {
+		return nil, err
+	}
+	if req.SubjectType == "" || req.Permission == "" {
+		return nil, status.Error(codes.InvalidArgument, "subject type and permission required")
+	}
+	object, err := domain.ParseObjectRef(req.Object)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	ids, token, err := h.listSubjectsUC.Execute(ctx, object, req.Permission, req.SubjectType, req.ConsistencyToken)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.ListSubjectsResponse{SubjectIds: ids, ConsistencyToken: token}, nil
+}
+
+// requireSelfOrService lets service accounts query any subject. People may
+// only ask about themselves.
+func requireSelfOrService(ctx context.Context, subject domain.SubjectRef) error {
+	if isServiceAccount(ctx) {
+		return nil
+	}
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if subject.Type != "user" || subject.ID != userID || subject.Relation != "" {
+		return status.Error(codes.PermissionDenied, "only service accounts may query relations of other subjects")
+	}
+	return nil
+}
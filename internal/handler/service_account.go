@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *IdentityHandler) CreateServiceAccount(ctx context.Context, req *identityv1.CreateServiceAccountRequest) (*identityv1.CreateServiceAccountResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name == "" || len(req.Scopes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "name and scopes required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	account, secret, err := h.createServiceAccountUC.Execute(ctx, userID, &domain.ServiceAccount{
+		Name:   req.Name,
+		Scopes: req.Scopes,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.CreateServiceAccountResponse{
+		ClientId:     account.ClientID,
+		ClientSecret: secret,
+		CreatedAt:    timestamppb.New(account.CreatedAt),
+	}, nil
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// LookupDevice shows which client a code shown by a kiosk or TV belongs to
+// and what it asks for. The app shows this before offering ApproveDevice.
+func (h *IdentityHandler) LookupDevice(ctx context.Context, req *identityv1.LookupDeviceRequest) (*identityv1.LookupDeviceResponse, error) {
+	if _, err := userIDFromContext(ctx); err != nil {
+		return nil, err
+	}
+	if req.UserCode == "" {
+		return nil, status.Error(codes.InvalidArgument, "user code required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	device, err := h.lookupDeviceUC.Execute(ctx, req.UserCode)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.LookupDeviceResponse{
+		ClientId:   device.ClientID,
+		ClientName: device.ClientName,
+		Scope:      device.Scope,
+		ExpiresAt:  timestamppb.New(device.ExpiresAt),
+	}, nil
+}
+
+// ApproveDevice lets a user signed in on their phone approve or deny the
+// code shown by a kiosk or TV. client_id must be the one LookupDevice
+// returned.
+func (h *IdentityHandler) ApproveDevice(ctx context.Context, req *identityv1.ApproveDeviceRequest) (*identityv1.ApproveDeviceResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserCode == "" || req.ClientId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user code and client id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	device, err := h.approveDeviceUC.Execute(ctx, domain.DeviceApproval{
+		UserID:   userID,
+		UserCode: req.UserCode,
+		ClientID: req.ClientId,
+		Approve:  req.Approve,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.ApproveDeviceResponse{
+		ClientId:   device.ClientID,
+		ClientName: device.ClientName,
+		Scope:      device.Scope,
+	}, nil
+}
+
+func (h *IdentityHandler) ListSessions(ctx context.Context, req *identityv1.ListSessionsRequest) (*identityv1.ListSessionsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	sessions, err := h.listSessionsUC.Execute(ctx, userID)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	resp := &identityv1.ListSessionsResponse{}
+	for _, s := range sessions {
+		resp.Sessions = append(resp.Sessions, mapSessionToProto(s))
+	}
+	return resp, nil
+}
+
+func (h *IdentityHandler) RevokeSession(ctx context.Context, req *identityv1.RevokeSessionRequest) (*identityv1.RevokeSessionResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.SessionId == "" {
+		return nil, status.Error(codes.InvalidArgument, "session id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := h.revokeSessionUC.Execute(ctx, userID, req.SessionId); err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.RevokeSessionResponse{}, nil
+}
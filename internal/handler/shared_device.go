@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// RegisterSharedDevice registers a tablet to a business location. The device
+// secret is returned once and must be stored on the device.
+func (h *IdentityHandler) RegisterSharedDevice(ctx context.Context, req *identityv1.RegisterSharedDeviceRequest) (*identityv1.RegisterSharedDeviceResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.BusinessId == "" || req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "business id and name required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	device, secret, err := h.registerSharedDeviceUC.Execute(ctx, userID, &domain.SharedDevice{
+		BusinessID: req.BusinessId,
+		Name:       req.Name,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.RegisterSharedDeviceResponse{
+		DeviceId:     device.ID,
+		DeviceSecret: secret,
+		CreatedAt:    timestamppb.New(device.CreatedAt),
+	}, nil
+}
+
+func (h *IdentityHandler) RevokeSharedDevice(ctx context.Context, req *identityv1.RevokeSharedDeviceRequest) (*identityv1.RevokeSharedDeviceResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.DeviceId == "" {
+		return nil, status.Error(codes.InvalidArgument, "device id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := h.revokeSharedDeviceUC.Execute(ctx, userID, req.DeviceId); err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.RevokeSharedDeviceResponse{}, nil
+}
+
+func (h *IdentityHandler) SetDevicePin(ctx context.Context, req *identityv1.SetDevicePinRequest) (*identityv1.SetDevicePinResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.DeviceId == "" {
+		return nil, status.Error(codes.InvalidArgument, "device id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := h.setDevicePinUC.Execute(ctx, userID, req.DeviceId, req.Pin); err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.SetDevicePinResponse{}, nil
+}
+
+func (h *IdentityHandler) ListDeviceUsers(ctx context.Context, req *identityv1.ListDeviceUsersRequest) (*identityv1.ListDeviceUsersResponse, error) {
+	if req.DeviceId == "" || req.DeviceSecret == "" {
+		return nil, status.Error(codes.InvalidArgument, "device id and secret required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	users, err := h.listDeviceUsersUC.Execute(ctx, req.DeviceId, req.DeviceSecret)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	resp := &identityv1.ListDeviceUsersResponse{}
+	for _, u := range users {
+		resp.Users = append(resp.Users, &identityv1.DeviceUser{
+			UserId:    u.UserID,
+			FirstName: u.FirstName,
+			LastName:  u.LastName,
+		})
+	}
+	return resp, nil
+}
+
+// PinLogin signs a member in on a shared device. The access token is short
+// lived, bound to the device's business and comes without a refresh token.
+func (h *IdentityHandler) PinLogin(ctx context.Context, req *identityv1.PinLoginRequest) (*identityv1.LoginResponse, error) {
+	if req.DeviceId == "" || req.DeviceSecret == "" || req.UserId == "" || req.Pin == "" {
+		return nil, status.Error(codes.InvalidArgument, "device id, device secret, user id and pin required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	user, token, err := h.pinLoginUC.Execute(ctx, domain.PinLoginRequest{
+		DeviceID:     req.DeviceId,
+		DeviceSecret: req.DeviceSecret,
+		UserID:       req.UserId,
+		Pin:          req.Pin,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.LoginResponse{
+		User:      mapUserToProto(user),
+		AuthToken: mapTokenToProto(token),
+	}, nil
+}
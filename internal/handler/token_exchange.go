@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *IdentityHandler) ExchangeToken(ctx context.Context, req *identityv1.ExchangeTokenRequest) (*identityv1.ExchangeTokenResponse, error) {
+	clientID, err := serviceAccountFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.SubjectToken == "" || req.Audience == "" {
+		return nil, status.Error(codes.InvalidArgument, "subject token and audience required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	result, err := h.exchangeTokenUC.Execute(ctx, domain.TokenExchangeRequest{
+		CallerClientID: clientID,
+		SubjectToken:   req.SubjectToken,
+		ActorToken:     req.ActorToken,
+		Audience:       req.Audience,
+		Scope:          req.Scope,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.ExchangeTokenResponse{
+		AccessToken:     result.AccessToken,
+		IssuedTokenType: result.IssuedTokenType,
+		Scope:           result.Scope,
+		ExpiresAt:       timestamppb.New(result.ExpiresAt),
+	}, nil
+}
+
+func (h *IdentityHandler) SetTokenExchangePolicy(ctx context.Context, req *identityv1.SetTokenExchangePolicyRequest) (*identityv1.TokenExchangePolicy, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	policy, err := h.setTokenExchangePolicyUC.Execute(ctx, userID, &domain.TokenExchangePolicy{
+		ClientID:   req.ClientId,
+		Audience:   req.Audience,
+		Scopes:     req.Scopes,
+		ActorRoles: req.ActorRoles,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.TokenExchangePolicy{
+		ClientId:   policy.ClientID,
+		Audience:   policy.Audience,
+		Scopes:     policy.Scopes,
+		ActorRoles: policy.ActorRoles,
+		CreatedBy:  policy.CreatedBy,
+		CreatedAt:  timestamppb.New(policy.CreatedAt),
+	}, nil
+}
+
+func (h *IdentityHandler) DeleteTokenExchangePolicy(ctx context.Context, req *identityv1.DeleteTokenExchangePolicyRequest) (*identityv1.DeleteTokenExchangePolicyResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.ClientId == "" || req.Audience == "" {
+		return nil, status.Error(codes.InvalidArgument, "client id and audience required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := h.deleteTokenExchangePolicyUC.Execute(ctx, userID, req.ClientId, req.Audience); err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.DeleteTokenExchangePolicyResponse{}, nil
+}
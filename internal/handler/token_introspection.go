@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// IntrospectToken and RevokeToken are public RPCs: like their HTTP
+// counterparts the caller authenticates with client credentials in the request.
+
+func (h *IdentityHandler) IntrospectToken(ctx context.Context, req *identityv1.IntrospectTokenRequest) (*identityv1.IntrospectTokenResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	result, err := h.introspectTokenUC.Execute(ctx, domain.ClientTokenRequest{
+		ClientID:      req.ClientId,
+		ClientSecret:  req.ClientSecret,
+		Token:         req.Token,
+		TokenTypeHint: req.TokenTypeHint,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	if !result.Active {
+		return &identityv1.IntrospectTokenResponse{Active: false}, nil
+	}
+	return &identityv1.IntrospectTokenResponse{
+		Active:     true,
+		TokenType:  result.TokenType,
+		Scope:      result.Scope,
+		ClientId:   result.ClientID,
+		Sub:        result.Subject,
+		BusinessId: result.BusinessID,
+		Audience:   result.Audience,
+		ActorId:    result.ActorID,
+		IssuedAt:   timestamppb.New(result.IssuedAt),
+		ExpiresAt:  timestamppb.New(result.ExpiresAt),
+	}, nil
+}
+
+func (h *IdentityHandler) RevokeToken(ctx context.Context, req *identityv1.RevokeTokenRequest) (*identityv1.RevokeTokenResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "token required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	err := h.revokeTokenUC.Execute(ctx, domain.ClientTokenRequest{
+		ClientID:      req.ClientId,
+		ClientSecret:  req.ClientSecret,
+		Token:         req.Token,
+		TokenTypeHint: req.TokenTypeHint,
+	})
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.RevokeTokenResponse{}, nil
+}
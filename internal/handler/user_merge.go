@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	identityv1 "github.com/ialekseychuk/my-place-proto/gen/go/identity/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *IdentityHandler) ListMergeRequests(ctx context.Context, req *identityv1.ListMergeRequestsRequest) (*identityv1.ListMergeRequestsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	requests, err := h.listMergeRequestsUC.Execute(ctx, userID)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	resp := &identityv1.ListMergeRequestsResponse{}
+	for _, r := range requests {
+		resp.Requests = append(resp.Requests, &identityv1.MergeRequest{
+			Id:           r.ID,
+			SourceUserId: r.SourceUserID,
+			TargetUserId: r.TargetUserID,
+			ProviderId:   r.ProviderID,
+			CreatedAt:    timestamppb.New(r.CreatedAt),
+		})
+	}
+	return resp, nil
+}
+
+// MergeAccounts folds source_user_id into target_user_id. Downstream services
+// learn about it from the user.merged event in ListUserEvents.
+func (h *IdentityHandler) MergeAccounts(ctx context.Context, req *identityv1.MergeAccountsRequest) (*identityv1.MergeAccountsResponse, error) {
+	userID, err := userIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.SourceUserId == "" || req.TargetUserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "source and target user ids required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	m, err := h.mergeAccountsUC.Execute(ctx, userID, req.SourceUserId, req.TargetUserId)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.MergeAccountsResponse{
+		SourceUserId: m.SourceUserID,
+		TargetUserId: m.TargetUserID,
+		MergedAt:     timestamppb.New(m.CreatedAt),
+	}, nil
+}
+
+// ResolveUserID maps a user ID a service stored earlier to the current one.
+func (h *IdentityHandler) ResolveUserID(ctx context.Context, req *identityv1.ResolveUserIDRequest) (*identityv1.ResolveUserIDResponse, error) {
+	if _, err := serviceAccountFromContext(ctx); err != nil {
+		return nil, err
+	}
+	if req.UserId == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id required")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	current, err := h.resolveUserIDUC.Execute(ctx, req.UserId)
+	if err != nil {
+		return nil, handleError(err)
+	}
+	return &identityv1.ResolveUserIDResponse{
+		UserId: current,
+		Merged: current != req.UserId,
+	}, nil
+}
+
+// ListUserEvents returns events after after_id; consumers keep the last ID
+// they processed and poll from it.
+func (h *IdentityHandler) ListUserEvents(ctx context.Context, req *identityv1.ListUserEventsRequest) (*identityv1.ListUserEventsResponse, error) {
+	if _, err := serviceAccountFromContext(ctx); err != nil {
+		return nil, err
+	}
+	if req.AfterId < 0 {
+		return nil, status.Error(codes.InvalidArgument, "after id must not be negative")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	events, err := h.listUserEventsUC.Execute(ctx, req.AfterId, int(req.Limit))
+	if err != nil {
+		return nil, handleError(err)
+	}
+	resp := &identityv1.ListUserEventsResponse{}
+	for _, e := range events {
+		resp.Events = append(resp.Events, mapUserEventToProto(e))
+	}
+	return resp, nil
+}
+
+func mapUserEventToProto(e *domain.UserEvent) *identityv1.UserEvent {
+	return &identityv1.UserEvent{
+		Id:        e.ID,
+		Type:      e.Type,
+		UserId:    e.UserID,
+		Data:      e.Data,
+		CreatedAt: timestamppb.New(e.CreatedAt),
+	}
+}
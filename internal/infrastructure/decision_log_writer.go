@@ -0,0 +1,98 @@
+package infrastructure
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	decisionLogBatchSize     = 256
+	decisionLogFlushInterval = time.Second
+)
+
+// DecisionLogWriter buffers permission decisions in a bounded queue and writes
+// them in batches from a single goroutine. When the queue is full new records
+// are dropped, so a slow database never slows down permission checks.
+type DecisionLogWriter struct {
+	repo       domain.DecisionLogRepository
+	sampleRate float64
+	records    chan domain.DecisionRecord
+	dropped    atomic.Int64
+	closeOnce  sync.Once
+	done       chan struct{}
+}
+
+// NewDecisionLogWriter keeps every denial and the sampleRate share (0..1) of
+// allowed decisions. Call Run to start writing.
+func NewDecisionLogWriter(repo domain.DecisionLogRepository, bufferSize int, sampleRate float64) *DecisionLogWriter {
+	return &DecisionLogWriter{
+		repo:       repo,
+		sampleRate: sampleRate,
+		records:    make(chan domain.DecisionRecord, bufferSize),
+		done:       make(chan struct{}),
+	}
+}
+
+func (w *DecisionLogWriter) Log(r domain.DecisionRecord) {
+	if r.Allowed && rand.Float64() >= w.sampleRate {
+		return
+	}
+	select {
+	case w.records <- r:
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+// Run writes queued records until Close is called and the queue is drained.
+func (w *DecisionLogWriter) Run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(decisionLogFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]domain.DecisionRecord, 0, decisionLogBatchSize)
+	for {
+		select {
+		case r, ok := <-w.records:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, r)
+			if len(batch) == decisionLogBatchSize {
+				batch = w.flush(batch)
+			}
+		case <-ticker.C:
+			batch = w.flush(batch)
+		}
+	}
+}
+
+// Close stops accepting records and waits for the queue to be written.
+// Log must not be called after Close.
+func (w *DecisionLogWriter) Close() {
+	w.closeOnce.Do(func() { close(w.records) })
+	<-w.done
+}
+
+func (w *DecisionLogWriter) flush(batch []domain.DecisionRecord) []domain.DecisionRecord {
+	if n := w.dropped.Swap(0); n > 0 {
+		logrus.Warnf("decision log: dropped %d records, queue full", n)
+	}
+	if len(batch) == 0 {
+		return batch
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.repo.InsertBatch(ctx, batch); err != nil {
+		logrus.Errorf("decision log: failed to write %d records: %v", len(batch), err)
+	}
+	return batch[:0]
+}
@@ -0,0 +1,227 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+type domainRuleLists struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// domainRulesFile is the JSON layout. A list set for an environment,
+// even an empty one, replaces the top-level list there.
+type domainRulesFile struct {
+	domainRuleLists
+	Environments map[string]domainRuleLists `json:"environments,omitempty"`
+}
+
+// DomainRuleStore serves the email domain rules for one environment from
+// a local file. Run reloads the file when it changes; Set writes changes
+// back to it. With no file the rules live in memory and start empty.
+type DomainRuleStore struct {
+	path string
+	env  string
+
+	mu      sync.RWMutex
+	rules   domain.DomainRules
+	modTime time.Time
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func NewDomainRuleStore(path, env string) (*DomainRuleStore, error) {
+	s := &DomainRuleStore{
+		path:  path,
+		env:   env,
+		rules: domain.DomainRules{Environment: env},
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if path == "" {
+		return s, nil
+	}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *DomainRuleStore) Rules() domain.DomainRules {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.rules
+}
+
+func (s *DomainRuleStore) Set(allow, deny []string) (domain.DomainRules, error) {
+	lists, err := normalizeDomainRuleLists(domainRuleLists{Allow: allow, Deny: deny})
+	if err != nil {
+		return domain.DomainRules{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path != "" {
+		if err := s.write(lists); err != nil {
+			return domain.DomainRules{}, err
+		}
+	}
+	s.rules = domain.DomainRules{Environment: s.env, Allow: lists.Allow, Deny: lists.Deny}
+	return s.rules, nil
+}
+
+// Run checks the file every interval until Close is called. A file that
+// fails to load is logged and the previous rules stay active.
+func (s *DomainRuleStore) Run(interval time.Duration) {
+	defer close(s.done)
+	if s.path == "" {
+		<-s.stop
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.reload(); err != nil {
+				logrus.Errorf("domain rules: %v", err)
+			}
+		}
+	}
+}
+
+func (s *DomainRuleStore) Close() {
+	s.closeOnce.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+// reload holds mu from stat to swap, so a Set between them can neither be
+// overwritten by the older file nor have its write read back half done.
+func (s *DomainRuleStore) reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		// Set creates the file
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read domain rules: %w", err)
+	}
+	if info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	lists := f.domainRuleLists
+	if o, ok := f.Environments[s.env]; ok {
+		if o.Allow != nil {
+			lists.Allow = o.Allow
+		}
+		if o.Deny != nil {
+			lists.Deny = o.Deny
+		}
+	}
+	if lists, err = normalizeDomainRuleLists(lists); err != nil {
+		return fmt.Errorf("parse domain rules: %w", err)
+	}
+
+	s.rules = domain.DomainRules{Environment: s.env, Allow: lists.Allow, Deny: lists.Deny}
+	s.modTime = info.ModTime()
+	logrus.Infof("domain rules: loaded %d allow and %d deny patterns", len(lists.Allow), len(lists.Deny))
+	return nil
+}
+
+func (s *DomainRuleStore) read() (*domainRulesFile, error) {
+	var f domainRulesFile
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &f, nil
+		}
+		return nil, fmt.Errorf("read domain rules: %w", err)
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse domain rules: %w", err)
+	}
+	return &f, nil
+}
+
+// write stores lists for the environment, or at the top level when none is
+// set, through a temporary file so a reload never sees half of it. Call
+// with mu held.
+func (s *DomainRuleStore) write(lists domainRuleLists) error {
+	f, err := s.read()
+	if err != nil {
+		return err
+	}
+	if s.env == "" {
+		f.domainRuleLists = lists
+	} else {
+		if f.Environments == nil {
+			f.Environments = map[string]domainRuleLists{}
+		}
+		f.Environments[s.env] = lists
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".domain-rules-*")
+	if err != nil {
+		return fmt.Errorf("write domain rules: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write domain rules: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write domain rules: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("write domain rules: %w", err)
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// normalizeDomainRuleLists validates every pattern and never returns nil
+// lists, so an environment written back keeps overriding the top level.
+func normalizeDomainRuleLists(in domainRuleLists) (domainRuleLists, error) {
+	out := domainRuleLists{Allow: []string{}, Deny: []string{}}
+	for _, list := range []struct {
+		src []string
+		dst *[]string
+	}{{in.Allow, &out.Allow}, {in.Deny, &out.Deny}} {
+		for _, p := range list.src {
+			n, ok := domain.NormalizeDomainPattern(p)
+			if !ok {
+				return domainRuleLists{}, fmt.Errorf("%w: %q", domain.ErrInvalidDomainRule, p)
+			}
+			*list.dst = append(*list.dst, n)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,101 @@
+package infrastructure
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// IDTokenSigner signs OpenID Connect ID tokens with RS256. Unlike access
+// tokens, ID tokens are verified by third-party clients, so they use a key
+// pair whose public half is published as a JWKS.
+type IDTokenSigner struct {
+	key *rsa.PrivateKey
+	kid string
+}
+
+// LoadIDTokenSigner reads a PEM encoded RSA private key (PKCS#1 or PKCS#8).
+// With an empty path it generates a throwaway key, which is only fit for
+// development: tokens stop verifying after a restart.
+func LoadIDTokenSigner(path string) (*IDTokenSigner, error) {
+	if path == "" {
+		logrus.Warn("OIDC_SIGNING_KEY_PATH is not set, using an ephemeral ID token signing key")
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, fmt.Errorf("generate signing key: %w", err)
+		}
+		return newIDTokenSigner(key)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("signing key: no PEM block found")
+	}
+	var key *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		var parsed any
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err == nil {
+			var ok bool
+			if key, ok = parsed.(*rsa.PrivateKey); !ok {
+				err = errors.New("not an RSA key")
+			}
+		}
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing key: %w", err)
+	}
+	return newIDTokenSigner(key)
+}
+
+func newIDTokenSigner(key *rsa.PrivateKey) (*IDTokenSigner, error) {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("marshal public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return &IDTokenSigner{
+		key: key,
+		kid: base64.RawURLEncoding.EncodeToString(sum[:12]),
+	}, nil
+}
+
+func (s *IDTokenSigner) Sign(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.kid
+	return token.SignedString(s.key)
+}
+
+// JWKS returns the public key set served at the jwks_uri.
+func (s *IDTokenSigner) JWKS() map[string]any {
+	pub := s.key.PublicKey
+	return map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": s.kid,
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	}
+}
@@ -0,0 +1,434 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	providerHTTPTimeout  = 10 * time.Second
+	providerMaxBody      = 1 << 20
+	jwksMinRefetchPeriod = time.Minute
+)
+
+// IdentityProviderConfig is one entry of the providers file. With Issuer set
+// and no endpoints, everything else is read from the issuer's discovery
+// document. Providers without OpenID Connect (no id_token) are configured
+// with static endpoints and a userinfo endpoint; Claims then names the
+// userinfo fields holding sub, email, email_verified, given_name and
+// family_name when they differ from the standard ones.
+type IdentityProviderConfig struct {
+	ID                    string            `json:"id"`
+	Name                  string            `json:"name"`
+	Issuer                string            `json:"issuer"`
+	ClientID              string            `json:"client_id"`
+	ClientSecret          string            `json:"client_secret"`
+	Scopes                []string          `json:"scopes"`
+	RedirectURIs          []string          `json:"redirect_uris"`
+	AuthorizationEndpoint string            `json:"authorization_endpoint"`
+	TokenEndpoint         string            `json:"token_endpoint"`
+	JWKSURI               string            `json:"jwks_uri"`
+	UserInfoEndpoint      string            `json:"userinfo_endpoint"`
+	Claims                map[string]string `json:"claims"`
+}
+
+// LoadIdentityProviders reads a JSON array of IdentityProviderConfig. An
+// empty path disables federated login. Any OpenID provider works as a
+// stand-in for local testing, including another instance of this service.
+func LoadIdentityProviders(path string) (map[string]domain.IdentityProvider, error) {
+	providers := map[string]domain.IdentityProvider{}
+	if path == "" {
+		return providers, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read identity providers: %w", err)
+	}
+	var configs []IdentityProviderConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("parse identity providers: %w", err)
+	}
+	for _, cfg := range configs {
+		if cfg.ID == "" || cfg.ClientID == "" || len(cfg.RedirectURIs) == 0 {
+			return nil, fmt.Errorf("identity provider %q: id, client_id and redirect_uris are required", cfg.ID)
+		}
+		if cfg.Issuer == "" && (cfg.AuthorizationEndpoint == "" || cfg.TokenEndpoint == "") {
+			return nil, fmt.Errorf("identity provider %q: issuer or authorization and token endpoints are required", cfg.ID)
+		}
+		if _, ok := providers[cfg.ID]; ok {
+			return nil, fmt.Errorf("identity provider %q is configured twice", cfg.ID)
+		}
+		providers[cfg.ID] = NewOIDCProvider(cfg)
+	}
+	return providers, nil
+}
+
+// OIDCProvider signs users in with the authorization code flow and PKCE. It
+// verifies the ID token against the provider's JWKS, or reads the userinfo
+// endpoint when the provider issues no ID token.
+type OIDCProvider struct {
+	cfg    IdentityProviderConfig
+	client *http.Client
+
+	mu         sync.Mutex
+	discovered bool
+	keys       map[string]any
+	keysAt     time.Time
+}
+
+func NewOIDCProvider(cfg IdentityProviderConfig) *OIDCProvider {
+	if cfg.Name == "" {
+		cfg.Name = cfg.ID
+	}
+	return &OIDCProvider{
+		cfg:    cfg,
+		client: &http.Client{Timeout: providerHTTPTimeout},
+	}
+}
+
+func (p *OIDCProvider) ID() string   { return p.cfg.ID }
+func (p *OIDCProvider) Name() string { return p.cfg.Name }
+
+func (p *OIDCProvider) AllowsRedirectURI(uri string) bool {
+	return slices.Contains(p.cfg.RedirectURIs, uri)
+}
+
+func (p *OIDCProvider) AuthorizationURL(ctx context.Context, state, nonce, codeChallenge,
+	redirectURI string) (string, error) {
+	cfg, err := p.config(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(cfg.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("identity provider %s: authorization endpoint: %w", cfg.ID, err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", cfg.ClientID)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("scope", strings.Join(cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	if cfg.Issuer != "" {
+		q.Set("nonce", nonce)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, redirectURI,
+	nonce string) (*domain.ExternalIdentity, error) {
+	identity, err := p.exchange(ctx, code, codeVerifier, redirectURI, nonce)
+	if err != nil {
+		logrus.Warnf("identity provider %s: %v", p.cfg.ID, err)
+		return nil, fmt.Errorf("%w: %v", domain.ErrExternalLoginFailed, err)
+	}
+	return identity, nil
+}
+
+func (p *OIDCProvider) exchange(ctx context.Context, code, codeVerifier, redirectURI,
+	nonce string) (*domain.ExternalIdentity, error) {
+	cfg, err := p.config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+	}
+	if err := p.doJSON(req, &tokens); err != nil {
+		return nil, fmt.Errorf("token endpoint: %w", err)
+	}
+
+	var claims map[string]any
+	switch {
+	case tokens.IDToken != "":
+		claims, err = p.verifyIDToken(ctx, cfg, tokens.IDToken, nonce)
+	case cfg.UserInfoEndpoint != "" && tokens.AccessToken != "":
+		claims, err = p.userInfo(ctx, cfg, tokens.AccessToken)
+	default:
+		err = errors.New("token response has neither an id_token nor an access_token for userinfo")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &domain.ExternalIdentity{
+		ProviderID:    cfg.ID,
+		Subject:       claimString(claims[p.claimName("sub")]),
+		Email:         claimString(claims[p.claimName("email")]),
+		EmailVerified: claimBool(claims[p.claimName("email_verified")]),
+		FirstName:     claimString(claims[p.claimName("given_name")]),
+		LastName:      claimString(claims[p.claimName("family_name")]),
+	}
+	if identity.Subject == "" {
+		return nil, errors.New("no subject in provider claims")
+	}
+	return identity, nil
+}
+
+func (p *OIDCProvider) claimName(standard string) string {
+	if name, ok := p.cfg.Claims[standard]; ok {
+		return name
+	}
+	return standard
+}
+
+// verifyIDToken checks signature, issuer, audience, expiry and nonce.
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, cfg IdentityProviderConfig, raw,
+	nonce string) (map[string]any, error) {
+	if cfg.Issuer == "" || cfg.JWKSURI == "" {
+		return nil, errors.New("id_token received but issuer or jwks_uri is not configured")
+	}
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, cfg, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("id_token: %w", err)
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return nil, errors.New("id_token: nonce mismatch")
+	}
+	return claims, nil
+}
+
+// key returns the JWKS key for kid. Unknown kids trigger a refetch, so key
+// rotation at the provider is picked up, but at most once a minute.
+func (p *OIDCProvider) key(ctx context.Context, cfg IdentityProviderConfig, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key := lookupKey(p.keys, kid); key != nil {
+		return key, nil
+	}
+	if time.Since(p.keysAt) < jwksMinRefetchPeriod {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []map[string]any `json:"keys"`
+	}
+	if err := p.doJSON(req, &set); err != nil {
+		return nil, fmt.Errorf("jwks: %w", err)
+	}
+	keys := map[string]any{}
+	for _, jwk := range set.Keys {
+		if use, _ := jwk["use"].(string); use != "" && use != "sig" {
+			continue
+		}
+		key, err := parseJWK(jwk)
+		if err != nil {
+			continue
+		}
+		id, _ := jwk["kid"].(string)
+		keys[id] = key
+	}
+	p.keys, p.keysAt = keys, time.Now()
+
+	if key := lookupKey(p.keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds kid; a token without kid matches a single-key set.
+func lookupKey(keys map[string]any, kid string) any {
+	if key, ok := keys[kid]; ok {
+		return key
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return nil
+}
+
+func parseJWK(jwk map[string]any) (any, error) {
+	field := func(name string) ([]byte, error) {
+		s, _ := jwk[name].(string)
+		if s == "" {
+			return nil, fmt.Errorf("jwk: missing %s", name)
+		}
+		return base64.RawURLEncoding.DecodeString(s)
+	}
+	switch jwk["kty"] {
+	case "RSA":
+		n, err := field("n")
+		if err != nil {
+			return nil, err
+		}
+		e, err := field("e")
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk["crv"] {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwk: unsupported curve %v", jwk["crv"])
+		}
+		x, err := field("x")
+		if err != nil {
+			return nil, err
+		}
+		y, err := field("y")
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("jwk: unsupported key type %v", jwk["kty"])
+}
+
+func (p *OIDCProvider) userInfo(ctx context.Context, cfg IdentityProviderConfig, accessToken string) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.UserInfoEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	var claims map[string]any
+	if err := p.doJSON(req, &claims); err != nil {
+		return nil, fmt.Errorf("userinfo: %w", err)
+	}
+	return claims, nil
+}
+
+// config returns the configuration completed from the discovery document.
+// Static endpoints take precedence over discovered ones. A failed discovery
+// is retried on the next call.
+func (p *OIDCProvider) config(ctx context.Context) (IdentityProviderConfig, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovered || p.cfg.Issuer == "" {
+		return p.cfg, nil
+	}
+	discoveryURL := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return p.cfg, err
+	}
+	var doc struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+		UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	}
+	if err := p.doJSON(req, &doc); err != nil {
+		return p.cfg, fmt.Errorf("identity provider %s: discovery: %w", p.cfg.ID, err)
+	}
+	if doc.Issuer != p.cfg.Issuer {
+		return p.cfg, fmt.Errorf("identity provider %s: discovery issuer %q does not match", p.cfg.ID, doc.Issuer)
+	}
+	fill := func(dst *string, v string) {
+		if *dst == "" {
+			*dst = v
+		}
+	}
+	fill(&p.cfg.AuthorizationEndpoint, doc.AuthorizationEndpoint)
+	fill(&p.cfg.TokenEndpoint, doc.TokenEndpoint)
+	fill(&p.cfg.JWKSURI, doc.JWKSURI)
+	fill(&p.cfg.UserInfoEndpoint, doc.UserInfoEndpoint)
+	p.discovered = true
+	return p.cfg, nil
+}
+
+func (p *OIDCProvider) doJSON(req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, providerMaxBody))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	return dec.Decode(out)
+}
+
+// claimString accepts numbers too: some providers use numeric user ids.
+func claimString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+func claimBool(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+	return false
+}
@@ -0,0 +1,356 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+const (
+	testClientID    = "identity"
+	testRedirectURI = "https://id.example.com/federated/callback"
+	testVerifier    = "verifier-verifier-verifier-verifier-verifier"
+	testNonce       = "nonce-1"
+)
+
+// fakeOIDC is a stand-in OpenID provider: discovery, JWKS and a token
+// endpoint that returns whatever idToken builds.
+type fakeOIDC struct {
+	t   *testing.T
+	srv *httptest.Server
+
+	mu             sync.Mutex
+	issuer         string // announced in discovery; the server URL by default
+	keys           map[string]*rsa.PrivateKey
+	jwksRequests   int
+	tokenForm      url.Values
+	idToken        func(issuer string) string
+	userInfoClaims map[string]any
+}
+
+func newFakeOIDC(t *testing.T) *fakeOIDC {
+	t.Helper()
+	f := &fakeOIDC{t: t, keys: map[string]*rsa.PrivateKey{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		issuer := f.issuer
+		f.mu.Unlock()
+		writeTestJSON(w, map[string]any{
+			"issuer":                 issuer,
+			"authorization_endpoint": f.srv.URL + "/authorize",
+			"token_endpoint":         f.srv.URL + "/token",
+			"jwks_uri":               f.srv.URL + "/jwks",
+			"userinfo_endpoint":      f.srv.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.jwksRequests++
+		var keys []map[string]any
+		for kid, k := range f.keys {
+			keys = append(keys, map[string]any{
+				"kty": "RSA",
+				"use": "sig",
+				"kid": kid,
+				"n":   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			})
+		}
+		writeTestJSON(w, map[string]any{"keys": keys})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "bad form", http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		f.tokenForm = r.PostForm
+		build := f.idToken
+		f.mu.Unlock()
+		resp := map[string]any{"access_token": "provider-access-token", "token_type": "Bearer"}
+		if build != nil {
+			resp["id_token"] = build(f.srv.URL)
+		}
+		writeTestJSON(w, resp)
+	})
+	mux.HandleFunc("GET /userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer provider-access-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		writeTestJSON(w, f.userInfoClaims)
+	})
+	f.srv = httptest.NewServer(mux)
+	t.Cleanup(f.srv.Close)
+	f.issuer = f.srv.URL
+	return f
+}
+
+func writeTestJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// addKey publishes a new signing key under kid.
+func (f *fakeOIDC) addKey(kid string) *rsa.PrivateKey {
+	f.t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.mu.Lock()
+	f.keys[kid] = key
+	f.mu.Unlock()
+	return key
+}
+
+// sign returns an ID token for claims, signed with key under kid.
+func (f *fakeOIDC) sign(key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	f.t.Helper()
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = kid
+	raw, err := tok.SignedString(key)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return raw
+}
+
+func (f *fakeOIDC) returnIDToken(build func(issuer string) string) {
+	f.mu.Lock()
+	f.idToken = build
+	f.mu.Unlock()
+}
+
+func validClaims(issuer string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            issuer,
+		"sub":            "user-42",
+		"aud":            testClientID,
+		"exp":            time.Now().Add(5 * time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          testNonce,
+		"email":          "Ann@Example.com",
+		"email_verified": true,
+		"given_name":     "Ann",
+		"family_name":    "Lee",
+	}
+}
+
+func (f *fakeOIDC) provider() *OIDCProvider {
+	return NewOIDCProvider(IdentityProviderConfig{
+		ID:           "fake",
+		Issuer:       f.srv.URL,
+		ClientID:     testClientID,
+		ClientSecret: "client-secret",
+		Scopes:       []string{"openid", "email", "profile"},
+		RedirectURIs: []string{testRedirectURI},
+	})
+}
+
+func TestOIDCProviderAuthorizationURL(t *testing.T) {
+	f := newFakeOIDC(t)
+	p := f.provider()
+
+	raw, err := p.AuthorizationURL(context.Background(), "state-1", testNonce, "challenge-1", testRedirectURI)
+	if err != nil {
+		t.Fatalf("AuthorizationURL: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := u.Scheme+"://"+u.Host+u.Path, f.srv.URL+"/authorize"; got != want {
+		t.Errorf("endpoint = %s, want %s", got, want)
+	}
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURI,
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 testNonce,
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if got := u.Query().Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	f := newFakeOIDC(t)
+	key := f.addKey("k1")
+	f.returnIDToken(func(issuer string) string { return f.sign(key, "k1", validClaims(issuer)) })
+	p := f.provider()
+
+	ext, err := p.Exchange(context.Background(), "code-1", testVerifier, testRedirectURI, testNonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := domain.ExternalIdentity{
+		ProviderID:    "fake",
+		Subject:       "user-42",
+		Email:         "Ann@Example.com",
+		EmailVerified: true,
+		FirstName:     "Ann",
+		LastName:      "Lee",
+	}
+	if *ext != want {
+		t.Errorf("identity = %+v, want %+v", *ext, want)
+	}
+
+	f.mu.Lock()
+	form := f.tokenForm
+	f.mu.Unlock()
+	for k, v := range map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          "code-1",
+		"code_verifier": testVerifier,
+		"redirect_uri":  testRedirectURI,
+		"client_id":     testClientID,
+	} {
+		if got := form.Get(k); got != v {
+			t.Errorf("token request %s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestOIDCProviderRejectsIDToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims func(c jwt.MapClaims)
+		// signWithOther signs with a key the provider does not publish
+		signWithOther bool
+		nonce         string
+		wantErr       string
+	}{
+		{name: "issuer mismatch", claims: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }},
+		{name: "wrong audience", claims: func(c jwt.MapClaims) { c["aud"] = "someone-else" }},
+		{name: "expired", claims: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-10 * time.Minute).Unix() }},
+		{name: "no expiry", claims: func(c jwt.MapClaims) { delete(c, "exp") }},
+		{name: "nonce mismatch", nonce: "other-nonce", wantErr: "nonce mismatch"},
+		{name: "missing nonce", claims: func(c jwt.MapClaims) { delete(c, "nonce") }, wantErr: "nonce mismatch"},
+		{name: "bad signature", signWithOther: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeOIDC(t)
+			key := f.addKey("k1")
+			signer := key
+			if tt.signWithOther {
+				other, err := rsa.GenerateKey(rand.Reader, 2048)
+				if err != nil {
+					t.Fatal(err)
+				}
+				signer = other
+			}
+			f.returnIDToken(func(issuer string) string {
+				c := validClaims(issuer)
+				if tt.claims != nil {
+					tt.claims(c)
+				}
+				return f.sign(signer, "k1", c)
+			})
+			nonce := testNonce
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			_, err := f.provider().Exchange(context.Background(), "code-1", testVerifier, testRedirectURI, nonce)
+			if !errors.Is(err, domain.ErrExternalLoginFailed) {
+				t.Fatalf("Exchange error = %v, want ErrExternalLoginFailed", err)
+			}
+			if tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Exchange error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOIDCProviderRejectsDiscoveryIssuerMismatch(t *testing.T) {
+	f := newFakeOIDC(t)
+	f.mu.Lock()
+	f.issuer = "https://evil.example.com"
+	f.mu.Unlock()
+
+	_, err := f.provider().AuthorizationURL(context.Background(), "state-1", testNonce, "challenge-1", testRedirectURI)
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("AuthorizationURL error = %v, want issuer mismatch", err)
+	}
+}
+
+func TestOIDCProviderRefetchesJWKSOnKeyRotation(t *testing.T) {
+	f := newFakeOIDC(t)
+	old := f.addKey("k1")
+	f.returnIDToken(func(issuer string) string { return f.sign(old, "k1", validClaims(issuer)) })
+	p := f.provider()
+	ctx := context.Background()
+
+	if _, err := p.Exchange(ctx, "code-1", testVerifier, testRedirectURI, testNonce); err != nil {
+		t.Fatalf("Exchange with k1: %v", err)
+	}
+
+	rotated := f.addKey("k2")
+	f.returnIDToken(func(issuer string) string { return f.sign(rotated, "k2", validClaims(issuer)) })
+
+	// unknown kids refetch at most once a minute
+	if _, err := p.Exchange(ctx, "code-2", testVerifier, testRedirectURI, testNonce); err == nil {
+		t.Fatal("Exchange with k2 right after a fetch succeeded, want it refused until the refetch period passes")
+	}
+	p.mu.Lock()
+	p.keysAt = time.Now().Add(-2 * jwksMinRefetchPeriod)
+	p.mu.Unlock()
+
+	if _, err := p.Exchange(ctx, "code-3", testVerifier, testRedirectURI, testNonce); err != nil {
+		t.Fatalf("Exchange with k2 after the refetch period: %v", err)
+	}
+	f.mu.Lock()
+	requests := f.jwksRequests
+	f.mu.Unlock()
+	if requests != 2 {
+		t.Errorf("jwks fetched %d times, want 2", requests)
+	}
+}
+
+func TestOIDCProviderUserInfo(t *testing.T) {
+	f := newFakeOIDC(t)
+	f.userInfoClaims = map[string]any{"id": 1234, "mail": "bob@example.com", "verified": "true"}
+	p := NewOIDCProvider(IdentityProviderConfig{
+		ID:                    "plain",
+		ClientID:              testClientID,
+		RedirectURIs:          []string{testRedirectURI},
+		AuthorizationEndpoint: f.srv.URL + "/authorize",
+		TokenEndpoint:         f.srv.URL + "/token",
+		UserInfoEndpoint:      f.srv.URL + "/userinfo",
+		Claims:                map[string]string{"sub": "id", "email": "mail", "email_verified": "verified"},
+	})
+
+	ext, err := p.Exchange(context.Background(), "code-1", testVerifier, testRedirectURI, "")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if ext.Subject != "1234" || ext.Email != "bob@example.com" || !ext.EmailVerified {
+		t.Errorf("identity = %+v, want subject 1234 with a verified bob@example.com", *ext)
+	}
+}
@@ -17,11 +17,118 @@ func NewJWTManager(secret string) *JWTManager {
 
 func (j *JWTManager) GenerateAccessToken(user *domain.User, expiry time.Time) (string, error) {
 	claims := jwt.MapClaims{
-		"sub":  user.ID,
+		"sub":   user.ID,
 		"email": user.Email,
-		"role": user.Role,
-		"exp":  expiry.Unix(),
-		"iat":  time.Now().Unix(),
+		"role":  user.Role,
+		"exp":   expiry.Unix(),
+		"iat":   time.Now().Unix(),
+	}
+	// guests keep getting restricted tokens on refresh until they upgrade
+	if user.IsGuest {
+		claims["guest"] = true
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(j.secret))
+}
+
+// GenerateBusinessAccessToken issues an access token scoped to the business of
+// membership m, carrying the membership role next to the global one. For custom
+// roles business_role is the role name and business_role_id its id.
+func (j *JWTManager) GenerateBusinessAccessToken(user *domain.User, m *domain.Membership, expiry time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":           user.ID,
+		"email":         user.Email,
+		"role":          user.Role,
+		"business_id":   m.BusinessID,
+		"business_role": m.RoleName(),
+		"exp":           expiry.Unix(),
+		"iat":           time.Now().Unix(),
+	}
+	if m.CustomRoleID != "" {
+		claims["business_role_id"] = m.CustomRoleID
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(j.secret))
+}
+
+// GenerateDeviceAccessToken issues a business access token for a PIN login on
+// a shared device. device_id marks it as restricted.
+func (j *JWTManager) GenerateDeviceAccessToken(user *domain.User, m *domain.Membership, deviceID string,
+	expiry time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":           user.ID,
+		"email":         user.Email,
+		"role":          user.Role,
+		"business_id":   m.BusinessID,
+		"business_role": m.RoleName(),
+		"device_id":     deviceID,
+		"exp":           expiry.Unix(),
+		"iat":           time.Now().Unix(),
+	}
+	if m.CustomRoleID != "" {
+		claims["business_role_id"] = m.CustomRoleID
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(j.secret))
+}
+
+// GenerateOAuthAccessToken issues an access token to an OAuth client acting
+// for user, limited to scope.
+func (j *JWTManager) GenerateOAuthAccessToken(user *domain.User, clientID, scope string, expiry time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":       user.ID,
+		"email":     user.Email,
+		"role":      user.Role,
+		"client_id": clientID,
+		"scope":     scope,
+		"typ":       "oauth",
+		"exp":       expiry.Unix(),
+		"iat":       time.Now().Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(j.secret))
+}
+
+// GenerateServiceAccessToken issues a client credentials token. sub is the
+// backing service user, so handlers see the account like any other caller.
+func (j *JWTManager) GenerateServiceAccessToken(a *domain.ServiceAccount, scope string, expiry time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":       a.UserID,
+		"role":      domain.UserRoleService,
+		"client_id": a.ClientID,
+		"scope":     scope,
+		"typ":       "service",
+		"exp":       expiry.Unix(),
+		"iat":       time.Now().Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(j.secret))
+}
+
+// GenerateExchangedToken issues a token exchange result: the subject's
+// identity and business context, restricted to audience and scope. act is
+// the RFC 8693 actor claim and may be nil.
+func (j *JWTManager) GenerateExchangedToken(subject *domain.TokenClaims, clientID, audience, scope string,
+	act map[string]any, expiry time.Time) (string, error) {
+	claims := jwt.MapClaims{
+		"sub":       subject.UserID,
+		"email":     subject.Email,
+		"role":      subject.Role,
+		"aud":       audience,
+		"client_id": clientID,
+		"scope":     scope,
+		"exp":       expiry.Unix(),
+		"iat":       time.Now().Unix(),
+	}
+	if subject.BusinessID != "" {
+		claims["business_id"] = subject.BusinessID
+		claims["business_role"] = subject.BusinessRole
+		if subject.BusinessRoleID != "" {
+			claims["business_role_id"] = subject.BusinessRoleID
+		}
+	}
+	if act != nil {
+		claims["act"] = act
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(j.secret))
@@ -41,9 +148,46 @@ func (j *JWTManager) ValidateAccessToken(tokenStr string) (*domain.TokenClaims,
 	if !ok {
 		return nil, domain.ErrTokenMalformed
 	}
-	return &domain.TokenClaims{
-		UserID: claims["sub"].(string),
-		Email:  claims["email"].(string),
-		Role:   claims["role"].(string),
-	}, nil
-}
\ No newline at end of file
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, domain.ErrTokenMalformed
+	}
+	email, _ := claims["email"].(string)
+	role, _ := claims["role"].(string)
+	typ, _ := claims["typ"].(string)
+	businessID, _ := claims["business_id"].(string)
+	businessRole, _ := claims["business_role"].(string)
+	businessRoleID, _ := claims["business_role_id"].(string)
+	clientID, _ := claims["client_id"].(string)
+	scope, _ := claims["scope"].(string)
+	deviceID, _ := claims["device_id"].(string)
+	guest, _ := claims["guest"].(bool)
+	out := &domain.TokenClaims{
+		UserID:         sub,
+		Email:          email,
+		Role:           role,
+		BusinessID:     businessID,
+		BusinessRole:   businessRole,
+		BusinessRoleID: businessRoleID,
+		ClientID:       clientID,
+		Scope:          scope,
+		ServiceAccount: typ == "service",
+		// client tokens issued before typ was set carry only client_id
+		OAuth:    typ == "oauth" || (typ == "" && clientID != ""),
+		DeviceID: deviceID,
+		Guest:    guest,
+	}
+	if aud, err := claims.GetAudience(); err == nil && len(aud) > 0 {
+		out.Audience = aud[0]
+	}
+	if act, ok := claims["act"].(map[string]any); ok {
+		out.Act = act
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		out.IssuedAt = iat.Time
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		out.ExpiresAt = exp.Time
+	}
+	return out, nil
+}
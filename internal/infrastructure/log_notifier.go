@@ -0,0 +1,26 @@
+package infrastructure
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// LogNotifier writes notifications to the log. It stands in until a delivery
+// service (email/SMS) is wired up. Only the template and recipient are
+// logged: data carries secrets such as invitation tokens.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, msg domain.Notification) error {
+	logrus.WithFields(logrus.Fields{
+		"template": msg.Template,
+		"email":    msg.Email,
+		"phone":    msg.Phone,
+	}).Info("notification")
+	return nil
+}
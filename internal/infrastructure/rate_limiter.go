@@ -0,0 +1,44 @@
+package infrastructure
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter allows limit calls per key in each fixed window. Counts live
+// in memory, so every instance limits on its own.
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu     sync.Mutex
+	start  time.Time
+	counts map[string]int
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:  limit,
+		window: window,
+		counts: map[string]int{},
+	}
+}
+
+// Allow counts a call for key and reports whether it is within the limit.
+// All counts are dropped together when the window ends, which keeps memory
+// bounded by the number of keys seen in one window.
+func (l *RateLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.start) >= l.window {
+		l.start = now
+		clear(l.counts)
+	}
+	if l.counts[key] >= l.limit {
+		return false
+	}
+	l.counts[key]++
+	return true
+}
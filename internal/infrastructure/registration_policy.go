@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+// LoadRegistrationPolicy reads a JSON domain.RegistrationPolicy. An empty
+// path gives domain.DefaultRegistrationPolicy.
+func LoadRegistrationPolicy(path string) (*domain.RegistrationPolicy, error) {
+	if path == "" {
+		return domain.DefaultRegistrationPolicy(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read registration policy: %w", err)
+	}
+	var p domain.RegistrationPolicy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse registration policy: %w", err)
+	}
+	if p.DefaultRole == "" {
+		p.DefaultRole = domain.UserRoleClient
+	}
+	for role, rule := range p.Roles {
+		if !slices.Contains(domain.UserRoles, role) {
+			return nil, fmt.Errorf("registration policy: unknown role %q", role)
+		}
+		if role == domain.UserRoleAdmin {
+			return nil, fmt.Errorf("registration policy: %s cannot be self-registered", role)
+		}
+		switch rule.Mode {
+		case domain.RegistrationOpen, domain.RegistrationApproval, domain.RegistrationInvite:
+		default:
+			return nil, fmt.Errorf("registration policy: role %q has unknown mode %q", role, rule.Mode)
+		}
+		for i, d := range rule.EmailDomains {
+			rule.EmailDomains[i] = strings.ToLower(strings.TrimPrefix(d, "@"))
+		}
+	}
+	return &p, nil
+}
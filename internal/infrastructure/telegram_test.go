@@ -0,0 +1,97 @@
+package infrastructure
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+const testBotToken = "123456:test-bot-token"
+
+// signTelegram signs data the way the Login Widget does.
+func signTelegram(botToken string, data map[string]string) map[string]string {
+	lines := make([]string, 0, len(data))
+	for k, v := range data {
+		lines = append(lines, k+"="+v)
+	}
+	sort.Strings(lines)
+	secret := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(strings.Join(lines, "\n")))
+
+	signed := map[string]string{"hash": hex.EncodeToString(mac.Sum(nil))}
+	for k, v := range data {
+		signed[k] = v
+	}
+	return signed
+}
+
+func TestTelegramAuthVerifier(t *testing.T) {
+	now := time.Unix(1_760_000_000, 0)
+	payload := func(authDate time.Time) map[string]string {
+		return map[string]string{
+			"id":         "777",
+			"first_name": "Ann",
+			"last_name":  "Lee",
+			"username":   "ann",
+			"auth_date":  strconv.FormatInt(authDate.Unix(), 10),
+		}
+	}
+	v := NewTelegramAuthVerifier(testBotToken, 10*time.Minute)
+
+	t.Run("valid", func(t *testing.T) {
+		ext, err := v.Verify(signTelegram(testBotToken, payload(now.Add(-time.Minute))), now)
+		if err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+		if ext.ProviderID != TelegramProviderID || ext.Subject != "777" || ext.FirstName != "Ann" || ext.LastName != "Lee" {
+			t.Errorf("identity = %+v", *ext)
+		}
+	})
+
+	tests := []struct {
+		name string
+		data func() map[string]string
+	}{
+		{"wrong bot token", func() map[string]string {
+			return signTelegram("654321:other-bot", payload(now))
+		}},
+		{"tampered field", func() map[string]string {
+			d := signTelegram(testBotToken, payload(now))
+			d["id"] = "778"
+			return d
+		}},
+		{"added field", func() map[string]string {
+			d := signTelegram(testBotToken, payload(now))
+			d["photo_url"] = "https://example.com/a.png"
+			return d
+		}},
+		{"missing hash", func() map[string]string {
+			d := signTelegram(testBotToken, payload(now))
+			delete(d, "hash")
+			return d
+		}},
+		{"too old", func() map[string]string {
+			return signTelegram(testBotToken, payload(now.Add(-11*time.Minute)))
+		}},
+		{"from the future", func() map[string]string {
+			return signTelegram(testBotToken, payload(now.Add(2*time.Minute)))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(tt.data(), now)
+			if !errors.Is(err, domain.ErrExternalLoginFailed) {
+				t.Fatalf("Verify error = %v, want ErrExternalLoginFailed", err)
+			}
+		})
+	}
+}
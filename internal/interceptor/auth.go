@@ -2,8 +2,10 @@ package interceptor
 
 import (
 	"context"
+	"slices"
 	"strings"
 
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
 	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -13,13 +15,80 @@ import (
 
 type ContextKey string
 
-const UserIDKey ContextKey = "user_id"
+const (
+	UserIDKey       ContextKey = "user_id"
+	BusinessIDKey   ContextKey = "business_id"
+	BusinessRoleKey ContextKey = "business_role"
+	// ServiceAccountKey holds the client_id when the caller is a service account
+	ServiceAccountKey ContextKey = "service_account"
+)
+
+// serviceMethods lists the RPCs service account tokens may call and the
+// scope each one needs. Everything else is reserved for people.
+var serviceMethods = map[string]string{
+	"/identity.Identity/ValidateToken":        domain.ScopeTokensValidate,
+	"/identity.Identity/CheckPermission":      domain.ScopePermissionsCheck,
+	"/identity.Identity/BatchCheckPermission": domain.ScopePermissionsCheck,
+	"/identity.Identity/RegisterPermissions":  domain.ScopePermissionsRegister,
+	"/identity.Identity/Check":                domain.ScopeRelationsRead,
+	"/identity.Identity/ListObjects":          domain.ScopeRelationsRead,
+	"/identity.Identity/ListSubjects":         domain.ScopeRelationsRead,
+	"/identity.Identity/WriteRelations":       domain.ScopeRelationsWrite,
+	"/identity.Identity/ExchangeToken":        domain.ScopeTokensExchange,
+	"/identity.Identity/ResolveUserID":        domain.ScopeUsersRead,
+	"/identity.Identity/ListUserEvents":       domain.ScopeUsersRead,
+}
+
+// oauthMethods lists the RPCs an OAuth client may call for the user it was
+// authorized by and the scope each one needs. Client tokens never reach
+// session, device or account management.
+var oauthMethods = map[string]string{
+	"/identity.Identity/GetMe": domain.ScopeProfile,
+}
+
+// deviceMethods lists the RPCs a PIN login token from a shared device may
+// call. Account, session and business administration needs a full sign-in.
+var deviceMethods = map[string]struct{}{
+	"/identity.Identity/GetMe":                {},
+	"/identity.Identity/SetDevicePin":         {},
+	"/identity.Identity/CheckPermission":      {},
+	"/identity.Identity/BatchCheckPermission": {},
+	"/identity.Identity/Check":                {},
+	"/identity.Identity/ListObjects":          {},
+}
+
+// guestMethods lists the RPCs a guest token may call: enough to keep the
+// session going and to upgrade to a full account.
+var guestMethods = map[string]struct{}{
+	"/identity.Identity/GetMe":        {},
+	"/identity.Identity/RefreshToken": {},
+	"/identity.Identity/Logout":       {},
+	"/identity.Identity/UpgradeGuest": {},
+}
 
 func Auth(jwtSecret string) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 		public := map[string]struct{}{
-			"/identity.Identity/Register": {},
-			"/identity.Identity/Login":     {},
+			"/identity.Identity/Register":         {},
+			"/identity.Identity/Login":            {},
+			"/identity.Identity/AcceptInvitation": {},
+			// the web client authenticates with the poll token it was given
+			"/identity.Identity/StartQRLogin": {},
+			"/identity.Identity/PollQRLogin":  {},
+			// authenticated with the shared device credential
+			"/identity.Identity/ListDeviceUsers": {},
+			"/identity.Identity/PinLogin":        {},
+			// sign-in through an external identity provider
+			"/identity.Identity/ListIdentityProviders":  {},
+			"/identity.Identity/StartFederatedLogin":    {},
+			"/identity.Identity/CompleteFederatedLogin": {},
+			"/identity.Identity/TelegramLogin":          {},
+			// guests start without credentials and return with a device secret
+			"/identity.Identity/CreateGuest": {},
+			"/identity.Identity/GuestLogin":  {},
+			// authenticated with client credentials in the request
+			"/identity.Identity/IntrospectToken": {},
+			"/identity.Identity/RevokeToken":     {},
 		}
 
 		if _, ok := public[info.FullMethod]; ok {
@@ -37,8 +106,46 @@ func Auth(jwtSecret string) grpc.UnaryServerInterceptor {
 		if err != nil {
 			return nil, status.Error(codes.Unauthenticated, err.Error())
 		}
+		// Exchanged tokens are minted for a downstream service, not for us.
+		if claims.Audience != "" {
+			return nil, status.Error(codes.Unauthenticated, "token is restricted to another audience")
+		}
+
+		if claims.ServiceAccount {
+			scope, ok := serviceMethods[info.FullMethod]
+			if !ok {
+				return nil, status.Error(codes.PermissionDenied, "method is not available to service accounts")
+			}
+			if !slices.Contains(domain.ParseScope(claims.Scope), scope) {
+				return nil, status.Errorf(codes.PermissionDenied, "token lacks the %s scope", scope)
+			}
+			ctx = context.WithValue(ctx, ServiceAccountKey, claims.ClientID)
+		}
+		if claims.OAuth {
+			scope, ok := oauthMethods[info.FullMethod]
+			if !ok {
+				return nil, status.Error(codes.PermissionDenied, "method is not available to OAuth clients")
+			}
+			if !slices.Contains(domain.ParseScope(claims.Scope), scope) {
+				return nil, status.Errorf(codes.PermissionDenied, "token lacks the %s scope", scope)
+			}
+		}
+		if claims.DeviceID != "" {
+			if _, ok := deviceMethods[info.FullMethod]; !ok {
+				return nil, status.Error(codes.PermissionDenied, "method is not available to shared device sign-ins")
+			}
+		}
+		if claims.Guest {
+			if _, ok := guestMethods[info.FullMethod]; !ok {
+				return nil, status.Error(codes.PermissionDenied, "method is not available to guests")
+			}
+		}
 
 		ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
+		if claims.BusinessID != "" {
+			ctx = context.WithValue(ctx, BusinessIDKey, claims.BusinessID)
+			ctx = context.WithValue(ctx, BusinessRoleKey, claims.BusinessRole)
+		}
 		return handler(ctx, req)
 	}
 }
@@ -0,0 +1,100 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ClientInfoKey holds the domain.ClientInfo of the caller
+const ClientInfoKey ContextKey = "client_info"
+
+const maxLocationLength = 128
+
+// ParseTrustedProxies reads a comma separated list of CIDRs or single
+// addresses.
+func ParseTrustedProxies(s string) ([]netip.Prefix, error) {
+	var out []netip.Prefix
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if p, err := netip.ParsePrefix(part); err == nil {
+			out = append(out, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", part)
+		}
+		out = append(out, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return out, nil
+}
+
+// ClientInfo records where each request comes from. x-forwarded-for and the
+// location header are believed only on connections from trustedProxies;
+// anyone else could put whatever they like in them. The client is the
+// nearest x-forwarded-for hop that is not itself a trusted proxy.
+func ClientInfo(trustedProxies []netip.Prefix, locationHeader string) grpc.UnaryServerInterceptor {
+	locationHeader = strings.ToLower(locationHeader)
+	trusted := func(addr netip.Addr) bool {
+		for _, p := range trustedProxies {
+			if p.Contains(addr.Unmap()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var client domain.ClientInfo
+		var peerAddr netip.Addr
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			client.IPAddress = p.Addr.String()
+			if host, _, err := net.SplitHostPort(client.IPAddress); err == nil {
+				client.IPAddress = host
+			}
+			peerAddr, _ = netip.ParseAddr(client.IPAddress)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			client.UserAgent = ua[0]
+		}
+		if peerAddr.IsValid() && trusted(peerAddr) {
+			var hops []string
+			for _, v := range md.Get("x-forwarded-for") {
+				hops = append(hops, strings.Split(v, ",")...)
+			}
+			for i := len(hops) - 1; i >= 0; i-- {
+				addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+				if err != nil {
+					break
+				}
+				client.IPAddress = addr.Unmap().String()
+				if !trusted(addr) {
+					break
+				}
+			}
+			if locationHeader != "" {
+				if loc := md.Get(locationHeader); len(loc) > 0 {
+					client.Location = strings.TrimSpace(loc[0])
+					if len(client.Location) > maxLocationLength {
+						client.Location = client.Location[:maxLocationLength]
+					}
+				}
+			}
+		}
+
+		return handler(context.WithValue(ctx, ClientInfoKey, client), req)
+	}
+}
@@ -0,0 +1,163 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// Input is the request context a condition is evaluated against. Conditions
+// see it as the variables
+//
+//	request.time        timestamp
+//	request.ip          string, empty when the caller did not pass one
+//	subject.id          string
+//	subject.role        the role whose grant is being checked
+//	subject.claims      map(string, dyn) of the subject token claims
+//	resource.attributes map(string, string) supplied by the caller
+//	business.id         string
+//
+// plus ipInRange(ip, cidr), so an IP allowlist reads
+// ipInRange(request.ip, "10.0.0.0/8").
+type Input struct {
+	Time               time.Time
+	IP                 string
+	SubjectID          string
+	SubjectRole        string
+	Claims             map[string]any
+	ResourceAttributes map[string]string
+	BusinessID         string
+}
+
+func (in Input) activation() map[string]any {
+	claims := in.Claims
+	if claims == nil {
+		claims = map[string]any{}
+	}
+	attrs := in.ResourceAttributes
+	if attrs == nil {
+		attrs = map[string]string{}
+	}
+	return map[string]any{
+		"request.time":        in.Time,
+		"request.ip":          in.IP,
+		"subject.id":          in.SubjectID,
+		"subject.role":        in.SubjectRole,
+		"subject.claims":      claims,
+		"resource.attributes": attrs,
+		"business.id":         in.BusinessID,
+	}
+}
+
+const (
+	// costLimit bounds the work of one evaluation; a condition that loops
+	// over large claims or attributes fails instead of stalling the check
+	costLimit = 100_000
+	// interruptCheckFrequency is how many comprehension iterations run
+	// between checks of the context
+	interruptCheckFrequency = 100
+	// maxCachedPrograms bounds the program cache; it is emptied when full
+	maxCachedPrograms = 1024
+)
+
+// Evaluator compiles CEL conditions and caches the programs by source, since
+// the same handful of conditions is evaluated on every permission check.
+type Evaluator struct {
+	env *cel.Env
+
+	mu       sync.Mutex
+	programs map[string]cel.Program
+}
+
+func NewEvaluator() (*Evaluator, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("request.time", cel.TimestampType),
+		cel.Variable("request.ip", cel.StringType),
+		cel.Variable("subject.id", cel.StringType),
+		cel.Variable("subject.role", cel.StringType),
+		cel.Variable("subject.claims", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("resource.attributes", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("business.id", cel.StringType),
+		cel.Function("ipInRange",
+			cel.Overload("ip_in_range_string_string", []*cel.Type{cel.StringType, cel.StringType}, cel.BoolType,
+				cel.BinaryBinding(ipInRange))),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("policy: build cel environment: %w", err)
+	}
+	return &Evaluator{env: env, programs: map[string]cel.Program{}}, nil
+}
+
+// Compile checks that expr is a valid condition returning a bool.
+func (e *Evaluator) Compile(expr string) error {
+	_, err := e.program(expr)
+	return err
+}
+
+// Eval reports whether the condition holds for in. It stops with an error
+// when ctx is done or the condition exceeds its cost limit.
+func (e *Evaluator) Eval(ctx context.Context, expr string, in Input) (bool, error) {
+	prg, err := e.program(expr)
+	if err != nil {
+		return false, err
+	}
+	out, _, err := prg.ContextEval(ctx, in.activation())
+	if err != nil {
+		return false, fmt.Errorf("policy: evaluate condition: %w", err)
+	}
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("policy: condition returned %s, want bool", out.Type())
+	}
+	return result, nil
+}
+
+func (e *Evaluator) program(expr string) (cel.Program, error) {
+	e.mu.Lock()
+	prg, ok := e.programs[expr]
+	e.mu.Unlock()
+	if ok {
+		return prg, nil
+	}
+	ast, iss := e.env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("policy: %w", iss.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("policy: condition must return bool, got %s", ast.OutputType())
+	}
+	prg, err := e.env.Program(ast,
+		cel.CostLimit(costLimit),
+		cel.InterruptCheckFrequency(interruptCheckFrequency),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("policy: %w", err)
+	}
+
+	e.mu.Lock()
+	if len(e.programs) >= maxCachedPrograms {
+		clear(e.programs)
+	}
+	e.programs[expr] = prg
+	e.mu.Unlock()
+	return prg, nil
+}
+
+func ipInRange(ip, cidr ref.Val) ref.Val {
+	addr, err := netip.ParseAddr(fmt.Sprint(ip.Value()))
+	if err != nil {
+		// an absent or malformed address is never inside a range
+		return types.False
+	}
+	prefix, err := netip.ParsePrefix(fmt.Sprint(cidr.Value()))
+	if err != nil {
+		return types.NewErr("ipInRange: invalid cidr %q", cidr.Value())
+	}
+	return types.Bool(prefix.Contains(addr.Unmap()))
+}
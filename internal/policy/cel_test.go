@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvaluatorEval(t *testing.T) {
+	e, err := NewEvaluator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := Input{
+		Time:               time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC),
+		IP:                 "10.1.2.3",
+		SubjectID:          "u1",
+		SubjectRole:        "manager",
+		Claims:             map[string]any{"mfa": true},
+		ResourceAttributes: map[string]string{"owner": "u1"},
+		BusinessID:         "b1",
+	}
+	tests := []struct {
+		expr string
+		in   Input
+		want bool
+	}{
+		{`ipInRange(request.ip, "10.0.0.0/8")`, in, true},
+		{`ipInRange(request.ip, "192.168.0.0/16")`, in, false},
+		{`ipInRange(request.ip, "10.0.0.0/8")`, Input{}, false},
+		{`resource.attributes["owner"] == subject.id`, in, true},
+		{`subject.claims.mfa == true && subject.role == "manager"`, in, true},
+		{`request.time < timestamp("2025-01-01T00:00:00Z")`, in, false},
+		{`business.id == "b2"`, in, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := e.Eval(context.Background(), tt.expr, tt.in)
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluatorCompileRejects(t *testing.T) {
+	e, err := NewEvaluator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expr := range []string{
+		`subject.id`,
+		`1 + 1`,
+		`unknown.variable == "x"`,
+		`ipInRange(request.ip)`,
+		`subject.id ==`,
+	} {
+		if err := e.Compile(expr); err == nil {
+			t.Errorf("Compile(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestEvaluatorInvalidCIDR(t *testing.T) {
+	e, err := NewEvaluator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.Eval(context.Background(), `ipInRange(request.ip, "10.0.0.0/33")`, Input{IP: "10.0.0.1"})
+	if err == nil || !strings.Contains(err.Error(), "invalid cidr") {
+		t.Fatalf("Eval error = %v, want invalid cidr", err)
+	}
+}
+
+// expensive nests six comprehensions over ten elements: a million
+// iterations, well past costLimit.
+const expensive = `[` + `1,1,1,1,1,1,1,1,1,1` + `].map(a, [1,1,1,1,1,1,1,1,1,1].map(b,
+	[1,1,1,1,1,1,1,1,1,1].map(c, [1,1,1,1,1,1,1,1,1,1].map(d,
+	[1,1,1,1,1,1,1,1,1,1].map(e, [1,1,1,1,1,1,1,1,1,1].map(f, a+b+c+d+e+f)))))).size() > 0`
+
+func TestEvaluatorCostLimit(t *testing.T) {
+	e, err := NewEvaluator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.Eval(context.Background(), expensive, Input{})
+	if err == nil || !strings.Contains(err.Error(), "cost limit") {
+		t.Fatalf("Eval error = %v, want the cost limit exceeded", err)
+	}
+}
+
+func TestEvaluatorCancelledContext(t *testing.T) {
+	e, err := NewEvaluator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the context is checked every interruptCheckFrequency iterations
+	attrs := map[string]string{}
+	for i := 0; i < 2*interruptCheckFrequency; i++ {
+		attrs[strconv.Itoa(i)] = "v"
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = e.Eval(ctx, `resource.attributes.all(k, k != "")`, Input{ResourceAttributes: attrs})
+	if err == nil {
+		t.Fatal("Eval with a cancelled context succeeded, want an error")
+	}
+}
+
+func TestEvaluatorCacheIsBounded(t *testing.T) {
+	e, err := NewEvaluator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i <= maxCachedPrograms; i++ {
+		expr := `subject.id == "` + strings.Repeat("x", i) + `"`
+		if err := e.Compile(expr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(e.programs); n > maxCachedPrograms {
+		t.Errorf("cache holds %d programs, want at most %d", n, maxCachedPrograms)
+	}
+}
@@ -0,0 +1,340 @@
+package rebac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+// maxDepth bounds the recursion through subject sets and rewrites, so a
+// cyclic schema or tuple graph ends in an error instead of a stack overflow.
+const maxDepth = 32
+
+var ErrMaxDepth = errors.New("relation graph too deep")
+
+// TupleReader is the read side of the tuple store used by the engine.
+type TupleReader interface {
+	// ReadSubjects returns the subjects stored directly in object#relation.
+	ReadSubjects(ctx context.Context, object domain.ObjectRef, relation string) ([]domain.SubjectRef, error)
+	// ReadObjects returns the objects of the tuples whose subject is subject,
+	// alone or as a subject set with any relation.
+	ReadObjects(ctx context.Context, subject domain.ObjectRef) ([]domain.ObjectRef, error)
+}
+
+type Engine struct {
+	schema *Schema
+	reader TupleReader
+}
+
+func NewEngine(schema *Schema, reader TupleReader) *Engine {
+	return &Engine{
+		schema: schema,
+		reader: reader,
+	}
+}
+
+func (e *Engine) Schema() *Schema {
+	return e.schema
+}
+
+// Check reports whether subject holds relation (or permission) on object.
+// When it does, path lists the object#relation steps that granted it, from
+// object#relation down to the one storing subject, e.g.
+// [calendar:c1#manage calendar:c1#business business:b1#manage business:b1#owner].
+func (e *Engine) Check(ctx context.Context, object domain.ObjectRef, relation string,
+	subject domain.SubjectRef) (allowed bool, path []string, err error) {
+	if _, ok := e.schema.Relation(object.Type, relation); !ok {
+		return false, nil, fmt.Errorf("%w: unknown relation %s#%s", domain.ErrSchemaViolation, object.Type, relation)
+	}
+	path, err = e.check(ctx, object, relation, subject, 0)
+	return path != nil, path, err
+}
+
+// check returns the path that grants subject relation on object, nil when
+// none does.
+func (e *Engine) check(ctx context.Context, object domain.ObjectRef, relation string, subject domain.SubjectRef, depth int) ([]string, error) {
+	if depth > maxDepth {
+		return nil, ErrMaxDepth
+	}
+	step := object.String() + "#" + relation
+	// a subject set trivially contains itself
+	if subject.Relation == relation && subject.Object() == object {
+		return []string{step}, nil
+	}
+
+	r, ok := e.schema.Relation(object.Type, relation)
+	if !ok {
+		return nil, nil
+	}
+	if r.IsPermission() {
+		path, err := e.checkExpr(ctx, object, r.Expr, subject, depth+1)
+		if err != nil || path == nil {
+			return nil, err
+		}
+		return append([]string{step}, path...), nil
+	}
+
+	subjects, err := e.reader.ReadSubjects(ctx, object, relation)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range subjects {
+		if s == subject {
+			return []string{step}, nil
+		}
+	}
+	for _, s := range subjects {
+		if s.Relation == "" {
+			continue
+		}
+		path, err := e.check(ctx, s.Object(), s.Relation, subject, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if path != nil {
+			return append([]string{step}, path...), nil
+		}
+	}
+	return nil, nil
+}
+
+// checkExpr returns the path through expr that grants subject access. For
+// intersections and exclusions that is the path through the left operand.
+func (e *Engine) checkExpr(ctx context.Context, object domain.ObjectRef, expr Expr, subject domain.SubjectRef, depth int) ([]string, error) {
+	switch x := expr.(type) {
+	case ComputedUserset:
+		return e.check(ctx, object, x.Relation, subject, depth)
+
+	case TupleToUserset:
+		targets, err := e.reader.ReadSubjects(ctx, object, x.Tupleset)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range targets {
+			path, err := e.check(ctx, t.Object(), x.Computed, subject, depth)
+			if err != nil {
+				return nil, err
+			}
+			if path != nil {
+				return append([]string{object.String() + "#" + x.Tupleset}, path...), nil
+			}
+		}
+		return nil, nil
+
+	case SetOperation:
+		left, err := e.checkExpr(ctx, object, x.Left, subject, depth)
+		if err != nil {
+			return nil, err
+		}
+		switch x.Op {
+		case OpUnion:
+			if left != nil {
+				return left, nil
+			}
+			return e.checkExpr(ctx, object, x.Right, subject, depth)
+		case OpIntersection:
+			if left == nil {
+				return nil, nil
+			}
+			right, err := e.checkExpr(ctx, object, x.Right, subject, depth)
+			if err != nil || right == nil {
+				return nil, err
+			}
+			return left, nil
+		case OpExclusion:
+			if left == nil {
+				return nil, nil
+			}
+			right, err := e.checkExpr(ctx, object, x.Right, subject, depth)
+			if err != nil || right != nil {
+				return nil, err
+			}
+			return left, nil
+		}
+	}
+	return nil, fmt.Errorf("rebac: unsupported expression %T", expr)
+}
+
+// ListObjects returns the ids of objects of objectType on which subject holds
+// relation. Every such object is linked to the subject by a chain of tuples,
+// so it walks the tuples backwards from the subject and checks only the
+// objects of objectType it reaches.
+func (e *Engine) ListObjects(ctx context.Context, objectType, relation string, subject domain.SubjectRef) ([]string, error) {
+	if _, ok := e.schema.Relation(objectType, relation); !ok {
+		return nil, fmt.Errorf("%w: unknown relation %s#%s", domain.ErrSchemaViolation, objectType, relation)
+	}
+	candidates, err := e.reachable(ctx, subject.Object())
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for _, object := range candidates {
+		if object.Type != objectType {
+			continue
+		}
+		path, err := e.check(ctx, object, relation, subject, 0)
+		if err != nil {
+			return nil, err
+		}
+		if path != nil {
+			out = append(out, object.ID)
+		}
+	}
+	return out, nil
+}
+
+// reachable returns start and the objects reached from it by following
+// tuples from subject to object, at most maxDepth steps deep.
+func (e *Engine) reachable(ctx context.Context, start domain.ObjectRef) ([]domain.ObjectRef, error) {
+	seen := map[domain.ObjectRef]struct{}{start: {}}
+	out := []domain.ObjectRef{start}
+	frontier := []domain.ObjectRef{start}
+	for depth := 0; depth < maxDepth && len(frontier) > 0; depth++ {
+		var next []domain.ObjectRef
+		for _, s := range frontier {
+			objects, err := e.reader.ReadObjects(ctx, s)
+			if err != nil {
+				return nil, err
+			}
+			for _, o := range objects {
+				if _, ok := seen[o]; ok {
+					continue
+				}
+				seen[o] = struct{}{}
+				out = append(out, o)
+				next = append(next, o)
+			}
+		}
+		frontier = next
+	}
+	return out, nil
+}
+
+// ListSubjects expands object#relation into the ids of the individual
+// subjects of subjectType it contains.
+func (e *Engine) ListSubjects(ctx context.Context, object domain.ObjectRef, relation, subjectType string) ([]string, error) {
+	if _, ok := e.schema.Relation(object.Type, relation); !ok {
+		return nil, fmt.Errorf("%w: unknown relation %s#%s", domain.ErrSchemaViolation, object.Type, relation)
+	}
+	set, err := e.expand(ctx, object, relation, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for s := range set {
+		if s.Type == subjectType && s.Relation == "" {
+			out = append(out, s.ID)
+		}
+	}
+	return out, nil
+}
+
+type subjectSet map[domain.SubjectRef]struct{}
+
+func (e *Engine) expand(ctx context.Context, object domain.ObjectRef, relation string, depth int) (subjectSet, error) {
+	if depth > maxDepth {
+		return nil, ErrMaxDepth
+	}
+	r, ok := e.schema.Relation(object.Type, relation)
+	if !ok {
+		return subjectSet{}, nil
+	}
+	if r.IsPermission() {
+		return e.expandExpr(ctx, object, r.Expr, depth+1)
+	}
+
+	subjects, err := e.reader.ReadSubjects(ctx, object, relation)
+	if err != nil {
+		return nil, err
+	}
+	set := subjectSet{}
+	for _, s := range subjects {
+		if s.Relation == "" {
+			set[s] = struct{}{}
+			continue
+		}
+		nested, err := e.expand(ctx, s.Object(), s.Relation, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		for n := range nested {
+			set[n] = struct{}{}
+		}
+	}
+	return set, nil
+}
+
+func (e *Engine) expandExpr(ctx context.Context, object domain.ObjectRef, expr Expr, depth int) (subjectSet, error) {
+	switch x := expr.(type) {
+	case ComputedUserset:
+		return e.expand(ctx, object, x.Relation, depth)
+
+	case TupleToUserset:
+		targets, err := e.reader.ReadSubjects(ctx, object, x.Tupleset)
+		if err != nil {
+			return nil, err
+		}
+		set := subjectSet{}
+		for _, t := range targets {
+			nested, err := e.expand(ctx, t.Object(), x.Computed, depth)
+			if err != nil {
+				return nil, err
+			}
+			for n := range nested {
+				set[n] = struct{}{}
+			}
+		}
+		return set, nil
+
+	case SetOperation:
+		left, err := e.expandExpr(ctx, object, x.Left, depth)
+		if err != nil {
+			return nil, err
+		}
+		right, err := e.expandExpr(ctx, object, x.Right, depth)
+		if err != nil {
+			return nil, err
+		}
+		switch x.Op {
+		case OpUnion:
+			for s := range right {
+				left[s] = struct{}{}
+			}
+		case OpIntersection:
+			for s := range left {
+				if _, ok := right[s]; !ok {
+					delete(left, s)
+				}
+			}
+		case OpExclusion:
+			for s := range right {
+				delete(left, s)
+			}
+		}
+		return left, nil
+	}
+	return nil, fmt.Errorf("rebac: unsupported expression %T", expr)
+}
+
+// ValidateTuple checks that t can be stored: the relation must be a stored
+// relation of the object type and accept the subject's type.
+func (s *Schema) ValidateTuple(t domain.RelationTuple) error {
+	r, ok := s.Relation(t.Object.Type, t.Relation)
+	if !ok {
+		return fmt.Errorf("%w: unknown relation %s#%s", domain.ErrSchemaViolation, t.Object.Type, t.Relation)
+	}
+	if r.IsPermission() {
+		return fmt.Errorf("%w: %s#%s is a permission and cannot be written", domain.ErrSchemaViolation, t.Object.Type, t.Relation)
+	}
+	for _, st := range r.Subjects {
+		if st.Type == t.Subject.Type && st.Relation == t.Subject.Relation {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s#%s does not accept %s subjects", domain.ErrSchemaViolation,
+		t.Object.Type, t.Relation, SubjectType{Type: t.Subject.Type, Relation: t.Subject.Relation})
+}
@@ -0,0 +1,239 @@
+package rebac
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+// memTuples is an in-memory TupleReader.
+type memTuples []domain.RelationTuple
+
+func (m memTuples) ReadSubjects(_ context.Context, object domain.ObjectRef, relation string) ([]domain.SubjectRef, error) {
+	var out []domain.SubjectRef
+	for _, t := range m {
+		if t.Object == object && t.Relation == relation {
+			out = append(out, t.Subject)
+		}
+	}
+	return out, nil
+}
+
+func (m memTuples) ReadObjects(_ context.Context, subject domain.ObjectRef) ([]domain.ObjectRef, error) {
+	var out []domain.ObjectRef
+	for _, t := range m {
+		if t.Subject.Object() == subject && !slices.Contains(out, t.Object) {
+			out = append(out, t.Object)
+		}
+	}
+	return out, nil
+}
+
+// tuples parses "object#relation@subject" specs.
+func tuples(t *testing.T, specs ...string) memTuples {
+	t.Helper()
+	var m memTuples
+	for _, s := range specs {
+		lhs, subject, _ := strings.Cut(s, "@")
+		object, relation, _ := strings.Cut(lhs, "#")
+		o, err := domain.ParseObjectRef(object)
+		if err != nil {
+			t.Fatalf("tuple %q: %v", s, err)
+		}
+		sub, err := domain.ParseSubjectRef(subject)
+		if err != nil {
+			t.Fatalf("tuple %q: %v", s, err)
+		}
+		m = append(m, domain.RelationTuple{Object: o, Relation: relation, Subject: sub})
+	}
+	return m
+}
+
+const testSchema = `
+definition user {}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition business {
+	relation owner: user
+	relation admin: user | group#member
+	relation suspended: user
+	relation mfa: user
+
+	permission manage = owner + admin
+	permission act = manage - suspended
+	permission sensitive = manage & mfa
+}
+
+definition calendar {
+	relation business: business
+	relation assistant: user
+
+	permission manage = assistant + business->manage
+}
+`
+
+func testEngine(t *testing.T, m memTuples) *Engine {
+	t.Helper()
+	schema, err := Parse(testSchema)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return NewEngine(schema, m)
+}
+
+func TestEngineCheck(t *testing.T) {
+	e := testEngine(t, tuples(t,
+		"business:b1#owner@user:alice",
+		"business:b1#admin@group:ops#member",
+		"group:ops#member@group:oncall#member",
+		"group:oncall#member@user:bob",
+		"business:b1#suspended@user:bob",
+		"business:b1#mfa@user:alice",
+		"calendar:c1#business@business:b1",
+		"calendar:c1#assistant@user:carol",
+		"calendar:c2#business@business:b2",
+	))
+	tests := []struct {
+		name     string
+		object   string
+		relation string
+		subject  string
+		want     bool
+	}{
+		{"direct relation", "business:b1", "owner", "user:alice", true},
+		{"union first branch", "business:b1", "manage", "user:alice", true},
+		{"union through nested subject sets", "business:b1", "manage", "user:bob", true},
+		{"union neither branch", "business:b1", "manage", "user:carol", false},
+		{"exclusion keeps", "business:b1", "act", "user:alice", true},
+		{"exclusion removes", "business:b1", "act", "user:bob", false},
+		{"intersection both", "business:b1", "sensitive", "user:alice", true},
+		{"intersection one side", "business:b1", "sensitive", "user:bob", false},
+		{"tuple to userset", "calendar:c1", "manage", "user:alice", true},
+		{"tuple to userset other business", "calendar:c2", "manage", "user:alice", false},
+		{"direct on calendar", "calendar:c1", "manage", "user:carol", true},
+		{"subject set contains itself", "group:ops", "member", "group:ops#member", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			object, err := domain.ParseObjectRef(tt.object)
+			if err != nil {
+				t.Fatal(err)
+			}
+			subject, err := domain.ParseSubjectRef(tt.subject)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _, err := e.Check(context.Background(), object, tt.relation, subject)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Check = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngineCheckPath(t *testing.T) {
+	e := testEngine(t, tuples(t,
+		"business:b1#admin@group:ops#member",
+		"group:ops#member@user:bob",
+		"calendar:c1#business@business:b1",
+	))
+	_, path, err := e.Check(context.Background(), domain.ObjectRef{Type: "calendar", ID: "c1"}, "manage",
+		domain.SubjectRef{Type: "user", ID: "bob"})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	want := []string{"calendar:c1#manage", "calendar:c1#business", "business:b1#manage",
+		"business:b1#admin", "group:ops#member"}
+	if !slices.Equal(path, want) {
+		t.Errorf("path = %v, want %v", path, want)
+	}
+}
+
+func TestEngineCheckUnknownRelation(t *testing.T) {
+	e := testEngine(t, nil)
+	_, _, err := e.Check(context.Background(), domain.ObjectRef{Type: "business", ID: "b1"}, "nope",
+		domain.SubjectRef{Type: "user", ID: "alice"})
+	if !errors.Is(err, domain.ErrSchemaViolation) {
+		t.Errorf("Check error = %v, want ErrSchemaViolation", err)
+	}
+}
+
+func TestEngineDepthLimit(t *testing.T) {
+	e := testEngine(t, tuples(t,
+		"group:a#member@group:b#member",
+		"group:b#member@group:a#member",
+	))
+	subject := domain.SubjectRef{Type: "user", ID: "alice"}
+	_, _, err := e.Check(context.Background(), domain.ObjectRef{Type: "group", ID: "a"}, "member", subject)
+	if !errors.Is(err, ErrMaxDepth) {
+		t.Errorf("Check error = %v, want ErrMaxDepth", err)
+	}
+	_, err = e.ListSubjects(context.Background(), domain.ObjectRef{Type: "group", ID: "a"}, "member", "user")
+	if !errors.Is(err, ErrMaxDepth) {
+		t.Errorf("ListSubjects error = %v, want ErrMaxDepth", err)
+	}
+}
+
+func TestEngineListObjects(t *testing.T) {
+	e := testEngine(t, tuples(t,
+		"business:b1#owner@user:alice",
+		"business:b2#admin@group:ops#member",
+		"group:ops#member@user:alice",
+		"business:b3#owner@user:bob",
+		"business:b2#suspended@user:alice",
+		"calendar:c1#business@business:b1",
+		"calendar:c2#business@business:b2",
+		"calendar:c3#business@business:b3",
+		"calendar:c4#assistant@user:alice",
+	))
+	alice := domain.SubjectRef{Type: "user", ID: "alice"}
+	tests := []struct {
+		objectType string
+		relation   string
+		want       []string
+	}{
+		{"business", "manage", []string{"b1", "b2"}},
+		{"business", "act", []string{"b1"}},
+		{"calendar", "manage", []string{"c1", "c2", "c4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.objectType+"#"+tt.relation, func(t *testing.T) {
+			got, err := e.ListObjects(context.Background(), tt.objectType, tt.relation, alice)
+			if err != nil {
+				t.Fatalf("ListObjects: %v", err)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ListObjects = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngineListSubjects(t *testing.T) {
+	e := testEngine(t, tuples(t,
+		"business:b1#owner@user:alice",
+		"business:b1#admin@group:ops#member",
+		"group:ops#member@user:bob",
+		"group:ops#member@user:carol",
+		"business:b1#suspended@user:carol",
+	))
+	got, err := e.ListSubjects(context.Background(), domain.ObjectRef{Type: "business", ID: "b1"}, "act", "user")
+	if err != nil {
+		t.Fatalf("ListSubjects: %v", err)
+	}
+	slices.Sort(got)
+	if want := []string{"alice", "bob"}; !slices.Equal(got, want) {
+		t.Errorf("ListSubjects = %v, want %v", got, want)
+	}
+}
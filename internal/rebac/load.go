@@ -0,0 +1,24 @@
+package rebac
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+)
+
+//go:embed default_schema.zed
+var defaultSchema string
+
+// LoadSchema parses the schema file at path, or the built-in default schema
+// when path is empty.
+func LoadSchema(path string) (*Schema, error) {
+	src := defaultSchema
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read schema: %w", err)
+		}
+		src = string(b)
+	}
+	return Parse(src)
+}
@@ -0,0 +1,402 @@
+package rebac
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Schema describes object types, the relations that can be written for them
+// and the permissions computed from those relations:
+//
+//	definition user {}
+//
+//	definition business {
+//	    relation owner: user
+//	    relation admin: user | business#owner
+//	    permission manage = owner + admin
+//	}
+//
+//	definition appointment {
+//	    relation business: business
+//	    relation master: user
+//	    permission edit = master + business->manage
+//	}
+//
+// Permissions combine relations with + (union), & (intersection) and
+// - (exclusion), evaluated left to right; parentheses group. rel->name follows
+// the objects stored in rel and evaluates name on each of them.
+type Schema struct {
+	Definitions map[string]*Definition
+}
+
+type Definition struct {
+	Name      string
+	Relations map[string]*Relation
+}
+
+// Relation is either a stored relation (Expr is nil) that accepts the listed
+// subject types, or a permission computed by Expr.
+type Relation struct {
+	Name     string
+	Subjects []SubjectType
+	Expr     Expr
+}
+
+func (r *Relation) IsPermission() bool {
+	return r.Expr != nil
+}
+
+// SubjectType is "user" or, with Relation set, a subject set such as
+// "business#owner".
+type SubjectType struct {
+	Type     string
+	Relation string
+}
+
+func (s SubjectType) String() string {
+	if s.Relation == "" {
+		return s.Type
+	}
+	return s.Type + "#" + s.Relation
+}
+
+type Expr interface {
+	expr()
+}
+
+// ComputedUserset evaluates another relation of the same object.
+type ComputedUserset struct {
+	Relation string
+}
+
+// TupleToUserset evaluates Computed on every object stored in Tupleset.
+type TupleToUserset struct {
+	Tupleset string
+	Computed string
+}
+
+type Operator byte
+
+const (
+	OpUnion        Operator = '+'
+	OpIntersection Operator = '&'
+	OpExclusion    Operator = '-'
+)
+
+type SetOperation struct {
+	Op          Operator
+	Left, Right Expr
+}
+
+func (ComputedUserset) expr() {}
+func (TupleToUserset) expr()  {}
+func (SetOperation) expr()    {}
+
+func (s *Schema) Relation(objectType, name string) (*Relation, bool) {
+	def, ok := s.Definitions[objectType]
+	if !ok {
+		return nil, false
+	}
+	r, ok := def.Relations[name]
+	return r, ok
+}
+
+// Parse reads a schema and checks that every reference in it resolves.
+func Parse(src string) (*Schema, error) {
+	toks, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	schema, err := p.schema()
+	if err != nil {
+		return nil, err
+	}
+	if err := schema.validate(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+type token struct {
+	text string
+	line int
+}
+
+func tokenize(src string) ([]token, error) {
+	var toks []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(c):
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "->"):
+			toks = append(toks, token{"->", line})
+			i += 2
+		case strings.ContainsRune("{}:|=+&-#()", c):
+			toks = append(toks, token{string(c), line})
+			i++
+		case isIdentRune(c):
+			start := i
+			for i < len(src) && isIdentRune(rune(src[i])) {
+				i++
+			}
+			toks = append(toks, token{src[start:i], line})
+		default:
+			return nil, fmt.Errorf("schema line %d: unexpected character %q", line, c)
+		}
+	}
+	return toks, nil
+}
+
+func isIdentRune(c rune) bool {
+	return c == '_' || c == '/' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.toks) {
+		return ""
+	}
+	return p.toks[p.pos].text
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	line := 0
+	if p.pos < len(p.toks) {
+		line = p.toks[p.pos].line
+	} else if len(p.toks) > 0 {
+		line = p.toks[len(p.toks)-1].line
+	}
+	return fmt.Errorf("schema line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) expect(text string) error {
+	if p.peek() != text {
+		return p.errorf("expected %q, got %q", text, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) ident() (string, error) {
+	t := p.peek()
+	if t == "" || !isIdentRune(rune(t[0])) {
+		return "", p.errorf("expected identifier, got %q", t)
+	}
+	p.pos++
+	return t, nil
+}
+
+func (p *parser) schema() (*Schema, error) {
+	s := &Schema{Definitions: map[string]*Definition{}}
+	for p.pos < len(p.toks) {
+		def, err := p.definition()
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := s.Definitions[def.Name]; dup {
+			return nil, fmt.Errorf("schema: definition %q declared twice", def.Name)
+		}
+		s.Definitions[def.Name] = def
+	}
+	return s, nil
+}
+
+func (p *parser) definition() (*Definition, error) {
+	if err := p.expect("definition"); err != nil {
+		return nil, err
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	def := &Definition{Name: name, Relations: map[string]*Relation{}}
+	for p.peek() != "}" {
+		var r *Relation
+		switch p.peek() {
+		case "relation":
+			r, err = p.relation()
+		case "permission":
+			r, err = p.permission()
+		default:
+			return nil, p.errorf("expected relation, permission or }, got %q", p.peek())
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := def.Relations[r.Name]; dup {
+			return nil, fmt.Errorf("schema: %s#%s declared twice", def.Name, r.Name)
+		}
+		def.Relations[r.Name] = r
+	}
+	p.pos++
+	return def, nil
+}
+
+func (p *parser) relation() (*Relation, error) {
+	p.pos++
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+
+	r := &Relation{Name: name}
+	for {
+		st, err := p.subjectType()
+		if err != nil {
+			return nil, err
+		}
+		r.Subjects = append(r.Subjects, st)
+		if p.peek() != "|" {
+			return r, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) subjectType() (SubjectType, error) {
+	typ, err := p.ident()
+	if err != nil {
+		return SubjectType{}, err
+	}
+	st := SubjectType{Type: typ}
+	if p.peek() == "#" {
+		p.pos++
+		if st.Relation, err = p.ident(); err != nil {
+			return SubjectType{}, err
+		}
+	}
+	return st, nil
+}
+
+func (p *parser) permission() (*Relation, error) {
+	p.pos++
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	expr, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	return &Relation{Name: name, Expr: expr}, nil
+}
+
+func (p *parser) expr() (Expr, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if op != "+" && op != "&" && op != "-" {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = SetOperation{Op: Operator(op[0]), Left: left, Right: right}
+	}
+}
+
+func (p *parser) term() (Expr, error) {
+	if p.peek() == "(" {
+		p.pos++
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	}
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != "->" {
+		return ComputedUserset{Relation: name}, nil
+	}
+	p.pos++
+	computed, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	return TupleToUserset{Tupleset: name, Computed: computed}, nil
+}
+
+func (s *Schema) validate() error {
+	for _, def := range s.Definitions {
+		for _, r := range def.Relations {
+			for _, st := range r.Subjects {
+				if _, ok := s.Definitions[st.Type]; !ok {
+					return fmt.Errorf("schema: %s#%s: unknown type %q", def.Name, r.Name, st.Type)
+				}
+				if st.Relation != "" {
+					if _, ok := s.Relation(st.Type, st.Relation); !ok {
+						return fmt.Errorf("schema: %s#%s: unknown relation %q", def.Name, r.Name, st)
+					}
+				}
+			}
+			if r.Expr != nil {
+				if err := s.validateExpr(def, r.Name, r.Expr); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateExpr(def *Definition, name string, e Expr) error {
+	switch e := e.(type) {
+	case ComputedUserset:
+		if _, ok := def.Relations[e.Relation]; !ok {
+			return fmt.Errorf("schema: %s#%s: unknown relation %q", def.Name, name, e.Relation)
+		}
+	case TupleToUserset:
+		ts, ok := def.Relations[e.Tupleset]
+		if !ok || ts.IsPermission() {
+			return fmt.Errorf("schema: %s#%s: %q must be a relation", def.Name, name, e.Tupleset)
+		}
+		found := false
+		for _, st := range ts.Subjects {
+			if _, ok := s.Relation(st.Type, e.Computed); ok {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("schema: %s#%s: no type in %q has %q", def.Name, name, e.Tupleset, e.Computed)
+		}
+	case SetOperation:
+		if err := s.validateExpr(def, name, e.Left); err != nil {
+			return err
+		}
+		return s.validateExpr(def, name, e.Right)
+	}
+	return nil
+}
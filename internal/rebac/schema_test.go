@@ -0,0 +1,101 @@
+package rebac
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	s, err := Parse(`
+		definition user {}
+
+		// comments are skipped
+		definition team {
+			relation member: user | team#member
+		}
+
+		definition doc {
+			relation team: team
+			relation owner: user
+			relation banned: user
+			permission edit = owner + team->member - banned
+			permission view = (owner & banned) + edit
+		}
+	`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	member, ok := s.Relation("team", "member")
+	if !ok {
+		t.Fatal("team#member missing")
+	}
+	wantSubjects := []SubjectType{{Type: "user"}, {Type: "team", Relation: "member"}}
+	if !reflect.DeepEqual(member.Subjects, wantSubjects) || member.IsPermission() {
+		t.Errorf("team#member = %+v, want relation with subjects %v", member, wantSubjects)
+	}
+
+	edit, _ := s.Relation("doc", "edit")
+	wantEdit := SetOperation{
+		Op: OpExclusion,
+		Left: SetOperation{
+			Op:    OpUnion,
+			Left:  ComputedUserset{Relation: "owner"},
+			Right: TupleToUserset{Tupleset: "team", Computed: "member"},
+		},
+		Right: ComputedUserset{Relation: "banned"},
+	}
+	if !reflect.DeepEqual(edit.Expr, wantEdit) {
+		t.Errorf("doc#edit = %+v, want %+v", edit.Expr, wantEdit)
+	}
+
+	view, _ := s.Relation("doc", "view")
+	wantView := SetOperation{
+		Op: OpUnion,
+		Left: SetOperation{
+			Op:    OpIntersection,
+			Left:  ComputedUserset{Relation: "owner"},
+			Right: ComputedUserset{Relation: "banned"},
+		},
+		Right: ComputedUserset{Relation: "edit"},
+	}
+	if !reflect.DeepEqual(view.Expr, wantView) {
+		t.Errorf("doc#view = %+v, want %+v", view.Expr, wantView)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"unexpected character", "definition user { ! }", "unexpected character"},
+		{"missing brace", "definition user {", `expected relation, permission or }`},
+		{"duplicate definition", "definition user {} definition user {}", "declared twice"},
+		{"duplicate relation", "definition user {} definition doc { relation a: user relation a: user }", "declared twice"},
+		{"unknown type", "definition doc { relation owner: user }", `unknown type "user"`},
+		{"unknown subject relation", "definition user {} definition doc { relation a: doc#b }", "unknown relation"},
+		{"unknown computed relation", "definition user {} definition doc { permission p = owner }", `unknown relation "owner"`},
+		{"tupleset is a permission", `definition user {}
+			definition doc { relation owner: user permission p = owner permission q = p->owner }`, "must be a relation"},
+		{"tupleset target lacks relation", `definition user {}
+			definition doc { relation owner: user permission q = owner->edit }`, `no type in "owner" has "edit"`},
+		{"unclosed parenthesis", "definition user {} definition doc { relation a: user permission p = (a + a }", `expected ")"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultSchemaParses(t *testing.T) {
+	if _, err := Parse(defaultSchema); err != nil {
+		t.Fatalf("default schema: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type accountMergeRequestRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAccountMergeRequestRepository(db *pgxpool.Pool) *accountMergeRequestRepo {
+	return &accountMergeRequestRepo{
+		db: db,
+	}
+}
+
+// Create is idempotent for pending requests: the no-op update makes
+// RETURNING yield the existing row.
+func (r *accountMergeRequestRepo) Create(ctx context.Context, req *domain.AccountMergeRequest) error {
+	const query = `
+	INSERT INTO account_merge_requests (source_user_id, target_user_id, provider_id, subject)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (source_user_id, target_user_id) WHERE status = 'pending'
+	DO UPDATE SET status = account_merge_requests.status
+	RETURNING id, status, created_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, req.SourceUserID, req.TargetUserID, req.ProviderID, req.Subject).
+		Scan(&req.ID, &req.Status, &req.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create account merge request: %w", err)
+	}
+	return nil
+}
+
+func (r *accountMergeRequestRepo) ListPending(ctx context.Context) ([]*domain.AccountMergeRequest, error) {
+	const query = `
+	SELECT id, source_user_id, target_user_id, provider_id, subject, status, created_at
+	FROM account_merge_requests
+	WHERE status = 'pending'
+	ORDER BY created_at`
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account merge requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*domain.AccountMergeRequest
+	for rows.Next() {
+		var req domain.AccountMergeRequest
+		if err := rows.Scan(&req.ID, &req.SourceUserID, &req.TargetUserID, &req.ProviderID, &req.Subject,
+			&req.Status, &req.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan account merge request: %w", err)
+		}
+		requests = append(requests, &req)
+	}
+	return requests, rows.Err()
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type auditRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepository(db *pgxpool.Pool) *auditRepo {
+	return &auditRepo{
+		db: db,
+	}
+}
+
+func (r *auditRepo) Record(ctx context.Context, e domain.AuditEntry) error {
+	details := e.Details
+	if details == nil {
+		details = map[string]string{}
+	}
+	const query = `
+	INSERT INTO audit_log (actor_id, action, business_id, target_user_id, details)
+	VALUES (NULLIF($1, '')::uuid, $2, NULLIF($3, '')::uuid, NULLIF($4, '')::uuid, $5)`
+	_, err := conn(ctx, r.db).Exec(ctx, query, e.ActorID, e.Action, e.BusinessID, e.TargetUserID, details)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type authorizationCodeRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewAuthorizationCodeRepository(db *pgxpool.Pool) *authorizationCodeRepo {
+	return &authorizationCodeRepo{
+		db: db,
+	}
+}
+
+func (r *authorizationCodeRepo) Create(ctx context.Context, code *domain.AuthorizationCode) error {
+	const query = `
+	INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scope, code_challenge,
+	                                       nonce, auth_time, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err := conn(ctx, r.db).Exec(ctx, query, code.CodeHash, code.ClientID, code.UserID, code.RedirectURI,
+		code.Scope, code.CodeChallenge, code.Nonce, code.AuthTime, code.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create authorization code: %w", err)
+	}
+	return nil
+}
+
+// Consume marks the code used in the same statement that reads it, so two
+// concurrent redemptions cannot both succeed.
+func (r *authorizationCodeRepo) Consume(ctx context.Context, codeHash string) (*domain.AuthorizationCode, error) {
+	const query = `
+	UPDATE oauth_authorization_codes
+	SET used_at = now()
+	WHERE code_hash = $1
+	AND used_at IS NULL
+	AND expires_at > now()
+	RETURNING code_hash, client_id, user_id, redirect_uri, scope, code_challenge, nonce, auth_time, expires_at`
+	var code domain.AuthorizationCode
+	err := conn(ctx, r.db).QueryRow(ctx, query, codeHash).Scan(
+		&code.CodeHash,
+		&code.ClientID,
+		&code.UserID,
+		&code.RedirectURI,
+		&code.Scope,
+		&code.CodeChallenge,
+		&code.Nonce,
+		&code.AuthTime,
+		&code.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAuthorizationCodeInvalid
+		}
+		return nil, fmt.Errorf("failed to consume authorization code: %w", err)
+	}
+	return &code, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type businessUnitRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewBusinessUnitRepository(db *pgxpool.Pool) *businessUnitRepo {
+	return &businessUnitRepo{
+		db: db,
+	}
+}
+
+// LockHierarchy serializes SetParent transactions. Locking only the rows
+// being changed is not enough: two moves touching disjoint rows can still
+// close a cycle through existing links, each passing its own check.
+func (r *businessUnitRepo) LockHierarchy(ctx context.Context) error {
+	if _, err := conn(ctx, r.db).Exec(ctx, `LOCK TABLE business_units IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock business hierarchy: %w", err)
+	}
+	return nil
+}
+
+// SetParent records parentID as the parent of businessID, creating both units
+// as needed. An empty parentID detaches the business.
+func (r *businessUnitRepo) SetParent(ctx context.Context, businessID, parentID string) error {
+	q := conn(ctx, r.db)
+	if parentID != "" {
+		if _, err := q.Exec(ctx, `INSERT INTO business_units (id) VALUES ($1) ON CONFLICT (id) DO NOTHING`, parentID); err != nil {
+			return fmt.Errorf("failed to save parent business: %w", err)
+		}
+	}
+	const query = `
+	INSERT INTO business_units (id, parent_id)
+	VALUES ($1, NULLIF($2, '')::uuid)
+	ON CONFLICT (id) DO UPDATE
+	   SET parent_id = EXCLUDED.parent_id`
+	if _, err := q.Exec(ctx, query, businessID, parentID); err != nil {
+		return fmt.Errorf("failed to set business parent: %w", err)
+	}
+	return nil
+}
+
+func (r *businessUnitRepo) Ancestors(ctx context.Context, businessID string) ([]string, error) {
+	query := businessAncestorsCTE("$1") + `
+	SELECT id::text FROM ancestors ORDER BY depth`
+	rows, err := conn(ctx, r.db).Query(ctx, query, businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list business ancestors: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan business id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *businessUnitRepo) Children(ctx context.Context, businessID string) ([]string, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT id::text FROM business_units WHERE parent_id = $1 ORDER BY created_at`, businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list child businesses: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan business id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
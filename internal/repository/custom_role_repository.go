@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type customRoleRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewCustomRoleRepository(db *pgxpool.Pool) *customRoleRepo {
+	return &customRoleRepo{
+		db: db,
+	}
+}
+
+func (r *customRoleRepo) Create(ctx context.Context, role *domain.CustomRole) error {
+	const query = `
+	INSERT INTO business_roles (business_id, name, description, created_by)
+	VALUES ($1, $2, $3, NULLIF($4, '')::uuid)
+	RETURNING id, created_at, updated_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, role.BusinessID, role.Name, role.Description, role.CreatedBy).
+		Scan(&role.ID, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrRoleExists
+		}
+		return fmt.Errorf("failed to create role: %w", err)
+	}
+	return nil
+}
+
+func (r *customRoleRepo) Update(ctx context.Context, role *domain.CustomRole) error {
+	const query = `
+	UPDATE business_roles
+	SET name = $2, description = $3
+	WHERE id = $1
+	RETURNING updated_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, role.ID, role.Name, role.Description).Scan(&role.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrRoleExists
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	return nil
+}
+
+func (r *customRoleRepo) Delete(ctx context.Context, id string) error {
+	const query = `DELETE FROM business_roles WHERE id = $1`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return domain.ErrRoleInUse
+		}
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRoleNotFound
+	}
+	return nil
+}
+
+func (r *customRoleRepo) GetByID(ctx context.Context, id string) (*domain.CustomRole, error) {
+	const query = `
+	SELECT id, business_id, name, description, COALESCE(created_by::text, ''), created_at, updated_at
+	FROM business_roles
+	WHERE id = $1`
+	var role domain.CustomRole
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
+		&role.ID,
+		&role.BusinessID,
+		&role.Name,
+		&role.Description,
+		&role.CreatedBy,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	if role.Permissions, err = r.permissions(ctx, role.ID); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *customRoleRepo) ListByBusiness(ctx context.Context, businessID string) ([]*domain.CustomRole, error) {
+	const query = `
+	SELECT id, business_id, name, description, COALESCE(created_by::text, ''), created_at, updated_at
+	FROM business_roles
+	WHERE business_id = $1
+	ORDER BY name`
+	rows, err := conn(ctx, r.db).Query(ctx, query, businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*domain.CustomRole
+	for rows.Next() {
+		var role domain.CustomRole
+		if err := rows.Scan(&role.ID, &role.BusinessID, &role.Name, &role.Description,
+			&role.CreatedBy, &role.CreatedAt, &role.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, &role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, role := range roles {
+		if role.Permissions, err = r.permissions(ctx, role.ID); err != nil {
+			return nil, err
+		}
+	}
+	return roles, nil
+}
+
+// SetPermissions replaces the permission set of the role. Run it inside a
+// transaction together with Create or Update.
+func (r *customRoleRepo) SetPermissions(ctx context.Context, roleID string, perms []domain.Permission) error {
+	q := conn(ctx, r.db)
+	if _, err := q.Exec(ctx, `DELETE FROM business_role_permissions WHERE role_id = $1`, roleID); err != nil {
+		return fmt.Errorf("failed to clear role permissions: %w", err)
+	}
+
+	resources := make([]string, len(perms))
+	actions := make([]string, len(perms))
+	for i, p := range perms {
+		resources[i] = p.Resource
+		actions[i] = p.Action
+	}
+	const query = `
+	INSERT INTO business_role_permissions (role_id, resource, action)
+	SELECT $1, resource, action FROM unnest($2::text[], $3::text[]) AS p(resource, action)
+	ON CONFLICT DO NOTHING`
+	if _, err := q.Exec(ctx, query, roleID, resources, actions); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return domain.ErrUnknownPermission
+		}
+		return fmt.Errorf("failed to set role permissions: %w", err)
+	}
+	return nil
+}
+
+func (r *customRoleRepo) permissions(ctx context.Context, roleID string) ([]domain.Permission, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT resource, action FROM business_role_permissions WHERE role_id = $1 ORDER BY resource, action`, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var perms []domain.Permission
+	for rows.Next() {
+		var p domain.Permission
+		if err := rows.Scan(&p.Resource, &p.Action); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		perms = append(perms, p)
+	}
+	return perms, rows.Err()
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type decisionLogRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewDecisionLogRepository(db *pgxpool.Pool) *decisionLogRepo {
+	return &decisionLogRepo{
+		db: db,
+	}
+}
+
+// InsertBatch writes all records in one statement.
+func (r *decisionLogRepo) InsertBatch(ctx context.Context, records []domain.DecisionRecord) error {
+	n := len(records)
+	var (
+		userIDs     = make([]string, n)
+		businessIDs = make([]string, n)
+		resources   = make([]string, n)
+		actions     = make([]string, n)
+		allowed     = make([]bool, n)
+		reasons     = make([]string, n)
+		matchedBy   = make([]string, n)
+		clientIPs   = make([]string, n)
+		attributes  = make([]string, n)
+		latencies   = make([]int64, n)
+		createdAt   = make([]time.Time, n)
+	)
+	for i, rec := range records {
+		attrs := rec.ResourceAttributes
+		if attrs == nil {
+			attrs = map[string]string{}
+		}
+		b, err := json.Marshal(attrs)
+		if err != nil {
+			return fmt.Errorf("failed to encode resource attributes: %w", err)
+		}
+		userIDs[i] = rec.UserID
+		businessIDs[i] = rec.BusinessID
+		resources[i] = rec.Permission.Resource
+		actions[i] = rec.Permission.Action
+		allowed[i] = rec.Allowed
+		reasons[i] = rec.Reason
+		matchedBy[i] = rec.MatchedBy
+		clientIPs[i] = rec.ClientIP
+		attributes[i] = string(b)
+		latencies[i] = rec.Latency.Microseconds()
+		createdAt[i] = rec.CreatedAt
+	}
+
+	const query = `
+	INSERT INTO decision_log (user_id, business_id, resource, action, allowed, reason, matched_by,
+	                          client_ip, resource_attributes, latency_us, created_at)
+	SELECT u::uuid, NULLIF(b, '')::uuid, res, act, al, rsn, mb, ip, attrs::jsonb, lat, ts
+	FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::bool[], $6::text[], $7::text[],
+	            $8::text[], $9::text[], $10::bigint[], $11::timestamptz[])
+	     AS t(u, b, res, act, al, rsn, mb, ip, attrs, lat, ts)`
+	_, err := r.db.Exec(ctx, query, userIDs, businessIDs, resources, actions, allowed, reasons, matchedBy,
+		clientIPs, attributes, latencies, createdAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert decision log: %w", err)
+	}
+	return nil
+}
+
+func (r *decisionLogRepo) Query(ctx context.Context, f domain.DecisionLogFilter) ([]*domain.DecisionRecord, error) {
+	var from, to *time.Time
+	if !f.From.IsZero() {
+		from = &f.From
+	}
+	if !f.To.IsZero() {
+		to = &f.To
+	}
+
+	const query = `
+	SELECT id, user_id, COALESCE(business_id::text, ''), resource, action, allowed, reason, matched_by,
+	       client_ip, resource_attributes, latency_us, created_at
+	FROM decision_log
+	WHERE ($1 = '' OR user_id = NULLIF($1, '')::uuid)
+	AND ($2 = '' OR business_id = NULLIF($2, '')::uuid)
+	AND ($3::timestamptz IS NULL OR created_at >= $3)
+	AND ($4::timestamptz IS NULL OR created_at < $4)
+	ORDER BY created_at DESC, id DESC
+	LIMIT $5`
+	rows, err := r.db.Query(ctx, query, f.UserID, f.BusinessID, from, to, f.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query decision log: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*domain.DecisionRecord
+	for rows.Next() {
+		var rec domain.DecisionRecord
+		var latencyUS int64
+		err := rows.Scan(
+			&rec.ID,
+			&rec.UserID,
+			&rec.BusinessID,
+			&rec.Permission.Resource,
+			&rec.Permission.Action,
+			&rec.Allowed,
+			&rec.Reason,
+			&rec.MatchedBy,
+			&rec.ClientIP,
+			&rec.ResourceAttributes,
+			&latencyUS,
+			&rec.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		rec.Latency = time.Duration(latencyUS) * time.Microsecond
+		records = append(records, &rec)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type deviceAuthorizationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewDeviceAuthorizationRepository(db *pgxpool.Pool) *deviceAuthorizationRepo {
+	return &deviceAuthorizationRepo{
+		db: db,
+	}
+}
+
+func (r *deviceAuthorizationRepo) Create(ctx context.Context, d *domain.DeviceAuthorization) error {
+	const query = `
+	INSERT INTO oauth_device_codes (device_code_hash, user_code, client_id, scope, interval_seconds, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING status, created_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, d.DeviceCodeHash, d.UserCode, d.ClientID, d.Scope,
+		d.Interval, d.ExpiresAt).Scan(&d.Status, &d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create device authorization: %w", err)
+	}
+	return nil
+}
+
+func (r *deviceAuthorizationRepo) GetPending(ctx context.Context, userCode string) (*domain.DeviceAuthorization, error) {
+	const query = `
+	SELECT d.device_code_hash, d.user_code, d.client_id, c.name, d.scope, COALESCE(d.user_id::text, ''), d.status,
+	       d.interval_seconds, d.expires_at, d.created_at
+	FROM oauth_device_codes d
+	JOIN oauth_clients c ON c.client_id = d.client_id
+	WHERE d.user_code = $1
+	AND d.status = 'pending'
+	AND d.expires_at > now()`
+	var d domain.DeviceAuthorization
+	err := conn(ctx, r.db).QueryRow(ctx, query, userCode).Scan(
+		&d.DeviceCodeHash,
+		&d.UserCode,
+		&d.ClientID,
+		&d.ClientName,
+		&d.Scope,
+		&d.UserID,
+		&d.Status,
+		&d.Interval,
+		&d.ExpiresAt,
+		&d.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDeviceCodeNotFound
+		}
+		return nil, fmt.Errorf("failed to get device authorization: %w", err)
+	}
+	return &d, nil
+}
+
+func (r *deviceAuthorizationRepo) Resolve(ctx context.Context, userCode, userID string, approve bool) error {
+	status := domain.DeviceStatusDenied
+	if approve {
+		status = domain.DeviceStatusApproved
+	}
+	const query = `
+	UPDATE oauth_device_codes
+	SET status = $3, user_id = $2
+	WHERE user_code = $1
+	AND status = 'pending'
+	AND expires_at > now()`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, userCode, userID, status)
+	if err != nil {
+		return fmt.Errorf("failed to resolve device authorization: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDeviceCodeNotFound
+	}
+	return nil
+}
+
+// Poll reads the previous poll time and stores the new one in a single
+// statement, so concurrent polls from a misbehaving device still slow down.
+func (r *deviceAuthorizationRepo) Poll(ctx context.Context, deviceCodeHash string) (*domain.DeviceAuthorization, bool, error) {
+	const query = `
+	WITH prev AS (
+		SELECT device_code_hash,
+		       last_polled_at IS NOT NULL
+		       AND last_polled_at > now() - make_interval(secs => interval_seconds) AS too_fast
+		FROM oauth_device_codes
+		WHERE device_code_hash = $1
+		AND status <> 'consumed'
+		FOR UPDATE
+	)
+	UPDATE oauth_device_codes d
+	SET last_polled_at = now(),
+	    interval_seconds = CASE WHEN prev.too_fast THEN d.interval_seconds + 5 ELSE d.interval_seconds END
+	FROM prev
+	WHERE d.device_code_hash = prev.device_code_hash
+	RETURNING d.device_code_hash, d.user_code, d.client_id, d.scope, COALESCE(d.user_id::text, ''), d.status,
+	          d.interval_seconds, d.expires_at, d.created_at, prev.too_fast`
+	var d domain.DeviceAuthorization
+	var slowDown bool
+	err := conn(ctx, r.db).QueryRow(ctx, query, deviceCodeHash).Scan(
+		&d.DeviceCodeHash,
+		&d.UserCode,
+		&d.ClientID,
+		&d.Scope,
+		&d.UserID,
+		&d.Status,
+		&d.Interval,
+		&d.ExpiresAt,
+		&d.CreatedAt,
+		&slowDown,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, domain.ErrDeviceCodeNotFound
+		}
+		return nil, false, fmt.Errorf("failed to poll device authorization: %w", err)
+	}
+	return &d, slowDown, nil
+}
+
+func (r *deviceAuthorizationRepo) Consume(ctx context.Context, deviceCodeHash string) error {
+	const query = `
+	UPDATE oauth_device_codes
+	SET status = 'consumed'
+	WHERE device_code_hash = $1
+	AND status = 'approved'`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, deviceCodeHash)
+	if err != nil {
+		return fmt.Errorf("failed to consume device authorization: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrDeviceCodeNotFound
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type federatedLoginStateRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewFederatedLoginStateRepository(db *pgxpool.Pool) *federatedLoginStateRepo {
+	return &federatedLoginStateRepo{
+		db: db,
+	}
+}
+
+func (r *federatedLoginStateRepo) Create(ctx context.Context, s *domain.FederatedLoginState) error {
+	const query = `
+	INSERT INTO federated_login_states (state_hash, provider_id, user_id, redirect_uri, code_verifier, nonce,
+	                                    expires_at)
+	VALUES ($1, $2, NULLIF($3, '')::uuid, $4, $5, $6, $7)`
+	_, err := conn(ctx, r.db).Exec(ctx, query, s.StateHash, s.ProviderID, s.UserID, s.RedirectURI, s.CodeVerifier,
+		s.Nonce, s.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create federated login state: %w", err)
+	}
+	return nil
+}
+
+// Consume deletes the state as it reads it, so a redirect cannot be replayed.
+func (r *federatedLoginStateRepo) Consume(ctx context.Context, stateHash, providerID string) (*domain.FederatedLoginState, error) {
+	const query = `
+	DELETE FROM federated_login_states
+	WHERE state_hash = $1
+	AND provider_id = $2
+	AND expires_at > now()
+	RETURNING state_hash, provider_id, COALESCE(user_id::text, ''), redirect_uri, code_verifier, nonce, expires_at`
+	var s domain.FederatedLoginState
+	err := conn(ctx, r.db).QueryRow(ctx, query, stateHash, providerID).Scan(
+		&s.StateHash,
+		&s.ProviderID,
+		&s.UserID,
+		&s.RedirectURI,
+		&s.CodeVerifier,
+		&s.Nonce,
+		&s.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrFederatedStateInvalid
+		}
+		return nil, fmt.Errorf("failed to consume federated login state: %w", err)
+	}
+	return &s, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type invitationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewInvitationRepository(db *pgxpool.Pool) *invitationRepo {
+	return &invitationRepo{
+		db: db,
+	}
+}
+
+const invitationColumns = `id, business_id, COALESCE(email, ''), COALESCE(phone, ''), role, token_hash,
+	invited_by, COALESCE(accepted_by::text, ''), expires_at, accepted_at, revoked_at, created_at`
+
+func scanInvitation(row pgx.Row) (*domain.Invitation, error) {
+	var inv domain.Invitation
+	err := row.Scan(
+		&inv.ID,
+		&inv.BusinessID,
+		&inv.Email,
+		&inv.Phone,
+		&inv.Role,
+		&inv.TokenHash,
+		&inv.InvitedBy,
+		&inv.AcceptedBy,
+		&inv.ExpiresAt,
+		&inv.AcceptedAt,
+		&inv.RevokedAt,
+		&inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (r *invitationRepo) Create(ctx context.Context, inv *domain.Invitation) error {
+	const query = `
+	INSERT INTO business_invitations (business_id, email, phone, role, token_hash, invited_by, expires_at)
+	VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, $7)
+	RETURNING id, created_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query,
+		inv.BusinessID, inv.Email, inv.Phone, inv.Role, inv.TokenHash, inv.InvitedBy, inv.ExpiresAt,
+	).Scan(&inv.ID, &inv.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+	return nil
+}
+
+func (r *invitationRepo) GetByID(ctx context.Context, id string) (*domain.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM business_invitations WHERE id = $1`
+	inv, err := scanInvitation(conn(ctx, r.db).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return inv, nil
+}
+
+func (r *invitationRepo) GetByTokenHash(ctx context.Context, hash string) (*domain.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM business_invitations WHERE token_hash = $1`
+	inv, err := scanInvitation(conn(ctx, r.db).QueryRow(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	return inv, nil
+}
+
+func (r *invitationRepo) ListByBusiness(ctx context.Context, businessID string) ([]*domain.Invitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM business_invitations
+	WHERE business_id = $1
+	ORDER BY created_at DESC`
+	rows, err := conn(ctx, r.db).Query(ctx, query, businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+	defer rows.Close()
+
+	var invitations []*domain.Invitation
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invitation: %w", err)
+		}
+		invitations = append(invitations, inv)
+	}
+	return invitations, rows.Err()
+}
+
+func (r *invitationRepo) Revoke(ctx context.Context, id string) error {
+	const query = `
+	UPDATE business_invitations
+	SET revoked_at = now()
+	WHERE id = $1
+	AND accepted_at IS NULL
+	AND revoked_at IS NULL`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invitation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvitationNotPending
+	}
+	return nil
+}
+
+// MarkAccepted only succeeds for a pending invitation, so concurrent accepts
+// of the same token cannot both win.
+func (r *invitationRepo) MarkAccepted(ctx context.Context, id, userID string) error {
+	const query = `
+	UPDATE business_invitations
+	SET accepted_at = now(), accepted_by = $2
+	WHERE id = $1
+	AND accepted_at IS NULL
+	AND revoked_at IS NULL
+	AND expires_at > now()`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to accept invitation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvitationNotPending
+	}
+	return nil
+}
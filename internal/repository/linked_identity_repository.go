@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type linkedIdentityRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewLinkedIdentityRepository(db *pgxpool.Pool) *linkedIdentityRepo {
+	return &linkedIdentityRepo{
+		db: db,
+	}
+}
+
+func (r *linkedIdentityRepo) Create(ctx context.Context, l *domain.LinkedIdentity) error {
+	const query = `
+	INSERT INTO linked_identities (provider_id, subject, user_id, email)
+	VALUES ($1, $2, $3, $4)
+	RETURNING created_at, last_login_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, l.ProviderID, l.Subject, l.UserID, l.Email).
+		Scan(&l.CreatedAt, &l.LastLoginAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "linked_identities_user_id_provider_id_key" {
+			return domain.ErrProviderAlreadyLinked
+		}
+		return fmt.Errorf("failed to create linked identity: %w", err)
+	}
+	return nil
+}
+
+func (r *linkedIdentityRepo) Get(ctx context.Context, providerID, subject string) (*domain.LinkedIdentity, error) {
+	const query = `
+	SELECT provider_id, subject, user_id, email, created_at, last_login_at
+	FROM linked_identities
+	WHERE provider_id = $1
+	AND subject = $2`
+	var l domain.LinkedIdentity
+	err := conn(ctx, r.db).QueryRow(ctx, query, providerID, subject).Scan(
+		&l.ProviderID,
+		&l.Subject,
+		&l.UserID,
+		&l.Email,
+		&l.CreatedAt,
+		&l.LastLoginAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrLinkedIdentityNotFound
+		}
+		return nil, fmt.Errorf("failed to get linked identity: %w", err)
+	}
+	return &l, nil
+}
+
+func (r *linkedIdentityRepo) TouchLogin(ctx context.Context, providerID, subject string) error {
+	const query = `
+	UPDATE linked_identities
+	SET last_login_at = now()
+	WHERE provider_id = $1
+	AND subject = $2`
+	_, err := conn(ctx, r.db).Exec(ctx, query, providerID, subject)
+	if err != nil {
+		return fmt.Errorf("failed to update linked identity: %w", err)
+	}
+	return nil
+}
+
+func (r *linkedIdentityRepo) ListByUser(ctx context.Context, userID string) ([]*domain.LinkedIdentity, error) {
+	const query = `
+	SELECT provider_id, subject, user_id, email, created_at, last_login_at
+	FROM linked_identities
+	WHERE user_id = $1
+	ORDER BY created_at`
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list linked identities: %w", err)
+	}
+	defer rows.Close()
+
+	var identities []*domain.LinkedIdentity
+	for rows.Next() {
+		var l domain.LinkedIdentity
+		if err := rows.Scan(&l.ProviderID, &l.Subject, &l.UserID, &l.Email, &l.CreatedAt, &l.LastLoginAt); err != nil {
+			return nil, fmt.Errorf("failed to scan linked identity: %w", err)
+		}
+		identities = append(identities, &l)
+	}
+	return identities, rows.Err()
+}
+
+func (r *linkedIdentityRepo) Delete(ctx context.Context, userID, providerID string) error {
+	const query = `
+	DELETE FROM linked_identities
+	WHERE user_id = $1
+	AND provider_id = $2`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, userID, providerID)
+	if err != nil {
+		return fmt.Errorf("failed to delete linked identity: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrLinkedIdentityNotFound
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type membershipRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewMembershipRepository(db *pgxpool.Pool) *membershipRepo {
+	return &membershipRepo{
+		db: db,
+	}
+}
+
+func (r *membershipRepo) Create(ctx context.Context, m *domain.Membership) error {
+	const query = `
+	INSERT INTO user_business_memberships (user_id, business_id, role, custom_role_id, is_active)
+	VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, '')::uuid, $5)
+	RETURNING id, created_at, updated_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, m.UserID, m.BusinessID, m.Role, m.CustomRoleID, m.IsActive).
+		Scan(&m.ID, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrMembershipExists
+		}
+		return fmt.Errorf("failed to create membership: %w", err)
+	}
+	return nil
+}
+
+func (r *membershipRepo) GetByUserAndBusiness(ctx context.Context, userID, businessID string) (*domain.Membership, error) {
+	const query = `
+	SELECT m.id, m.user_id, m.business_id, COALESCE(m.role, ''), COALESCE(m.custom_role_id::text, ''),
+	       COALESCE(br.name, ''), m.is_active, m.created_at, m.updated_at
+	FROM user_business_memberships m
+	LEFT JOIN business_roles br ON br.id = m.custom_role_id
+	WHERE m.user_id = $1
+	AND m.business_id = $2`
+	var m domain.Membership
+	err := conn(ctx, r.db).QueryRow(ctx, query, userID, businessID).Scan(
+		&m.ID,
+		&m.UserID,
+		&m.BusinessID,
+		&m.Role,
+		&m.CustomRoleID,
+		&m.CustomRoleName,
+		&m.IsActive,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrMembershipNotFound
+		}
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+	return &m, nil
+}
+
+// businessAncestorsCTE lists the business bound to param and its ancestors
+// with their distance. The anchor does not require a business_units row, so
+// standalone businesses resolve to themselves; depth is capped as a guard
+// against cycles.
+func businessAncestorsCTE(param string) string {
+	return fmt.Sprintf(`
+	WITH RECURSIVE ancestors AS (
+		SELECT %[1]s::uuid AS id, (SELECT parent_id FROM business_units WHERE id = %[1]s::uuid) AS parent_id, 0 AS depth
+		UNION ALL
+		SELECT bu.id, bu.parent_id, a.depth + 1
+		FROM business_units bu
+		JOIN ancestors a ON bu.id = a.parent_id
+		WHERE a.depth < 32
+	)`, param)
+}
+
+func (r *membershipRepo) GetEffective(ctx context.Context, userID, businessID string) (*domain.Membership, error) {
+	query := businessAncestorsCTE("$2") + `
+	SELECT m.id, m.user_id, m.business_id, COALESCE(m.role, ''), COALESCE(m.custom_role_id::text, ''),
+	       COALESCE(br.name, ''), m.is_active, m.created_at, m.updated_at
+	FROM ancestors a
+	JOIN user_business_memberships m ON m.business_id = a.id AND m.user_id = $1
+	LEFT JOIN business_roles br ON br.id = m.custom_role_id
+	ORDER BY a.depth
+	LIMIT 1`
+	var m domain.Membership
+	err := conn(ctx, r.db).QueryRow(ctx, query, userID, businessID).Scan(
+		&m.ID,
+		&m.UserID,
+		&m.BusinessID,
+		&m.Role,
+		&m.CustomRoleID,
+		&m.CustomRoleName,
+		&m.IsActive,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrMembershipNotFound
+		}
+		return nil, fmt.Errorf("failed to get effective membership: %w", err)
+	}
+	if m.BusinessID != businessID {
+		m.InheritedFrom, m.BusinessID = m.BusinessID, businessID
+	}
+	return &m, nil
+}
+
+func (r *membershipRepo) UpdateRole(ctx context.Context, m *domain.Membership) error {
+	const query = `
+	UPDATE user_business_memberships
+	SET role = NULLIF($2, ''), custom_role_id = NULLIF($3, '')::uuid
+	WHERE id = $1
+	RETURNING updated_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, m.ID, m.Role, m.CustomRoleID).Scan(&m.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrMembershipNotFound
+		}
+		return fmt.Errorf("failed to update membership role: %w", err)
+	}
+	return nil
+}
+
+func (r *membershipRepo) Grant(ctx context.Context, m *domain.Membership) error {
+	const query = `
+	INSERT INTO user_business_memberships (user_id, business_id, role, is_active)
+	VALUES ($1, $2, $3, true)
+	ON CONFLICT (user_id, business_id) DO UPDATE
+	   SET role = EXCLUDED.role,
+	       custom_role_id = NULL,
+	       is_active = true
+	RETURNING id, created_at, updated_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, m.UserID, m.BusinessID, m.Role).
+		Scan(&m.ID, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to grant membership: %w", err)
+	}
+	m.CustomRoleID, m.CustomRoleName, m.IsActive = "", "", true
+	return nil
+}
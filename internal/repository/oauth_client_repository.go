@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type oauthClientRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewOAuthClientRepository(db *pgxpool.Pool) *oauthClientRepo {
+	return &oauthClientRepo{
+		db: db,
+	}
+}
+
+func (r *oauthClientRepo) Create(ctx context.Context, c *domain.OAuthClient) error {
+	const query = `
+	INSERT INTO oauth_clients (client_id, secret_hash, name, redirect_uris, scopes, created_by)
+	VALUES ($1, NULLIF($2, ''), $3, $4, $5, NULLIF($6, '')::uuid)
+	RETURNING created_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, c.ID, c.SecretHash, c.Name, c.RedirectURIs, c.Scopes, c.CreatedBy).
+		Scan(&c.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create oauth client: %w", err)
+	}
+	return nil
+}
+
+func (r *oauthClientRepo) GetByID(ctx context.Context, clientID string) (*domain.OAuthClient, error) {
+	const query = `
+	SELECT client_id, COALESCE(secret_hash, ''), name, redirect_uris, scopes, COALESCE(created_by::text, ''), created_at
+	FROM oauth_clients
+	WHERE client_id = $1`
+	var c domain.OAuthClient
+	err := conn(ctx, r.db).QueryRow(ctx, query, clientID).Scan(
+		&c.ID,
+		&c.SecretHash,
+		&c.Name,
+		&c.RedirectURIs,
+		&c.Scopes,
+		&c.CreatedBy,
+		&c.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrOAuthClientNotFound
+		}
+		return nil, fmt.Errorf("failed to get oauth client: %w", err)
+	}
+	return &c, nil
+}
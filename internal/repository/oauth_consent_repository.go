@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type oauthConsentRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewOAuthConsentRepository(db *pgxpool.Pool) *oauthConsentRepo {
+	return &oauthConsentRepo{
+		db: db,
+	}
+}
+
+// Get returns nil without error when the user never consented to the client.
+func (r *oauthConsentRepo) Get(ctx context.Context, userID, clientID string) (*domain.OAuthConsent, error) {
+	const query = `
+	SELECT user_id, client_id, scopes, granted_at
+	FROM oauth_consents
+	WHERE user_id = $1
+	AND client_id = $2`
+	var c domain.OAuthConsent
+	err := conn(ctx, r.db).QueryRow(ctx, query, userID, clientID).Scan(&c.UserID, &c.ClientID, &c.Scopes, &c.GrantedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get consent: %w", err)
+	}
+	return &c, nil
+}
+
+func (r *oauthConsentRepo) Grant(ctx context.Context, userID, clientID string, scopes []string) error {
+	const query = `
+	INSERT INTO oauth_consents (user_id, client_id, scopes)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (user_id, client_id) DO UPDATE
+	SET scopes = ARRAY(SELECT DISTINCT unnest(oauth_consents.scopes || EXCLUDED.scopes)),
+	    granted_at = now()`
+	if _, err := conn(ctx, r.db).Exec(ctx, query, userID, clientID, scopes); err != nil {
+		return fmt.Errorf("failed to record consent: %w", err)
+	}
+	return nil
+}
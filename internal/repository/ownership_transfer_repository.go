@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ownershipTransferRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewOwnershipTransferRepository(db *pgxpool.Pool) *ownershipTransferRepo {
+	return &ownershipTransferRepo{
+		db: db,
+	}
+}
+
+func (r *ownershipTransferRepo) Create(ctx context.Context, t *domain.OwnershipTransfer) error {
+	q := conn(ctx, r.db)
+	// a stale pending transfer must not block a new one
+	const expire = `
+	UPDATE ownership_transfers
+	SET status = 'cancelled', resolved_at = now()
+	WHERE business_id = $1
+	AND status = 'pending'
+	AND expires_at <= now()`
+	if _, err := q.Exec(ctx, expire, t.BusinessID); err != nil {
+		return fmt.Errorf("failed to expire ownership transfers: %w", err)
+	}
+
+	const query = `
+	INSERT INTO ownership_transfers (business_id, from_user_id, to_user_id, expires_at)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, status, created_at`
+	err := q.QueryRow(ctx, query, t.BusinessID, t.FromUserID, t.ToUserID, t.ExpiresAt).
+		Scan(&t.ID, &t.Status, &t.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrTransferInProgress
+		}
+		return fmt.Errorf("failed to create ownership transfer: %w", err)
+	}
+	return nil
+}
+
+func (r *ownershipTransferRepo) GetByID(ctx context.Context, id string) (*domain.OwnershipTransfer, error) {
+	const query = `
+	SELECT id, business_id, from_user_id, to_user_id, status, expires_at, resolved_at, created_at
+	FROM ownership_transfers
+	WHERE id = $1`
+	var t domain.OwnershipTransfer
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
+		&t.ID,
+		&t.BusinessID,
+		&t.FromUserID,
+		&t.ToUserID,
+		&t.Status,
+		&t.ExpiresAt,
+		&t.ResolvedAt,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTransferNotFound
+		}
+		return nil, fmt.Errorf("failed to get ownership transfer: %w", err)
+	}
+	return &t, nil
+}
+
+func (r *ownershipTransferRepo) MarkAccepted(ctx context.Context, id string) error {
+	const query = `
+	UPDATE ownership_transfers
+	SET status = 'accepted', resolved_at = now()
+	WHERE id = $1
+	AND status = 'pending'
+	AND expires_at > now()`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to accept ownership transfer: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTransferNotPending
+	}
+	return nil
+}
+
+func (r *ownershipTransferRepo) Cancel(ctx context.Context, id string) error {
+	const query = `
+	UPDATE ownership_transfers
+	SET status = 'cancelled', resolved_at = now()
+	WHERE id = $1
+	AND status = 'pending'`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel ownership transfer: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTransferNotPending
+	}
+	return nil
+}
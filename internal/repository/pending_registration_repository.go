@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type pendingRegistrationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPendingRegistrationRepository(db *pgxpool.Pool) *pendingRegistrationRepo {
+	return &pendingRegistrationRepo{
+		db: db,
+	}
+}
+
+func (r *pendingRegistrationRepo) Create(ctx context.Context, userID string) error {
+	const query = `INSERT INTO pending_registrations (user_id) VALUES ($1)`
+	if _, err := conn(ctx, r.db).Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to create pending registration: %w", err)
+	}
+	return nil
+}
+
+func (r *pendingRegistrationRepo) List(ctx context.Context) ([]*domain.PendingRegistration, error) {
+	const query = `
+	SELECT u.id, u.first_name, u.last_name, u.email, COALESCE(u.phone, ''), u.role, u.is_active,
+	u.email_verified, u.phone_verified, u.created_at, u.updated_at, p.created_at
+	FROM pending_registrations p
+	JOIN users u ON u.id = p.user_id
+	ORDER BY p.created_at`
+	rows, err := conn(ctx, r.db).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending registrations: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*domain.PendingRegistration
+	for rows.Next() {
+		var u domain.User
+		p := domain.PendingRegistration{User: &u}
+		if err := rows.Scan(&u.ID, &u.FirstName, &u.LastName, &u.Email, &u.Phone, &u.Role, &u.IsActive,
+			&u.EmailVerified, &u.PhoneVerified, &u.CreatedAt, &u.UpdatedAt, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pending registration: %w", err)
+		}
+		out = append(out, &p)
+	}
+	return out, rows.Err()
+}
+
+func (r *pendingRegistrationRepo) Approve(ctx context.Context, userID string) error {
+	const query = `
+	WITH pending AS (
+		DELETE FROM pending_registrations WHERE user_id = $1 RETURNING user_id
+	)
+	UPDATE users SET is_active = true
+	FROM pending
+	WHERE users.id = pending.user_id`
+	return r.resolve(ctx, query, userID)
+}
+
+func (r *pendingRegistrationRepo) Reject(ctx context.Context, userID string) error {
+	const query = `
+	WITH pending AS (
+		DELETE FROM pending_registrations WHERE user_id = $1 RETURNING user_id
+	)
+	DELETE FROM users
+	USING pending
+	WHERE users.id = pending.user_id`
+	return r.resolve(ctx, query, userID)
+}
+
+func (r *pendingRegistrationRepo) resolve(ctx context.Context, query, userID string) error {
+	tag, err := conn(ctx, r.db).Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve pending registration: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRegistrationNotFound
+	}
+	return nil
+}
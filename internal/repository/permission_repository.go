@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type permissionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPermissionRepository(db *pgxpool.Pool) *permissionRepo {
+	return &permissionRepo{
+		db: db,
+	}
+}
+
+func (r *permissionRepo) Known(ctx context.Context, perms []domain.Permission) (map[domain.Permission]bool, error) {
+	resources := make([]string, len(perms))
+	actions := make([]string, len(perms))
+	for i, p := range perms {
+		resources[i] = p.Resource
+		actions[i] = p.Action
+	}
+
+	const query = `
+	SELECT p.resource, p.action
+	FROM permissions p
+	JOIN unnest($1::text[], $2::text[]) AS q(resource, action)
+	  ON q.resource = p.resource AND q.action = p.action`
+	rows, err := conn(ctx, r.db).Query(ctx, query, resources, actions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up permissions: %w", err)
+	}
+	defer rows.Close()
+
+	known := make(map[domain.Permission]bool, len(perms))
+	for rows.Next() {
+		var p domain.Permission
+		if err := rows.Scan(&p.Resource, &p.Action); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		known[p] = true
+	}
+	return known, rows.Err()
+}
+
+func (r *permissionRepo) ListByRole(ctx context.Context, scope, role string) ([]domain.Permission, error) {
+	const query = `
+	SELECT resource, action
+	FROM role_permissions
+	WHERE scope = $1
+	AND role = $2`
+	rows, err := conn(ctx, r.db).Query(ctx, query, scope, role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var perms []domain.Permission
+	for rows.Next() {
+		var p domain.Permission
+		if err := rows.Scan(&p.Resource, &p.Action); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		perms = append(perms, p)
+	}
+	return perms, rows.Err()
+}
+
+func (r *permissionRepo) ListByCustomRole(ctx context.Context, roleID string) ([]domain.Permission, error) {
+	const query = `
+	SELECT resource, action
+	FROM business_role_permissions
+	WHERE role_id = $1`
+	rows, err := conn(ctx, r.db).Query(ctx, query, roleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom role permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var perms []domain.Permission
+	for rows.Next() {
+		var p domain.Permission
+		if err := rows.Scan(&p.Resource, &p.Action); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		perms = append(perms, p)
+	}
+	return perms, rows.Err()
+}
+
+func (r *permissionRepo) Deprecated(ctx context.Context, perms []domain.Permission) (map[domain.Permission]bool, error) {
+	resources := make([]string, len(perms))
+	actions := make([]string, len(perms))
+	for i, p := range perms {
+		resources[i] = p.Resource
+		actions[i] = p.Action
+	}
+
+	const query = `
+	SELECT p.resource, p.action
+	FROM permissions p
+	JOIN unnest($1::text[], $2::text[]) AS q(resource, action)
+	  ON q.resource = p.resource AND q.action = p.action
+	WHERE p.deprecated_at IS NOT NULL`
+	rows, err := conn(ctx, r.db).Query(ctx, query, resources, actions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up deprecated permissions: %w", err)
+	}
+	defer rows.Close()
+
+	deprecated := make(map[domain.Permission]bool)
+	for rows.Next() {
+		var p domain.Permission
+		if err := rows.Scan(&p.Resource, &p.Action); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		deprecated[p] = true
+	}
+	return deprecated, rows.Err()
+}
+
+func (r *permissionRepo) ClaimNamespace(ctx context.Context, name, ownerID string) (*domain.PermissionNamespace, error) {
+	q := conn(ctx, r.db)
+	const insert = `
+	INSERT INTO permission_namespaces (namespace, owner_id, version)
+	VALUES ($1, $2, 0)
+	ON CONFLICT (namespace) DO NOTHING`
+	if _, err := q.Exec(ctx, insert, name, ownerID); err != nil {
+		return nil, fmt.Errorf("failed to create permission namespace: %w", err)
+	}
+
+	const query = `
+	SELECT namespace, COALESCE(owner_id::text, ''), version, updated_at
+	FROM permission_namespaces
+	WHERE namespace = $1
+	FOR UPDATE`
+	var ns domain.PermissionNamespace
+	err := q.QueryRow(ctx, query, name).Scan(&ns.Name, &ns.OwnerID, &ns.Version, &ns.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock permission namespace: %w", err)
+	}
+	return &ns, nil
+}
+
+func (r *permissionRepo) SetNamespaceVersion(ctx context.Context, name string, version int) error {
+	const query = `UPDATE permission_namespaces SET version = $2 WHERE namespace = $1`
+	if _, err := conn(ctx, r.db).Exec(ctx, query, name, version); err != nil {
+		return fmt.Errorf("failed to update namespace version: %w", err)
+	}
+	return nil
+}
+
+// UpsertDefinitions creates or updates the permissions of a namespace. A
+// permission registered again without Deprecated is un-deprecated.
+func (r *permissionRepo) UpsertDefinitions(ctx context.Context, namespace string, defs []domain.PermissionDefinition) error {
+	n := len(defs)
+	resources := make([]string, n)
+	actions := make([]string, n)
+	descriptions := make([]string, n)
+	deprecated := make([]bool, n)
+	for i, d := range defs {
+		resources[i] = d.Permission.Resource
+		actions[i] = d.Permission.Action
+		descriptions[i] = d.Description
+		deprecated[i] = d.Deprecated
+	}
+
+	const query = `
+	INSERT INTO permissions (namespace, resource, action, description, deprecated_at)
+	SELECT $1, resource, action, description, CASE WHEN deprecated THEN now() END
+	FROM unnest($2::text[], $3::text[], $4::text[], $5::bool[]) AS d(resource, action, description, deprecated)
+	ON CONFLICT (resource, action) DO UPDATE
+	SET description = EXCLUDED.description,
+	    deprecated_at = CASE WHEN EXCLUDED.deprecated_at IS NULL THEN NULL
+	                         ELSE COALESCE(permissions.deprecated_at, EXCLUDED.deprecated_at) END
+	WHERE permissions.namespace = EXCLUDED.namespace`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, namespace, resources, actions, descriptions, deprecated)
+	if err != nil {
+		return fmt.Errorf("failed to upsert permissions: %w", err)
+	}
+	// a conflicting row from another namespace is left alone by the WHERE
+	if int(tag.RowsAffected()) != n {
+		return domain.ErrNamespaceOwned
+	}
+	return nil
+}
+
+// SetDefaultGrants also keeps the grant to platform admins, who hold every
+// permission.
+func (r *permissionRepo) SetDefaultGrants(ctx context.Context, p domain.Permission, roles []string) error {
+	q := conn(ctx, r.db)
+	const clear = `
+	DELETE FROM role_permissions
+	WHERE scope = 'membership'
+	AND resource = $1
+	AND action = $2`
+	if _, err := q.Exec(ctx, clear, p.Resource, p.Action); err != nil {
+		return fmt.Errorf("failed to clear default grants: %w", err)
+	}
+
+	const grant = `
+	INSERT INTO role_permissions (scope, role, resource, action)
+	SELECT 'membership', role, $2, $3 FROM unnest($1::text[]) AS r(role)
+	UNION ALL
+	SELECT 'global', 'admin', $2, $3
+	ON CONFLICT DO NOTHING`
+	if _, err := q.Exec(ctx, grant, roles, p.Resource, p.Action); err != nil {
+		return fmt.Errorf("failed to set default grants: %w", err)
+	}
+	return nil
+}
+
+func (r *permissionRepo) DeprecateMissing(ctx context.Context, namespace string, keep []domain.Permission) ([]domain.Permission, error) {
+	resources := make([]string, len(keep))
+	actions := make([]string, len(keep))
+	for i, p := range keep {
+		resources[i] = p.Resource
+		actions[i] = p.Action
+	}
+
+	const query = `
+	UPDATE permissions p
+	SET deprecated_at = now()
+	WHERE p.namespace = $1
+	AND p.deprecated_at IS NULL
+	AND (p.resource, p.action) NOT IN (
+		SELECT resource, action FROM unnest($2::text[], $3::text[]) AS k(resource, action)
+	)
+	RETURNING p.resource, p.action`
+	rows, err := conn(ctx, r.db).Query(ctx, query, namespace, resources, actions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deprecate permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var perms []domain.Permission
+	for rows.Next() {
+		var p domain.Permission
+		if err := rows.Scan(&p.Resource, &p.Action); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		perms = append(perms, p)
+	}
+	return perms, rows.Err()
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type policyConditionRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPolicyConditionRepository(db *pgxpool.Pool) *policyConditionRepo {
+	return &policyConditionRepo{
+		db: db,
+	}
+}
+
+const policyConditionColumns = `
+	c.id, COALESCE(c.business_id::text, ''), c.resource, c.action, c.role_scope, c.role,
+	COALESCE(c.custom_role_id::text, ''), c.expression, c.mode, c.description,
+	COALESCE(c.created_by::text, ''), c.created_at`
+
+func scanPolicyCondition(row pgx.Row) (*domain.PolicyCondition, error) {
+	var c domain.PolicyCondition
+	err := row.Scan(
+		&c.ID,
+		&c.BusinessID,
+		&c.Resource,
+		&c.Action,
+		&c.RoleScope,
+		&c.Role,
+		&c.CustomRoleID,
+		&c.Expression,
+		&c.Mode,
+		&c.Description,
+		&c.CreatedBy,
+		&c.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *policyConditionRepo) Create(ctx context.Context, c *domain.PolicyCondition) error {
+	const query = `
+	INSERT INTO policy_conditions (business_id, resource, action, role_scope, role, custom_role_id,
+	                               expression, mode, description, created_by)
+	VALUES (NULLIF($1, '')::uuid, $2, $3, $4, $5, NULLIF($6, '')::uuid, $7, $8, $9, NULLIF($10, '')::uuid)
+	RETURNING id, created_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, c.BusinessID, c.Resource, c.Action, c.RoleScope, c.Role,
+		c.CustomRoleID, c.Expression, c.Mode, c.Description, c.CreatedBy).Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create policy condition: %w", err)
+	}
+	return nil
+}
+
+func (r *policyConditionRepo) GetByID(ctx context.Context, id string) (*domain.PolicyCondition, error) {
+	query := `SELECT` + policyConditionColumns + `
+	FROM policy_conditions c
+	WHERE c.id = $1`
+	c, err := scanPolicyCondition(conn(ctx, r.db).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrConditionNotFound
+		}
+		return nil, fmt.Errorf("failed to get policy condition: %w", err)
+	}
+	return c, nil
+}
+
+func (r *policyConditionRepo) Delete(ctx context.Context, id string) error {
+	const query = `DELETE FROM policy_conditions WHERE id = $1`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete policy condition: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrConditionNotFound
+	}
+	return nil
+}
+
+func (r *policyConditionRepo) ListByBusiness(ctx context.Context, businessID string) ([]*domain.PolicyCondition, error) {
+	query := `SELECT` + policyConditionColumns + `
+	FROM policy_conditions c
+	WHERE c.business_id IS NOT DISTINCT FROM NULLIF($1, '')::uuid
+	ORDER BY c.created_at`
+	return r.list(ctx, query, businessID)
+}
+
+func (r *policyConditionRepo) ListApplicable(ctx context.Context, businessID string) ([]*domain.PolicyCondition, error) {
+	if businessID == "" {
+		return r.ListByBusiness(ctx, "")
+	}
+	query := businessAncestorsCTE("$1") + `
+	SELECT` + policyConditionColumns + `
+	FROM policy_conditions c
+	WHERE c.business_id IS NULL
+	OR c.business_id IN (SELECT id FROM ancestors)
+	ORDER BY c.created_at`
+	return r.list(ctx, query, businessID)
+}
+
+func (r *policyConditionRepo) list(ctx context.Context, query string, args ...any) ([]*domain.PolicyCondition, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policy conditions: %w", err)
+	}
+	defer rows.Close()
+
+	var conditions []*domain.PolicyCondition
+	for rows.Next() {
+		c, err := scanPolicyCondition(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan policy condition: %w", err)
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, rows.Err()
+}
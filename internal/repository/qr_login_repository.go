@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type qrLoginRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewQRLoginRepository(db *pgxpool.Pool) *qrLoginRepo {
+	return &qrLoginRepo{
+		db: db,
+	}
+}
+
+const qrLoginColumns = `id, poll_secret_hash, status, COALESCE(user_id::text, ''), ip_address, user_agent,
+	location, expires_at, created_at`
+
+func scanQRLogin(row pgx.Row) (*domain.QRLoginSession, error) {
+	var s domain.QRLoginSession
+	err := row.Scan(
+		&s.ID,
+		&s.PollSecretHash,
+		&s.Status,
+		&s.UserID,
+		&s.Client.IPAddress,
+		&s.Client.UserAgent,
+		&s.Client.Location,
+		&s.ExpiresAt,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrQRLoginNotFound
+		}
+		return nil, fmt.Errorf("failed to scan qr login session: %w", err)
+	}
+	return &s, nil
+}
+
+func (r *qrLoginRepo) Create(ctx context.Context, s *domain.QRLoginSession) error {
+	const query = `
+	INSERT INTO qr_login_sessions (id, poll_secret_hash, ip_address, user_agent, location, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	RETURNING status, created_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, s.ID, s.PollSecretHash, s.Client.IPAddress, s.Client.UserAgent,
+		s.Client.Location, s.ExpiresAt).Scan(&s.Status, &s.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create qr login session: %w", err)
+	}
+	return nil
+}
+
+func (r *qrLoginRepo) Get(ctx context.Context, id string) (*domain.QRLoginSession, error) {
+	query := `
+	SELECT ` + qrLoginColumns + `
+	FROM qr_login_sessions
+	WHERE id = $1
+	AND status <> 'consumed'
+	AND expires_at > now()`
+	return scanQRLogin(conn(ctx, r.db).QueryRow(ctx, query, id))
+}
+
+func (r *qrLoginRepo) Scan(ctx context.Context, id, userID string) (*domain.QRLoginSession, error) {
+	query := `
+	UPDATE qr_login_sessions
+	SET status = 'scanned', user_id = $2
+	WHERE id = $1
+	AND status = 'pending'
+	AND expires_at > now()
+	RETURNING ` + qrLoginColumns
+	return scanQRLogin(conn(ctx, r.db).QueryRow(ctx, query, id, userID))
+}
+
+func (r *qrLoginRepo) Resolve(ctx context.Context, id, userID string, approve bool) error {
+	status := domain.QRLoginStatusDenied
+	if approve {
+		status = domain.QRLoginStatusApproved
+	}
+	const query = `
+	UPDATE qr_login_sessions
+	SET status = $3
+	WHERE id = $1
+	AND user_id = $2
+	AND status = 'scanned'
+	AND expires_at > now()`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id, userID, status)
+	if err != nil {
+		return fmt.Errorf("failed to resolve qr login session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrQRLoginNotFound
+	}
+	return nil
+}
+
+func (r *qrLoginRepo) Consume(ctx context.Context, id string) (string, error) {
+	const query = `
+	UPDATE qr_login_sessions
+	SET status = 'consumed'
+	WHERE id = $1
+	AND status = 'approved'
+	AND expires_at > now()
+	RETURNING user_id::text`
+	var userID string
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", domain.ErrQRLoginNotFound
+		}
+		return "", fmt.Errorf("failed to consume qr login session: %w", err)
+	}
+	return userID, nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type relationTupleRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewRelationTupleRepository(db *pgxpool.Pool) *relationTupleRepo {
+	return &relationTupleRepo{
+		db: db,
+	}
+}
+
+// Write stores writes and removes deletes under a new revision, which it
+// returns. Run it inside a transaction so the batch and the revision are
+// applied atomically.
+func (r *relationTupleRepo) Write(ctx context.Context, writes, deletes []domain.RelationTuple) (int64, error) {
+	q := conn(ctx, r.db)
+
+	revision, err := nextRelationRevision(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+
+	const insert = `
+	INSERT INTO relation_tuples (object_type, object_id, relation, subject_type, subject_id, subject_relation, created_revision)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT DO NOTHING`
+	for _, t := range writes {
+		if _, err := q.Exec(ctx, insert, t.Object.Type, t.Object.ID, t.Relation,
+			t.Subject.Type, t.Subject.ID, t.Subject.Relation, revision); err != nil {
+			return 0, fmt.Errorf("failed to write tuple %s: %w", t, err)
+		}
+	}
+
+	const remove = `
+	DELETE FROM relation_tuples
+	WHERE object_type = $1 AND object_id = $2 AND relation = $3
+	AND subject_type = $4 AND subject_id = $5 AND subject_relation = $6`
+	for _, t := range deletes {
+		if _, err := q.Exec(ctx, remove, t.Object.Type, t.Object.ID, t.Relation,
+			t.Subject.Type, t.Subject.ID, t.Subject.Relation); err != nil {
+			return 0, fmt.Errorf("failed to delete tuple %s: %w", t, err)
+		}
+	}
+	return revision, nil
+}
+
+// ReassignUser moves the tuples naming user fromUserID as their subject to
+// toUserID under a new revision, which it returns. Tuples the target already
+// holds are dropped from the source. Run it inside a transaction.
+func (r *relationTupleRepo) ReassignUser(ctx context.Context, fromUserID, toUserID string) (int64, error) {
+	q := conn(ctx, r.db)
+
+	revision, err := nextRelationRevision(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	const dropDuplicates = `
+	DELETE FROM relation_tuples s
+	USING relation_tuples t
+	WHERE s.subject_type = 'user' AND s.subject_id = $1
+	AND t.subject_type = 'user' AND t.subject_id = $2
+	AND s.object_type = t.object_type AND s.object_id = t.object_id
+	AND s.relation = t.relation AND s.subject_relation = t.subject_relation`
+	if _, err := q.Exec(ctx, dropDuplicates, fromUserID, toUserID); err != nil {
+		return 0, fmt.Errorf("failed to reassign tuples: %w", err)
+	}
+	const reassign = `
+	UPDATE relation_tuples
+	SET subject_id = $2, created_revision = $3
+	WHERE subject_type = 'user' AND subject_id = $1`
+	if _, err := q.Exec(ctx, reassign, fromUserID, toUserID, revision); err != nil {
+		return 0, fmt.Errorf("failed to reassign tuples: %w", err)
+	}
+	return revision, nil
+}
+
+func (r *relationTupleRepo) ReadSubjects(ctx context.Context, object domain.ObjectRef, relation string) ([]domain.SubjectRef, error) {
+	const query = `
+	SELECT subject_type, subject_id, subject_relation
+	FROM relation_tuples
+	WHERE object_type = $1
+	AND object_id = $2
+	AND relation = $3`
+	rows, err := conn(ctx, r.db).Query(ctx, query, object.Type, object.ID, relation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tuples: %w", err)
+	}
+	defer rows.Close()
+
+	var subjects []domain.SubjectRef
+	for rows.Next() {
+		var s domain.SubjectRef
+		if err := rows.Scan(&s.Type, &s.ID, &s.Relation); err != nil {
+			return nil, fmt.Errorf("failed to scan tuple: %w", err)
+		}
+		subjects = append(subjects, s)
+	}
+	return subjects, rows.Err()
+}
+
+// ReadObjects uses idx_relation_tuples_subject to find the tuples that
+// point at subject.
+func (r *relationTupleRepo) ReadObjects(ctx context.Context, subject domain.ObjectRef) ([]domain.ObjectRef, error) {
+	const query = `
+	SELECT DISTINCT object_type, object_id
+	FROM relation_tuples
+	WHERE subject_type = $1
+	AND subject_id = $2`
+	rows, err := conn(ctx, r.db).Query(ctx, query, subject.Type, subject.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tuples: %w", err)
+	}
+	defer rows.Close()
+
+	var objects []domain.ObjectRef
+	for rows.Next() {
+		var o domain.ObjectRef
+		if err := rows.Scan(&o.Type, &o.ID); err != nil {
+			return nil, fmt.Errorf("failed to scan tuple: %w", err)
+		}
+		objects = append(objects, o)
+	}
+	return objects, rows.Err()
+}
+
+// CurrentRevision returns the revision of the last committed write visible
+// to this connection.
+func (r *relationTupleRepo) CurrentRevision(ctx context.Context) (int64, error) {
+	var revision int64
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT revision FROM relation_tuple_head`).Scan(&revision)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read revision: %w", err)
+	}
+	return revision, nil
+}
+
+// nextRelationRevision advances the head revision. The row stays locked
+// until the transaction ends, so revisions commit in order.
+func nextRelationRevision(ctx context.Context, q querier) (int64, error) {
+	var revision int64
+	err := q.QueryRow(ctx,
+		`UPDATE relation_tuple_head SET revision = revision + 1 RETURNING revision`).Scan(&revision)
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate revision: %w", err)
+	}
+	return revision, nil
+}
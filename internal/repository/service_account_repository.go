@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type serviceAccountRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewServiceAccountRepository(db *pgxpool.Pool) *serviceAccountRepo {
+	return &serviceAccountRepo{
+		db: db,
+	}
+}
+
+// Create inserts the backing user and the account in one statement. The
+// user gets an address under the reserved .invalid domain and a password
+// hash bcrypt never matches, so it cannot sign in any other way.
+func (r *serviceAccountRepo) Create(ctx context.Context, a *domain.ServiceAccount) error {
+	const query = `
+	WITH u AS (
+		INSERT INTO users (email, first_name, last_name, role, password)
+		VALUES ($1 || '@service-accounts.invalid', $2, '', 'service', '!')
+		RETURNING id
+	)
+	INSERT INTO service_accounts (client_id, user_id, name, secret_hash, scopes, created_by)
+	SELECT $1, u.id, $2, $3, $4, NULLIF($5, '')::uuid FROM u
+	RETURNING user_id, created_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, a.ClientID, a.Name, a.SecretHash, a.Scopes, a.CreatedBy).
+		Scan(&a.UserID, &a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create service account: %w", err)
+	}
+	a.IsActive = true
+	return nil
+}
+
+func (r *serviceAccountRepo) GetByClientID(ctx context.Context, clientID string) (*domain.ServiceAccount, error) {
+	const query = `
+	SELECT sa.client_id, sa.user_id, sa.name, sa.secret_hash, sa.scopes, COALESCE(u.is_active, false),
+	       COALESCE(sa.created_by::text, ''), sa.created_at
+	FROM service_accounts sa
+	JOIN users u ON u.id = sa.user_id
+	WHERE sa.client_id = $1`
+	var a domain.ServiceAccount
+	err := conn(ctx, r.db).QueryRow(ctx, query, clientID).Scan(
+		&a.ClientID,
+		&a.UserID,
+		&a.Name,
+		&a.SecretHash,
+		&a.Scopes,
+		&a.IsActive,
+		&a.CreatedBy,
+		&a.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrServiceAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to get service account: %w", err)
+	}
+	return &a, nil
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type sharedDeviceRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSharedDeviceRepository(db *pgxpool.Pool) *sharedDeviceRepo {
+	return &sharedDeviceRepo{
+		db: db,
+	}
+}
+
+func (r *sharedDeviceRepo) Create(ctx context.Context, d *domain.SharedDevice) error {
+	const query = `
+	INSERT INTO shared_devices (business_id, name, secret_hash, created_by)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, created_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, d.BusinessID, d.Name, d.SecretHash, d.CreatedBy).
+		Scan(&d.ID, &d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create shared device: %w", err)
+	}
+	return nil
+}
+
+func (r *sharedDeviceRepo) GetByID(ctx context.Context, id string) (*domain.SharedDevice, error) {
+	const query = `
+	SELECT id, business_id, name, secret_hash, COALESCE(created_by::text, ''), created_at, revoked_at
+	FROM shared_devices
+	WHERE id = $1
+	AND revoked_at IS NULL`
+	var d domain.SharedDevice
+	err := conn(ctx, r.db).QueryRow(ctx, query, id).Scan(
+		&d.ID,
+		&d.BusinessID,
+		&d.Name,
+		&d.SecretHash,
+		&d.CreatedBy,
+		&d.CreatedAt,
+		&d.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSharedDeviceNotFound
+		}
+		return nil, fmt.Errorf("failed to get shared device: %w", err)
+	}
+	return &d, nil
+}
+
+func (r *sharedDeviceRepo) Revoke(ctx context.Context, id string) error {
+	const query = `
+	UPDATE shared_devices
+	SET revoked_at = now()
+	WHERE id = $1
+	AND revoked_at IS NULL`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke shared device: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSharedDeviceNotFound
+	}
+	return nil
+}
+
+func (r *sharedDeviceRepo) SetPin(ctx context.Context, deviceID, userID, pinHash string) error {
+	const query = `
+	INSERT INTO shared_device_pins (device_id, user_id, pin_hash)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (device_id, user_id) DO UPDATE
+	SET pin_hash = EXCLUDED.pin_hash, failed_attempts = 0, lockouts = 0, locked_until = NULL, updated_at = now()`
+	_, err := conn(ctx, r.db).Exec(ctx, query, deviceID, userID, pinHash)
+	if err != nil {
+		return fmt.Errorf("failed to set device pin: %w", err)
+	}
+	return nil
+}
+
+// ReservePinAttempt takes the attempt in the same statement that checks the
+// lockout, so parallel guesses cannot all pass the check before any failure
+// is recorded. A correct PIN gives the attempt back with ResetPinFailures,
+// but lockouts stay counted until the PIN is set again.
+func (r *sharedDeviceRepo) ReservePinAttempt(ctx context.Context, deviceID, userID string, maxAttempts int,
+	lockout time.Duration, maxLockouts int) (*domain.DevicePin, error) {
+	const query = `
+	UPDATE shared_device_pins
+	SET failed_attempts = CASE WHEN failed_attempts + 1 >= $3 THEN 0 ELSE failed_attempts + 1 END,
+	    lockouts = CASE WHEN failed_attempts + 1 >= $3 THEN lockouts + 1 ELSE lockouts END,
+	    locked_until = CASE
+	        WHEN failed_attempts + 1 < $3 OR lockouts + 1 >= $5 THEN NULL
+	        ELSE now() + make_interval(secs => $4 * power(2, lockouts))
+	    END
+	WHERE device_id = $1
+	AND user_id = $2
+	AND lockouts < $5
+	AND (locked_until IS NULL OR locked_until <= now())
+	RETURNING device_id, user_id, pin_hash, failed_attempts, lockouts, locked_until`
+	var p domain.DevicePin
+	err := conn(ctx, r.db).QueryRow(ctx, query, deviceID, userID, maxAttempts, lockout.Seconds(), maxLockouts).Scan(
+		&p.DeviceID,
+		&p.UserID,
+		&p.PinHash,
+		&p.FailedAttempts,
+		&p.Lockouts,
+		&p.LockedUntil,
+	)
+	if err == nil {
+		return &p, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to reserve pin attempt: %w", err)
+	}
+
+	const lockouts = `
+	SELECT lockouts
+	FROM shared_device_pins
+	WHERE device_id = $1
+	AND user_id = $2`
+	var n int
+	err = conn(ctx, r.db).QueryRow(ctx, lockouts, deviceID, userID).Scan(&n)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, domain.ErrInvalidCredentials
+	case err != nil:
+		return nil, fmt.Errorf("failed to get device pin: %w", err)
+	case n >= maxLockouts:
+		return nil, domain.ErrPinDisabled
+	}
+	return nil, domain.ErrPinLocked
+}
+
+func (r *sharedDeviceRepo) ResetPinFailures(ctx context.Context, deviceID, userID string) error {
+	const query = `
+	UPDATE shared_device_pins
+	SET failed_attempts = 0, locked_until = NULL
+	WHERE device_id = $1
+	AND user_id = $2`
+	_, err := conn(ctx, r.db).Exec(ctx, query, deviceID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to reset pin failures: %w", err)
+	}
+	return nil
+}
+
+func (r *sharedDeviceRepo) ListUsers(ctx context.Context, deviceID string) ([]*domain.DeviceUser, error) {
+	const query = `
+	SELECT u.id, u.first_name, u.last_name
+	FROM shared_device_pins p
+	JOIN users u ON u.id = p.user_id
+	WHERE p.device_id = $1
+	AND u.is_active
+	ORDER BY u.first_name, u.last_name`
+	rows, err := conn(ctx, r.db).Query(ctx, query, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list device users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*domain.DeviceUser
+	for rows.Next() {
+		var u domain.DeviceUser
+		if err := rows.Scan(&u.UserID, &u.FirstName, &u.LastName); err != nil {
+			return nil, fmt.Errorf("failed to scan device user: %w", err)
+		}
+		users = append(users, &u)
+	}
+	return users, rows.Err()
+}
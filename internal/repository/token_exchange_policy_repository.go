@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type tokenExchangePolicyRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewTokenExchangePolicyRepository(db *pgxpool.Pool) *tokenExchangePolicyRepo {
+	return &tokenExchangePolicyRepo{
+		db: db,
+	}
+}
+
+func (r *tokenExchangePolicyRepo) Upsert(ctx context.Context, p *domain.TokenExchangePolicy) error {
+	const query = `
+	INSERT INTO token_exchange_policies (client_id, audience, scopes, actor_roles, created_by)
+	VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid)
+	ON CONFLICT (client_id, audience) DO UPDATE
+	SET scopes = EXCLUDED.scopes, actor_roles = EXCLUDED.actor_roles, created_by = EXCLUDED.created_by,
+	    created_at = now()
+	RETURNING created_at`
+	err := conn(ctx, r.db).QueryRow(ctx, query, p.ClientID, p.Audience, p.Scopes, p.ActorRoles, p.CreatedBy).
+		Scan(&p.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return domain.ErrServiceAccountNotFound
+		}
+		return fmt.Errorf("failed to save token exchange policy: %w", err)
+	}
+	return nil
+}
+
+func (r *tokenExchangePolicyRepo) Get(ctx context.Context, clientID, audience string) (*domain.TokenExchangePolicy, error) {
+	const query = `
+	SELECT client_id, audience, scopes, actor_roles, COALESCE(created_by::text, ''), created_at
+	FROM token_exchange_policies
+	WHERE client_id = $1
+	AND audience = $2`
+	var p domain.TokenExchangePolicy
+	err := conn(ctx, r.db).QueryRow(ctx, query, clientID, audience).Scan(
+		&p.ClientID,
+		&p.Audience,
+		&p.Scopes,
+		&p.ActorRoles,
+		&p.CreatedBy,
+		&p.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrExchangePolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to get token exchange policy: %w", err)
+	}
+	return &p, nil
+}
+
+func (r *tokenExchangePolicyRepo) Delete(ctx context.Context, clientID, audience string) error {
+	const query = `DELETE FROM token_exchange_policies WHERE client_id = $1 AND audience = $2`
+	tag, err := conn(ctx, r.db).Exec(ctx, query, clientID, audience)
+	if err != nil {
+		return fmt.Errorf("failed to delete token exchange policy: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrExchangePolicyNotFound
+	}
+	return nil
+}
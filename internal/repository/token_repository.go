@@ -35,9 +35,40 @@ func (r *tokenRepo) Create(ctx context.Context, userID, tokenHash string, expire
 	}
 	return nil
 }
+func (r *tokenRepo) CreateForBusiness(ctx context.Context, userID, businessID, tokenHash string, expiresAt time.Time) error {
+	const query = `
+		INSERT INTO refresh_tokens (user_id, business_id, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (token_hash) DO UPDATE
+		   SET business_id = EXCLUDED.business_id,
+		       expires_at = EXCLUDED.expires_at,
+		       created_at = now()
+	`
+	_, err := r.db.Exec(ctx, query, userID, businessID, tokenHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("save refresh token: %w", err)
+	}
+	return nil
+}
+func (r *tokenRepo) CreateForClient(ctx context.Context, userID, clientID, scope, tokenHash string, expiresAt time.Time) error {
+	const query = `
+		INSERT INTO refresh_tokens (user_id, client_id, scope, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (token_hash) DO UPDATE
+		   SET client_id = EXCLUDED.client_id,
+		       scope = EXCLUDED.scope,
+		       expires_at = EXCLUDED.expires_at,
+		       created_at = now()
+	`
+	_, err := r.db.Exec(ctx, query, userID, clientID, scope, tokenHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("save refresh token: %w", err)
+	}
+	return nil
+}
 func (r *tokenRepo) FindByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
 	const query = `
-	SELECT id, user_id, token_hash, expires_at, created_at
+	SELECT id, user_id, COALESCE(business_id::text, ''), COALESCE(client_id, ''), scope, token_hash, expires_at, created_at
 	FROM refresh_tokens
 	Where token_hash = $1
 	AND expires_at > now()
@@ -46,6 +77,9 @@ func (r *tokenRepo) FindByHash(ctx context.Context, hash string) (*domain.Refres
 	err := r.db.QueryRow(ctx, query, hash).Scan(
 		&rt.ID,
 		&rt.UserID,
+		&rt.BusinessID,
+		&rt.ClientID,
+		&rt.Scope,
 		&rt.TokenHash,
 		&rt.ExpiresAt,
 		&rt.CreatedAt,
@@ -82,3 +116,45 @@ func (r *tokenRepo) DeleteAllByUserID(ctx context.Context, userID string) error
 	}
 	return nil
 }
+
+func (r *tokenRepo) ListSessions(ctx context.Context, userID string) ([]*domain.Session, error) {
+	const query = `
+	SELECT rt.id, COALESCE(rt.client_id, ''), COALESCE(c.name, ''), COALESCE(rt.business_id::text, ''), rt.scope,
+	       rt.created_at, rt.expires_at
+	FROM refresh_tokens rt
+	LEFT JOIN oauth_clients c ON c.client_id = rt.client_id
+	WHERE rt.user_id = $1
+	AND rt.expires_at > now()
+	ORDER BY rt.created_at DESC`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*domain.Session
+	for rows.Next() {
+		var s domain.Session
+		if err := rows.Scan(&s.ID, &s.ClientID, &s.ClientName, &s.BusinessID, &s.Scope,
+			&s.CreatedAt, &s.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, &s)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *tokenRepo) DeleteSession(ctx context.Context, userID, sessionID string) error {
+	const query = `
+	DELETE FROM refresh_tokens
+	WHERE id = $1
+	AND user_id = $2`
+	tag, err := r.db.Exec(ctx, query, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type userEventRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewUserEventRepository(db *pgxpool.Pool) *userEventRepo {
+	return &userEventRepo{
+		db: db,
+	}
+}
+
+func (r *userEventRepo) Publish(ctx context.Context, e *domain.UserEvent) error {
+	data := e.Data
+	if data == nil {
+		data = map[string]string{}
+	}
+	const query = `
+	INSERT INTO user_events (type, user_id, data)
+	VALUES ($1, $2, $3)
+	RETURNING id, created_at`
+	if err := conn(ctx, r.db).QueryRow(ctx, query, e.Type, e.UserID, data).Scan(&e.ID, &e.CreatedAt); err != nil {
+		return fmt.Errorf("failed to publish user event: %w", err)
+	}
+	return nil
+}
+
+func (r *userEventRepo) List(ctx context.Context, afterID int64, limit int) ([]*domain.UserEvent, error) {
+	const query = `
+	SELECT id, type, user_id, data, created_at
+	FROM user_events
+	WHERE id > $1
+	ORDER BY id
+	LIMIT $2`
+	rows, err := conn(ctx, r.db).Query(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.UserEvent
+	for rows.Next() {
+		var e domain.UserEvent
+		if err := rows.Scan(&e.ID, &e.Type, &e.UserID, &e.Data, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user event: %w", err)
+		}
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type userMergeRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewUserMergeRepository(db *pgxpool.Pool) *userMergeRepo {
+	return &userMergeRepo{
+		db: db,
+	}
+}
+
+// mergeStatements run in order with the source user as $1 and the target as
+// $2. Where both users hold a row that must stay unique, the target's wins;
+// a business owned by the source stays owned through the target.
+var mergeStatements = []struct {
+	what  string
+	query string
+}{
+	{"memberships", `
+	UPDATE user_business_memberships t
+	SET role = 'owner', custom_role_id = NULL
+	FROM user_business_memberships s
+	WHERE s.user_id = $1 AND t.user_id = $2
+	AND s.business_id = t.business_id
+	AND s.role = 'owner'`},
+	{"memberships", `
+	DELETE FROM user_business_memberships s
+	USING user_business_memberships t
+	WHERE s.user_id = $1 AND t.user_id = $2
+	AND s.business_id = t.business_id`},
+	{"memberships", `UPDATE user_business_memberships SET user_id = $2 WHERE user_id = $1`},
+	{"linked identities", `UPDATE linked_identities SET user_id = $2 WHERE user_id = $1`},
+	{"sessions", `UPDATE refresh_tokens SET user_id = $2 WHERE user_id = $1`},
+	{"oauth consents", `
+	DELETE FROM oauth_consents s
+	USING oauth_consents t
+	WHERE s.user_id = $1 AND t.user_id = $2
+	AND s.client_id = t.client_id`},
+	{"oauth consents", `UPDATE oauth_consents SET user_id = $2 WHERE user_id = $1`},
+	{"device pins", `
+	DELETE FROM shared_device_pins s
+	USING shared_device_pins t
+	WHERE s.user_id = $1 AND t.user_id = $2
+	AND s.device_id = t.device_id`},
+	{"device pins", `UPDATE shared_device_pins SET user_id = $2 WHERE user_id = $1`},
+	{"ownership transfers", `
+	UPDATE ownership_transfers
+	SET status = 'cancelled', resolved_at = now()
+	WHERE status = 'pending'
+	AND ((from_user_id = $1 AND to_user_id = $2) OR (from_user_id = $2 AND to_user_id = $1))`},
+	{"ownership transfers", `UPDATE ownership_transfers SET from_user_id = $2 WHERE from_user_id = $1`},
+	{"ownership transfers", `UPDATE ownership_transfers SET to_user_id = $2 WHERE to_user_id = $1`},
+	{"merge requests", `
+	UPDATE account_merge_requests
+	SET status = 'completed'
+	WHERE status = 'pending'
+	AND ((source_user_id = $1 AND target_user_id = $2) OR (source_user_id = $2 AND target_user_id = $1))`},
+	// the identities behind the rest moved to the target; linking them
+	// again files a fresh request
+	{"merge requests", `
+	DELETE FROM account_merge_requests
+	WHERE status = 'pending'
+	AND (source_user_id = $1 OR target_user_id = $1)`},
+	{"tombstones", `UPDATE user_merges SET target_user_id = $2 WHERE target_user_id = $1`},
+	{"user", `UPDATE users SET is_active = false, updated_at = now() WHERE id = $1`},
+}
+
+// LockUsers locks in id order, so merges of A into B and of B into A cannot
+// deadlock either.
+func (r *userMergeRepo) LockUsers(ctx context.Context, userIDs ...string) error {
+	const query = `
+	SELECT id
+	FROM users
+	WHERE id = ANY($1::uuid[])
+	ORDER BY id
+	FOR UPDATE`
+	rows, err := conn(ctx, r.db).Query(ctx, query, userIDs)
+	if err != nil {
+		return fmt.Errorf("failed to lock users: %w", err)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to lock users: %w", err)
+	}
+	return nil
+}
+
+func (r *userMergeRepo) Merge(ctx context.Context, m *domain.UserMerge) error {
+	q := conn(ctx, r.db)
+
+	if m.MovePhone {
+		if err := r.movePhone(ctx, m.SourceUserID, m.TargetUserID); err != nil {
+			return err
+		}
+	}
+	if m.MoveEmail {
+		if err := r.moveEmail(ctx, m.SourceUserID, m.TargetUserID); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range mergeStatements {
+		if _, err := q.Exec(ctx, s.query, m.SourceUserID, m.TargetUserID); err != nil {
+			return fmt.Errorf("failed to merge %s: %w", s.what, err)
+		}
+	}
+
+	const tombstone = `
+	INSERT INTO user_merges (source_user_id, target_user_id, merged_by)
+	VALUES ($1, $2, NULLIF($3, '')::uuid)
+	RETURNING created_at`
+	if err := q.QueryRow(ctx, tombstone, m.SourceUserID, m.TargetUserID, m.MergedBy).Scan(&m.CreatedAt); err != nil {
+		return fmt.Errorf("failed to record user merge: %w", err)
+	}
+	return nil
+}
+
+// movePhone and moveEmail clear the source before writing the target so the
+// unique indexes hold.
+func (r *userMergeRepo) movePhone(ctx context.Context, sourceID, targetID string) error {
+	q := conn(ctx, r.db)
+	var phone string
+	var verified bool
+	err := q.QueryRow(ctx, `SELECT phone, phone_verified FROM users WHERE id = $1`, sourceID).Scan(&phone, &verified)
+	if err != nil {
+		return fmt.Errorf("failed to read source phone: %w", err)
+	}
+	if _, err := q.Exec(ctx, `UPDATE users SET phone = NULL, phone_verified = false WHERE id = $1`, sourceID); err != nil {
+		return fmt.Errorf("failed to clear source phone: %w", err)
+	}
+	const query = `UPDATE users SET phone = $2, phone_verified = $3 WHERE id = $1`
+	if _, err := q.Exec(ctx, query, targetID, phone, verified); err != nil {
+		return fmt.Errorf("failed to move phone: %w", err)
+	}
+	return nil
+}
+
+// moveEmail hands the source's email and, when the target has none, its
+// password to the target. The tombstone keeps a unique placeholder.
+func (r *userMergeRepo) moveEmail(ctx context.Context, sourceID, targetID string) error {
+	q := conn(ctx, r.db)
+	var email, password string
+	var verified bool
+	err := q.QueryRow(ctx, `SELECT email, email_verified, password FROM users WHERE id = $1`, sourceID).
+		Scan(&email, &verified, &password)
+	if err != nil {
+		return fmt.Errorf("failed to read source email: %w", err)
+	}
+	const clear = `
+	UPDATE users
+	SET email = 'merged-' || id || '@merged.invalid', email_verified = false, password = $2
+	WHERE id = $1`
+	if _, err := q.Exec(ctx, clear, sourceID, domain.NoPassword); err != nil {
+		return fmt.Errorf("failed to clear source email: %w", err)
+	}
+	const query = `
+	UPDATE users
+	SET email = $2, email_verified = $3,
+	password = CASE WHEN password = $5 THEN $4 ELSE password END
+	WHERE id = $1`
+	if _, err := q.Exec(ctx, query, targetID, email, verified, password, domain.NoPassword); err != nil {
+		return fmt.Errorf("failed to move email: %w", err)
+	}
+	return nil
+}
+
+func (r *userMergeRepo) Resolve(ctx context.Context, userID string) (string, error) {
+	const query = `SELECT target_user_id FROM user_merges WHERE source_user_id = $1`
+	var target string
+	err := conn(ctx, r.db).QueryRow(ctx, query, userID).Scan(&target)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", domain.ErrUserNotFound
+		}
+		return "", fmt.Errorf("failed to resolve merged user: %w", err)
+	}
+	return target, nil
+}
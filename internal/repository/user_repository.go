@@ -6,6 +6,7 @@ import (
 
 	"github.com/ialekseychuk/my-place-identity/internal/domain"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
 )
@@ -21,16 +22,16 @@ func NewUserRepository(db *pgxpool.Pool) *userRepo {
 }
 
 func (r *userRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-	const sql = `SELECT id, first_name, last_name, email, phone, password, role, is_active, email_verified, phone_verified, created_at, updated_at
+	const sql = `SELECT id, first_name, last_name, email, COALESCE(phone, ''), password, role, is_active, email_verified, phone_verified, is_guest, contact_phone, created_at, updated_at
 		 FROM users
 		 WHERE email = $1`
 
 	var user domain.User
-	err := r.db.QueryRow(ctx,
+	err := conn(ctx, r.db).QueryRow(ctx,
 		sql,
 		email).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email,
 		&user.Phone, &user.Password, &user.Role, &user.IsActive, &user.EmailVerified,
-		&user.PhoneVerified, &user.CreatedAt, &user.UpdatedAt)
+		&user.PhoneVerified, &user.IsGuest, &user.ContactPhone, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		logrus.Debugf("Get user by email: %s, error: %s", email, err)
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -43,25 +44,45 @@ func (r *userRepo) GetByEmail(ctx context.Context, email string) (*domain.User,
 
 func (r *userRepo) Create(ctx context.Context, user *domain.User) error {
 	const sql = `INSERT INTO users (first_name, last_name, email, phone, password, role, 
-			is_active, email_verified, phone_verified, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			is_active, email_verified, phone_verified, is_guest, contact_phone, created_at, updated_at)
+		 VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		 RETURNING id`
-	err := r.db.QueryRow(ctx, sql,
+	err := conn(ctx, r.db).QueryRow(ctx, sql,
 		user.FirstName, user.LastName, user.Email, user.Phone,
 		user.Password, user.Role, user.IsActive,
-		user.EmailVerified, user.PhoneVerified, user.CreatedAt, user.UpdatedAt).Scan(&user.ID)
-	return err
+		user.EmailVerified, user.PhoneVerified, user.IsGuest, user.ContactPhone, user.CreatedAt, user.UpdatedAt).Scan(&user.ID)
+	return uniqueUserError(err)
 }
 
 func (r *userRepo) GetByID(ctx context.Context, id string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.QueryRow(ctx,
-		`SELECT id, first_name, last_name, email, phone, password, role, is_active, email_verified, phone_verified, created_at, updated_at
+	err := conn(ctx, r.db).QueryRow(ctx,
+		`SELECT id, first_name, last_name, email, COALESCE(phone, ''), password, role, is_active, email_verified, phone_verified, is_guest, contact_phone, created_at, updated_at
 		 FROM users
 		 WHERE id = $1`,
 		id).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email,
 		&user.Phone, &user.Password, &user.Role, &user.IsActive, &user.EmailVerified,
-		&user.PhoneVerified, &user.CreatedAt, &user.UpdatedAt)
+		&user.PhoneVerified, &user.IsGuest, &user.ContactPhone, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepo) GetByPhone(ctx context.Context, phone string) (*domain.User, error) {
+	const sql = `SELECT id, first_name, last_name, email, COALESCE(phone, ''), password, role, is_active, email_verified, phone_verified, is_guest, contact_phone, created_at, updated_at
+		 FROM users
+		 WHERE phone = $1`
+
+	var user domain.User
+	err := conn(ctx, r.db).QueryRow(ctx,
+		sql,
+		phone).Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email,
+		&user.Phone, &user.Password, &user.Role, &user.IsActive, &user.EmailVerified,
+		&user.PhoneVerified, &user.IsGuest, &user.ContactPhone, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -70,3 +91,48 @@ func (r *userRepo) GetByID(ctx context.Context, id string) (*domain.User, error)
 	}
 	return &user, nil
 }
+
+// UpgradeGuest turns a guest into a full account in place, keeping its ID.
+func (r *userRepo) UpgradeGuest(ctx context.Context, user *domain.User) error {
+	const sql = `UPDATE users
+		 SET email = $2, phone = NULLIF($3, ''), password = $4, first_name = $5, last_name = $6,
+		 email_verified = false, is_guest = false, contact_phone = '', updated_at = now()
+		 WHERE id = $1 AND is_guest
+		 RETURNING updated_at`
+	err := conn(ctx, r.db).QueryRow(ctx, sql, user.ID, user.Email, user.Phone, user.Password,
+		user.FirstName, user.LastName).Scan(&user.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrNotGuest
+	}
+	if err != nil {
+		return uniqueUserError(err)
+	}
+	user.EmailVerified = false
+	user.IsGuest = false
+	user.ContactPhone = ""
+	return nil
+}
+
+func (r *userRepo) Lock(ctx context.Context, id string) error {
+	var locked string
+	err := conn(ctx, r.db).QueryRow(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, id).Scan(&locked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ErrUserNotFound
+	}
+	return err
+}
+
+// uniqueUserError reports a taken email or phone as the matching domain
+// error.
+func uniqueUserError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		switch pgErr.ConstraintName {
+		case "users_email_key":
+			return domain.ErrEmailExists
+		case "users_phone_key":
+			return domain.ErrPhoneExists
+		}
+	}
+	return err
+}
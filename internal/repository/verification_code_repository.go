@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type verificationCodeRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewVerificationCodeRepository(db *pgxpool.Pool) *verificationCodeRepo {
+	return &verificationCodeRepo{
+		db: db,
+	}
+}
+
+func (r *verificationCodeRepo) Create(ctx context.Context, c *domain.VerificationCode) error {
+	const query = `
+	INSERT INTO verification_codes (user_id, purpose, code_hash, expires_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (user_id, purpose) DO UPDATE
+	SET code_hash = EXCLUDED.code_hash, attempts = 0, expires_at = EXCLUDED.expires_at, created_at = now()`
+	_, err := conn(ctx, r.db).Exec(ctx, query, c.UserID, c.Purpose, c.CodeHash, c.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save verification code: %w", err)
+	}
+	return nil
+}
+
+// ReserveAttempt counts the attempt in the statement that checks the limit,
+// so parallel guesses cannot exceed it.
+func (r *verificationCodeRepo) ReserveAttempt(ctx context.Context, userID, purpose string,
+	maxAttempts int) (*domain.VerificationCode, error) {
+	const query = `
+	UPDATE verification_codes
+	SET attempts = attempts + 1
+	WHERE user_id = $1
+	AND purpose = $2
+	AND attempts < $3
+	AND expires_at > now()
+	RETURNING user_id, purpose, code_hash, attempts, expires_at`
+	var c domain.VerificationCode
+	err := conn(ctx, r.db).QueryRow(ctx, query, userID, purpose, maxAttempts).Scan(
+		&c.UserID,
+		&c.Purpose,
+		&c.CodeHash,
+		&c.Attempts,
+		&c.ExpiresAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to reserve verification attempt: %w", err)
+	}
+	return &c, nil
+}
+
+func (r *verificationCodeRepo) Delete(ctx context.Context, userID, purpose string) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`DELETE FROM verification_codes WHERE user_id = $1 AND purpose = $2`, userID, purpose)
+	if err != nil {
+		return fmt.Errorf("failed to delete verification code: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type acceptInvitationUseCase struct {
+	userRepo       domain.UserRepository
+	tokenRepo      domain.TokenRepository
+	membershipRepo domain.MembershipRepository
+	invitationRepo domain.InvitationRepository
+	domainRules    domain.DomainRuleStore
+	tx             domain.Transactor
+	jwt            *infrastructure.JWTManager
+	accessTTL      time.Duration
+	refreshTTL     time.Duration
+}
+
+func NewAcceptInvitation(userRepo domain.UserRepository, tokenRepo domain.TokenRepository,
+	membershipRepo domain.MembershipRepository, invitationRepo domain.InvitationRepository,
+	domainRules domain.DomainRuleStore, tx domain.Transactor, jwt *infrastructure.JWTManager,
+	accessTTL, refreshTTL time.Duration) domain.AcceptInvitationUseCase {
+	return &acceptInvitationUseCase{
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		membershipRepo: membershipRepo,
+		invitationRepo: invitationRepo,
+		domainRules:    domainRules,
+		tx:             tx,
+		jwt:            jwt,
+		accessTTL:      accessTTL,
+		refreshTTL:     refreshTTL,
+	}
+}
+
+// Execute accepts the invitation as the existing account matching the
+// invitation's email or phone (password must match), or registers a new
+// account with the supplied details. The account, the membership and the
+// invitation update are committed together.
+func (u *acceptInvitationUseCase) Execute(ctx context.Context, req domain.AcceptInvitationRequest) (*domain.User, *domain.AuthToken, error) {
+	inv, err := u.invitationRepo.GetByTokenHash(ctx, infrastructure.GenerateTokenHash(req.Token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if inv.Status(time.Now()) != domain.InvitationStatusPending {
+		return nil, nil, domain.ErrInvitationNotPending
+	}
+
+	var user *domain.User
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		user, err = u.findInvitee(ctx, inv)
+		if err != nil {
+			return err
+		}
+
+		if user != nil {
+			if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+				return domain.ErrInvalidCredentials
+			}
+		} else {
+			user, err = u.createInvitee(ctx, inv, req)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := u.invitationRepo.MarkAccepted(ctx, inv.ID, user.ID); err != nil {
+			return err
+		}
+		return u.membershipRepo.Create(ctx, &domain.Membership{
+			UserID:     user.ID,
+			BusinessID: inv.BusinessID,
+			Role:       inv.Role,
+			IsActive:   true,
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token, err := issueAuthToken(ctx, u.jwt, u.tokenRepo, user, nil, u.accessTTL, u.refreshTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, token, nil
+}
+
+func (u *acceptInvitationUseCase) findInvitee(ctx context.Context, inv *domain.Invitation) (*domain.User, error) {
+	if inv.Email != "" {
+		return u.userRepo.GetByEmail(ctx, inv.Email)
+	}
+	return u.userRepo.GetByPhone(ctx, inv.Phone)
+}
+
+func (u *acceptInvitationUseCase) createInvitee(ctx context.Context, inv *domain.Invitation, req domain.AcceptInvitationRequest) (*domain.User, error) {
+	email := inv.Email
+	if email == "" {
+		email = req.Email
+	}
+	if email == "" || req.Password == "" {
+		return nil, domain.ErrInvalidCredentials
+	}
+	// the rules may have changed since the invitation was sent
+	if err := u.domainRules.Rules().Check(email); err != nil {
+		return nil, err
+	}
+
+	existing, err := u.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, domain.ErrEmailExists
+	}
+
+	hash, err := infrastructure.GeneratePassworHash(req.Password)
+	if err != nil {
+		return nil, err
+	}
+	user := &domain.User{
+		Email:         email,
+		Phone:         inv.Phone,
+		Password:      string(hash),
+		FirstName:     req.FirstName,
+		LastName:      req.LastName,
+		Role:          domain.UserRoleMaster,
+		IsActive:      true,
+		EmailVerified: inv.Email != "",
+		PhoneVerified: inv.Phone != "",
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := u.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type acceptOwnershipTransferUseCase struct {
+	userRepo       domain.UserRepository
+	membershipRepo domain.MembershipRepository
+	transferRepo   domain.OwnershipTransferRepository
+	auditRepo      domain.AuditRepository
+	tx             domain.Transactor
+	notifier       domain.Notifier
+}
+
+func NewAcceptOwnershipTransfer(userRepo domain.UserRepository, membershipRepo domain.MembershipRepository,
+	transferRepo domain.OwnershipTransferRepository, auditRepo domain.AuditRepository, tx domain.Transactor,
+	notifier domain.Notifier) domain.AcceptOwnershipTransferUseCase {
+	return &acceptOwnershipTransferUseCase{
+		userRepo:       userRepo,
+		membershipRepo: membershipRepo,
+		transferRepo:   transferRepo,
+		auditRepo:      auditRepo,
+		tx:             tx,
+		notifier:       notifier,
+	}
+}
+
+// Execute makes the recipient the owner and demotes the previous owner to
+// admin in one transaction.
+func (u *acceptOwnershipTransferUseCase) Execute(ctx context.Context, callerID, transferID string) (*domain.OwnershipTransfer, error) {
+	t, err := u.transferRepo.GetByID(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if t.ToUserID != callerID {
+		return nil, domain.ErrTransferNotFound
+	}
+	if t.EffectiveStatus(time.Now()) != domain.TransferStatusPending {
+		return nil, domain.ErrTransferNotPending
+	}
+
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.transferRepo.MarkAccepted(ctx, t.ID); err != nil {
+			return err
+		}
+		// the offer is void if the sender lost ownership in the meantime
+		if err := requireDirectOwner(ctx, u.membershipRepo, t.FromUserID, t.BusinessID); err != nil {
+			return domain.ErrTransferNotPending
+		}
+
+		previous, err := u.membershipRepo.GetByUserAndBusiness(ctx, t.FromUserID, t.BusinessID)
+		if err != nil {
+			return err
+		}
+		previous.Role = domain.MembershipRoleAdmin
+		if err := u.membershipRepo.UpdateRole(ctx, previous); err != nil {
+			return err
+		}
+		if err := u.membershipRepo.Grant(ctx, &domain.Membership{
+			UserID:     t.ToUserID,
+			BusinessID: t.BusinessID,
+			Role:       domain.MembershipRoleOwner,
+		}); err != nil {
+			return err
+		}
+
+		return u.auditRepo.Record(ctx, domain.AuditEntry{
+			ActorID:      callerID,
+			Action:       "ownership_transfer.accepted",
+			BusinessID:   t.BusinessID,
+			TargetUserID: t.FromUserID,
+			Details:      map[string]string{"transfer_id": t.ID},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	t.Status = domain.TransferStatusAccepted
+
+	owner, err := u.userRepo.GetByID(ctx, t.FromUserID)
+	if err != nil {
+		return nil, err
+	}
+	recipient, err := u.userRepo.GetByID(ctx, t.ToUserID)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil && recipient != nil {
+		notifyTransferParties(ctx, u.notifier, t, owner, recipient, "ownership_transfer_completed")
+	}
+	return t, nil
+}
@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+type listLoginMethodsUseCase struct {
+	userRepo     domain.UserRepository
+	identityRepo domain.LinkedIdentityRepository
+}
+
+func NewListLoginMethods(userRepo domain.UserRepository,
+	identityRepo domain.LinkedIdentityRepository) domain.ListLoginMethodsUseCase {
+	return &listLoginMethodsUseCase{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+	}
+}
+
+func (u *listLoginMethodsUseCase) Execute(ctx context.Context, userID string) ([]*domain.LoginMethod, error) {
+	return loginMethods(ctx, u.userRepo, u.identityRepo, userID)
+}
+
+func loginMethods(ctx context.Context, userRepo domain.UserRepository, identityRepo domain.LinkedIdentityRepository,
+	userID string) ([]*domain.LoginMethod, error) {
+	user, err := userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	var methods []*domain.LoginMethod
+	if user.HasPassword() {
+		methods = append(methods, &domain.LoginMethod{Type: domain.LoginMethodPassword, Email: user.Email})
+	}
+	identities, err := identityRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range identities {
+		methods = append(methods, &domain.LoginMethod{
+			Type:        domain.LoginMethodProvider,
+			ProviderID:  l.ProviderID,
+			Email:       l.Email,
+			CreatedAt:   l.CreatedAt,
+			LastLoginAt: l.LastLoginAt,
+		})
+	}
+	return methods, nil
+}
+
+type startLinkIdentityUseCase struct {
+	start *startFederatedLoginUseCase
+}
+
+func NewStartLinkIdentity(providers map[string]domain.IdentityProvider, stateRepo domain.FederatedLoginStateRepository,
+	ttl time.Duration) domain.StartLinkIdentityUseCase {
+	return &startLinkIdentityUseCase{
+		start: &startFederatedLoginUseCase{
+			providers: providers,
+			stateRepo: stateRepo,
+			ttl:       ttl,
+		},
+	}
+}
+
+func (u *startLinkIdentityUseCase) Execute(ctx context.Context, userID, providerID,
+	redirectURI string) (*domain.FederatedLoginStart, error) {
+	return u.start.begin(ctx, providerID, redirectURI, userID)
+}
+
+type completeLinkIdentityUseCase struct {
+	providers map[string]domain.IdentityProvider
+	stateRepo domain.FederatedLoginStateRepository
+	linker    *identityLinker
+}
+
+func NewCompleteLinkIdentity(providers map[string]domain.IdentityProvider, stateRepo domain.FederatedLoginStateRepository,
+	identityRepo domain.LinkedIdentityRepository, mergeRepo domain.AccountMergeRequestRepository,
+	auditRepo domain.AuditRepository) domain.CompleteLinkIdentityUseCase {
+	return &completeLinkIdentityUseCase{
+		providers: providers,
+		stateRepo: stateRepo,
+		linker: &identityLinker{
+			identityRepo: identityRepo,
+			mergeRepo:    mergeRepo,
+			auditRepo:    auditRepo,
+		},
+	}
+}
+
+func (u *completeLinkIdentityUseCase) Execute(ctx context.Context, userID, providerID, state,
+	code string) (*domain.LinkIdentityResult, error) {
+	provider, ok := u.providers[providerID]
+	if !ok {
+		return nil, domain.ErrIdentityProviderNotFound
+	}
+	s, err := u.stateRepo.Consume(ctx, infrastructure.GenerateTokenHash(state), providerID)
+	if err != nil {
+		return nil, err
+	}
+	if s.UserID != userID {
+		return nil, domain.ErrFederatedStateInvalid
+	}
+	ext, err := provider.Exchange(ctx, code, s.CodeVerifier, s.RedirectURI, s.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	return u.linker.link(ctx, userID, ext)
+}
+
+type linkTelegramUseCase struct {
+	verifier *infrastructure.TelegramAuthVerifier
+	linker   *identityLinker
+}
+
+func NewLinkTelegram(verifier *infrastructure.TelegramAuthVerifier, identityRepo domain.LinkedIdentityRepository,
+	mergeRepo domain.AccountMergeRequestRepository, auditRepo domain.AuditRepository) domain.LinkTelegramUseCase {
+	return &linkTelegramUseCase{
+		verifier: verifier,
+		linker: &identityLinker{
+			identityRepo: identityRepo,
+			mergeRepo:    mergeRepo,
+			auditRepo:    auditRepo,
+		},
+	}
+}
+
+func (u *linkTelegramUseCase) Execute(ctx context.Context, userID string,
+	data map[string]string) (*domain.LinkIdentityResult, error) {
+	if u.verifier == nil {
+		return nil, domain.ErrIdentityProviderNotFound
+	}
+	ext, err := u.verifier.Verify(data, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return u.linker.link(ctx, userID, ext)
+}
+
+// identityLinker attaches a verified external identity to a signed-in user.
+type identityLinker struct {
+	identityRepo domain.LinkedIdentityRepository
+	mergeRepo    domain.AccountMergeRequestRepository
+	auditRepo    domain.AuditRepository
+}
+
+// link never moves an identity between users. If it already signs in to
+// another account, the caller has just proven control of both accounts, so
+// a merge request is filed for an admin instead; the other account keeps
+// its identity until the merge.
+func (l *identityLinker) link(ctx context.Context, userID string,
+	ext *domain.ExternalIdentity) (*domain.LinkIdentityResult, error) {
+	existing, err := l.identityRepo.Get(ctx, ext.ProviderID, ext.Subject)
+	switch {
+	case err == nil && existing.UserID == userID:
+		return &domain.LinkIdentityResult{Identity: existing}, nil
+	case err == nil:
+		req := &domain.AccountMergeRequest{
+			SourceUserID: existing.UserID,
+			TargetUserID: userID,
+			ProviderID:   ext.ProviderID,
+			Subject:      ext.Subject,
+		}
+		if err := l.mergeRepo.Create(ctx, req); err != nil {
+			return nil, err
+		}
+		err = l.auditRepo.Record(ctx, domain.AuditEntry{
+			ActorID:      userID,
+			Action:       "account.merge_requested",
+			TargetUserID: existing.UserID,
+			Details: map[string]string{
+				"merge_request_id": req.ID,
+				"provider_id":      ext.ProviderID,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &domain.LinkIdentityResult{MergeRequest: req}, nil
+	case !errors.Is(err, domain.ErrLinkedIdentityNotFound):
+		return nil, err
+	}
+
+	identity := &domain.LinkedIdentity{
+		ProviderID: ext.ProviderID,
+		Subject:    ext.Subject,
+		UserID:     userID,
+		Email:      ext.Email,
+	}
+	if err := l.identityRepo.Create(ctx, identity); err != nil {
+		return nil, err
+	}
+	err = l.auditRepo.Record(ctx, domain.AuditEntry{
+		ActorID:      userID,
+		Action:       "identity.linked",
+		TargetUserID: userID,
+		Details:      map[string]string{"provider_id": ext.ProviderID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LinkIdentityResult{Identity: identity}, nil
+}
+
+type unlinkIdentityUseCase struct {
+	userRepo     domain.UserRepository
+	identityRepo domain.LinkedIdentityRepository
+	auditRepo    domain.AuditRepository
+	tx           domain.Transactor
+}
+
+func NewUnlinkIdentity(userRepo domain.UserRepository, identityRepo domain.LinkedIdentityRepository,
+	auditRepo domain.AuditRepository, tx domain.Transactor) domain.UnlinkIdentityUseCase {
+	return &unlinkIdentityUseCase{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		auditRepo:    auditRepo,
+		tx:           tx,
+	}
+}
+
+// Execute counts and deletes with the user row locked, so two unlinks of
+// the last two methods cannot both see the other one still there.
+func (u *unlinkIdentityUseCase) Execute(ctx context.Context, userID, providerID string) error {
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.Lock(ctx, userID); err != nil {
+			return err
+		}
+		methods, err := loginMethods(ctx, u.userRepo, u.identityRepo, userID)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, m := range methods {
+			if m.Type == domain.LoginMethodProvider && m.ProviderID == providerID {
+				found = true
+			}
+		}
+		if !found {
+			return domain.ErrLinkedIdentityNotFound
+		}
+		if len(methods) <= 1 {
+			return domain.ErrLastLoginMethod
+		}
+
+		if err := u.identityRepo.Delete(ctx, userID, providerID); err != nil {
+			return err
+		}
+		return u.auditRepo.Record(ctx, domain.AuditEntry{
+			ActorID:      userID,
+			Action:       "identity.unlinked",
+			TargetUserID: userID,
+			Details:      map[string]string{"provider_id": providerID},
+		})
+	})
+}
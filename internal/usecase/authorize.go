@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const pkceMethodS256 = "S256"
+
+type validateAuthorizationUseCase struct {
+	clientRepo domain.OAuthClientRepository
+}
+
+func NewValidateAuthorization(clientRepo domain.OAuthClientRepository) domain.ValidateAuthorizationUseCase {
+	return &validateAuthorizationUseCase{
+		clientRepo: clientRepo,
+	}
+}
+
+func (u *validateAuthorizationUseCase) Execute(ctx context.Context, req domain.AuthorizationRequest) (*domain.OAuthClient, error) {
+	return validateAuthorizationRequest(ctx, u.clientRepo, req)
+}
+
+// validateAuthorizationRequest checks the client and redirect URI first:
+// until both are known to be good, errors must not be redirected anywhere.
+func validateAuthorizationRequest(ctx context.Context, clientRepo domain.OAuthClientRepository,
+	req domain.AuthorizationRequest) (*domain.OAuthClient, error) {
+	client, err := clientRepo.GetByID(ctx, req.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	if req.RedirectURI == "" || !client.HasRedirectURI(req.RedirectURI) {
+		return nil, domain.ErrInvalidRedirectURI
+	}
+
+	if req.ResponseType != "code" {
+		return nil, domain.NewOAuthError(domain.OAuthUnsupportedResponseType, "only response_type=code is supported")
+	}
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != pkceMethodS256 {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "PKCE with code_challenge_method=S256 is required")
+	}
+	if !client.AllowsScopes(domain.ParseScope(req.Scope)) {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidScope, "scope not allowed for this client")
+	}
+	return client, nil
+}
+
+type authorizeUseCase struct {
+	userRepo    domain.UserRepository
+	clientRepo  domain.OAuthClientRepository
+	codeRepo    domain.AuthorizationCodeRepository
+	consentRepo domain.OAuthConsentRepository
+	tx          domain.Transactor
+	codeTTL     time.Duration
+}
+
+func NewAuthorize(userRepo domain.UserRepository, clientRepo domain.OAuthClientRepository,
+	codeRepo domain.AuthorizationCodeRepository, consentRepo domain.OAuthConsentRepository,
+	tx domain.Transactor, codeTTL time.Duration) domain.AuthorizeUseCase {
+	return &authorizeUseCase{
+		userRepo:    userRepo,
+		clientRepo:  clientRepo,
+		codeRepo:    codeRepo,
+		consentRepo: consentRepo,
+		tx:          tx,
+		codeTTL:     codeTTL,
+	}
+}
+
+func (u *authorizeUseCase) Execute(ctx context.Context, req domain.AuthorizationRequest, email, password string) (string, error) {
+	client, err := validateAuthorizationRequest(ctx, u.clientRepo, req)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := u.userRepo.GetByEmail(ctx, email)
+	if err != nil || user == nil {
+		return "", domain.ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return "", domain.ErrInvalidCredentials
+	}
+	if !user.IsActive {
+		return "", domain.ErrUserNotActive
+	}
+
+	codeRaw, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	scopes := domain.ParseScope(req.Scope)
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if len(scopes) > 0 {
+			if err := u.consentRepo.Grant(ctx, user.ID, client.ID, scopes); err != nil {
+				return err
+			}
+		}
+		return u.codeRepo.Create(ctx, &domain.AuthorizationCode{
+			CodeHash:      infrastructure.GenerateTokenHash(codeRaw),
+			ClientID:      client.ID,
+			UserID:        user.ID,
+			RedirectURI:   req.RedirectURI,
+			Scope:         joinScope(scopes),
+			CodeChallenge: req.CodeChallenge,
+			Nonce:         req.Nonce,
+			AuthTime:      now,
+			ExpiresAt:     now.Add(u.codeTTL),
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return codeRaw, nil
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type cancelOwnershipTransferUseCase struct {
+	userRepo     domain.UserRepository
+	transferRepo domain.OwnershipTransferRepository
+	auditRepo    domain.AuditRepository
+	tx           domain.Transactor
+	notifier     domain.Notifier
+}
+
+func NewCancelOwnershipTransfer(userRepo domain.UserRepository, transferRepo domain.OwnershipTransferRepository,
+	auditRepo domain.AuditRepository, tx domain.Transactor, notifier domain.Notifier) domain.CancelOwnershipTransferUseCase {
+	return &cancelOwnershipTransferUseCase{
+		userRepo:     userRepo,
+		transferRepo: transferRepo,
+		auditRepo:    auditRepo,
+		tx:           tx,
+		notifier:     notifier,
+	}
+}
+
+// Execute withdraws (owner) or declines (recipient) a pending transfer.
+func (u *cancelOwnershipTransferUseCase) Execute(ctx context.Context, callerID, transferID string) error {
+	t, err := u.transferRepo.GetByID(ctx, transferID)
+	if err != nil {
+		return err
+	}
+	if callerID != t.FromUserID && callerID != t.ToUserID {
+		return domain.ErrTransferNotFound
+	}
+
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.transferRepo.Cancel(ctx, t.ID); err != nil {
+			return err
+		}
+		return u.auditRepo.Record(ctx, domain.AuditEntry{
+			ActorID:    callerID,
+			Action:     "ownership_transfer.cancelled",
+			BusinessID: t.BusinessID,
+			Details:    map[string]string{"transfer_id": t.ID},
+		})
+	})
+	if err != nil {
+		return err
+	}
+	t.Status = domain.TransferStatusCancelled
+
+	owner, err := u.userRepo.GetByID(ctx, t.FromUserID)
+	if err != nil {
+		return err
+	}
+	recipient, err := u.userRepo.GetByID(ctx, t.ToUserID)
+	if err != nil {
+		return err
+	}
+	if owner != nil && recipient != nil {
+		notifyTransferParties(ctx, u.notifier, t, owner, recipient, "ownership_transfer_cancelled")
+	}
+	return nil
+}
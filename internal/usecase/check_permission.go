@@ -0,0 +1,312 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+	"github.com/ialekseychuk/my-place-identity/internal/policy"
+	"github.com/sirupsen/logrus"
+)
+
+type checkPermissionUseCase struct {
+	userRepo       domain.UserRepository
+	membershipRepo domain.MembershipRepository
+	permissionRepo domain.PermissionRepository
+	conditionRepo  domain.PolicyConditionRepository
+	evaluator      *policy.Evaluator
+	decisionLog    domain.DecisionLogger
+	jwt            *infrastructure.JWTManager
+}
+
+func NewCheckPermission(userRepo domain.UserRepository, membershipRepo domain.MembershipRepository,
+	permissionRepo domain.PermissionRepository, conditionRepo domain.PolicyConditionRepository,
+	evaluator *policy.Evaluator, decisionLog domain.DecisionLogger, jwt *infrastructure.JWTManager) domain.CheckPermissionUseCase {
+	return &checkPermissionUseCase{
+		userRepo:       userRepo,
+		membershipRepo: membershipRepo,
+		permissionRepo: permissionRepo,
+		conditionRepo:  conditionRepo,
+		evaluator:      evaluator,
+		decisionLog:    decisionLog,
+		jwt:            jwt,
+	}
+}
+
+// roleGrant identifies the role a permission was granted through, which
+// decides the policy conditions that apply to it.
+type roleGrant struct {
+	scope        string
+	role         string
+	customRoleID string
+	name         string
+}
+
+// Execute decides every requested permission for the subject within the
+// business. A permission is allowed when the user's global role or their
+// active membership role in the business grants it. The membership may be
+// inherited from a parent organization. Allowed decisions are then subject to
+// the policy conditions attached to the permission or the granting role.
+// Every decision is handed to the decision log. The subject's own token
+// limits the check: guest and service tokens are refused, a business-scoped
+// token fixes the business, and client-issued tokens are limited to their
+// scope.
+func (u *checkPermissionUseCase) Execute(ctx context.Context, req domain.PermissionCheckRequest) ([]domain.PermissionDecision, error) {
+	start := time.Now()
+	userID := req.UserID
+	var tokenClaims map[string]any
+	// OAuth and exchanged subject tokens grant only the permissions in scope
+	var scoped bool
+	var scope []string
+	businessID, err := tokenBusiness(req.BusinessID, req.CallerBusinessID)
+	if err != nil {
+		return nil, err
+	}
+	if req.SubjectToken != "" {
+		claims, err := u.jwt.ValidateAccessToken(req.SubjectToken)
+		if err != nil {
+			return nil, domain.ErrTokenExpired
+		}
+		if claims.Guest || claims.ServiceAccount {
+			return nil, fmt.Errorf("%w: guest and service tokens carry no permissions", domain.ErrPermissionDenied)
+		}
+		if businessID, err = tokenBusiness(businessID, claims.BusinessID); err != nil {
+			return nil, err
+		}
+		if claims.ClientID != "" {
+			scoped, scope = true, domain.ParseScope(claims.Scope)
+		}
+		userID = claims.UserID
+		tokenClaims = map[string]any{
+			"sub":              claims.UserID,
+			"email":            claims.Email,
+			"role":             claims.Role,
+			"business_id":      claims.BusinessID,
+			"business_role":    claims.BusinessRole,
+			"business_role_id": claims.BusinessRoleID,
+		}
+	} else if userID != req.CallerID && !req.CallerService {
+		return nil, fmt.Errorf("%w: only service accounts may check other users", domain.ErrPermissionDenied)
+	}
+
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	if !user.IsActive {
+		decisions := denyAll(req.Permissions, "user is not active")
+		u.logDecisions(req, user.ID, businessID, decisions, start)
+		return decisions, nil
+	}
+
+	known, err := u.permissionRepo.Known(ctx, req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	globalGrants, err := u.grants(ctx, domain.RoleScopeGlobal, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	var membership *domain.Membership
+	var membershipGrants map[domain.Permission]bool
+	if businessID != "" {
+		membership, err = u.membershipRepo.GetEffective(ctx, user.ID, businessID)
+		if err != nil && !errors.Is(err, domain.ErrMembershipNotFound) {
+			return nil, err
+		}
+		if membership != nil && membership.IsActive {
+			membershipGrants, err = u.membershipGrants(ctx, membership)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	conditions, err := u.conditionRepo.ListApplicable(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	input := policy.Input{
+		Time:               time.Now(),
+		IP:                 req.ClientIP,
+		SubjectID:          user.ID,
+		Claims:             tokenClaims,
+		ResourceAttributes: req.ResourceAttributes,
+		BusinessID:         businessID,
+	}
+
+	decisions := make([]domain.PermissionDecision, 0, len(req.Permissions))
+	for _, p := range req.Permissions {
+		d := domain.PermissionDecision{Permission: p}
+		var grant roleGrant
+		switch {
+		case !known[p]:
+			d.Reason = "unknown permission"
+		case scoped && !slices.Contains(scope, p.String()):
+			d.Reason = "not within the subject token's scope"
+		case globalGrants[p]:
+			d.Allowed, d.Reason = true, fmt.Sprintf("granted by global role %q", user.Role)
+			grant = roleGrant{scope: domain.RoleScopeGlobal, role: user.Role, name: user.Role}
+			d.MatchedBy = grant.String()
+		case businessID == "":
+			d.Reason = "no business specified"
+		case membership == nil:
+			d.Reason = "no membership in business"
+		case !membership.IsActive:
+			d.Reason = "membership is not active"
+		case membershipGrants[p] && membership.InheritedFrom != "":
+			d.Allowed, d.Reason = true, fmt.Sprintf("granted by membership role %q inherited from business %s",
+				membership.RoleName(), membership.InheritedFrom)
+			grant = membershipGrant(membership)
+			d.MatchedBy = grant.String()
+		case membershipGrants[p]:
+			d.Allowed, d.Reason = true, fmt.Sprintf("granted by membership role %q", membership.RoleName())
+			grant = membershipGrant(membership)
+			d.MatchedBy = grant.String()
+		default:
+			d.Reason = fmt.Sprintf("not granted to membership role %q", membership.RoleName())
+		}
+		if d.Allowed {
+			u.applyConditions(ctx, &d, grant, conditions, input)
+		}
+		decisions = append(decisions, d)
+	}
+	u.logDecisions(req, user.ID, businessID, decisions, start)
+	return decisions, nil
+}
+
+// tokenBusiness returns the business to check in. A token scoped to a
+// business confines the check to it; a request cannot widen it.
+func tokenBusiness(requested, scopedTo string) (string, error) {
+	switch {
+	case scopedTo == "":
+		return requested, nil
+	case requested == "" || requested == scopedTo:
+		return scopedTo, nil
+	}
+	return "", fmt.Errorf("%w: token is scoped to another business", domain.ErrPermissionDenied)
+}
+
+func (u *checkPermissionUseCase) logDecisions(req domain.PermissionCheckRequest, userID, businessID string,
+	decisions []domain.PermissionDecision, start time.Time) {
+	now := time.Now()
+	latency := now.Sub(start)
+	for _, d := range decisions {
+		u.decisionLog.Log(domain.DecisionRecord{
+			UserID:             userID,
+			BusinessID:         businessID,
+			Permission:         d.Permission,
+			Allowed:            d.Allowed,
+			Reason:             d.Reason,
+			MatchedBy:          d.MatchedBy,
+			ClientIP:           req.ClientIP,
+			ResourceAttributes: req.ResourceAttributes,
+			Latency:            latency,
+			CreatedAt:          now,
+		})
+	}
+}
+
+// applyConditions denies d when an enforced condition matching the grant does
+// not hold or fails to evaluate. Shadow conditions only log the decision they
+// would have made.
+func (u *checkPermissionUseCase) applyConditions(ctx context.Context, d *domain.PermissionDecision, grant roleGrant,
+	conditions []*domain.PolicyCondition, input policy.Input) {
+	input.SubjectRole = grant.name
+	for _, c := range conditions {
+		if !c.AppliesTo(d.Permission) || !conditionMatchesGrant(c, grant) {
+			continue
+		}
+		ok, err := u.evaluator.Eval(ctx, c.Expression, input)
+		if c.Mode == domain.ConditionModeShadow {
+			if err != nil || !ok {
+				logrus.Infof("policy condition %s (shadow) would deny %s to user %s in business %q: ok=%t err=%v",
+					c.ID, d.Permission, input.SubjectID, input.BusinessID, ok, err)
+			}
+			continue
+		}
+		if err != nil {
+			logrus.Warnf("policy condition %s: %v", c.ID, err)
+			d.Allowed, d.Reason = false, fmt.Sprintf("policy condition %s could not be evaluated", c.ID)
+			d.MatchedBy = "condition:" + c.ID
+			return
+		}
+		if !ok {
+			d.Allowed, d.Reason = false, fmt.Sprintf("%s, but policy condition %s is not satisfied", d.Reason, c.ID)
+			d.MatchedBy = "condition:" + c.ID
+			return
+		}
+	}
+}
+
+// conditionMatchesGrant reports whether c targets the role behind grant.
+// Business conditions never restrict platform-wide global role grants.
+func conditionMatchesGrant(c *domain.PolicyCondition, grant roleGrant) bool {
+	if c.BusinessID != "" && grant.scope == domain.RoleScopeGlobal {
+		return false
+	}
+	switch {
+	case c.CustomRoleID != "":
+		return grant.customRoleID == c.CustomRoleID
+	case c.Role != "":
+		return grant.customRoleID == "" && grant.scope == c.RoleScope && grant.role == c.Role
+	}
+	return true
+}
+
+func (g roleGrant) String() string {
+	switch {
+	case g.customRoleID != "":
+		return "custom_role:" + g.customRoleID
+	case g.scope == domain.RoleScopeGlobal:
+		return "global_role:" + g.role
+	}
+	return "membership_role:" + g.role
+}
+
+func membershipGrant(m *domain.Membership) roleGrant {
+	return roleGrant{scope: domain.RoleScopeMembership, role: m.Role, customRoleID: m.CustomRoleID, name: m.RoleName()}
+}
+
+func (u *checkPermissionUseCase) grants(ctx context.Context, scope, role string) (map[domain.Permission]bool, error) {
+	perms, err := u.permissionRepo.ListByRole(ctx, scope, role)
+	if err != nil {
+		return nil, err
+	}
+	return permissionSet(perms), nil
+}
+
+func (u *checkPermissionUseCase) membershipGrants(ctx context.Context, m *domain.Membership) (map[domain.Permission]bool, error) {
+	if m.CustomRoleID == "" {
+		return u.grants(ctx, domain.RoleScopeMembership, m.Role)
+	}
+	perms, err := u.permissionRepo.ListByCustomRole(ctx, m.CustomRoleID)
+	if err != nil {
+		return nil, err
+	}
+	return permissionSet(perms), nil
+}
+
+func permissionSet(perms []domain.Permission) map[domain.Permission]bool {
+	set := make(map[domain.Permission]bool, len(perms))
+	for _, p := range perms {
+		set[p] = true
+	}
+	return set
+}
+
+func denyAll(perms []domain.Permission, reason string) []domain.PermissionDecision {
+	decisions := make([]domain.PermissionDecision, 0, len(perms))
+	for _, p := range perms {
+		decisions = append(decisions, domain.PermissionDecision{Permission: p, Reason: reason})
+	}
+	return decisions
+}
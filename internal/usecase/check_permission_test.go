@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+func TestTokenBusiness(t *testing.T) {
+	tests := []struct {
+		requested, scopedTo string
+		want                string
+		err                 error
+	}{
+		{"", "", "", nil},
+		{"b1", "", "b1", nil},
+		{"", "b1", "b1", nil},
+		{"b1", "b1", "b1", nil},
+		{"b2", "b1", "", domain.ErrPermissionDenied},
+	}
+	for _, tt := range tests {
+		got, err := tokenBusiness(tt.requested, tt.scopedTo)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("tokenBusiness(%q, %q) = %q, %v, want %q, %v", tt.requested, tt.scopedTo, got, err, tt.want, tt.err)
+		}
+	}
+}
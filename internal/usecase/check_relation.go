@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/rebac"
+)
+
+type checkRelationUseCase struct {
+	tupleRepo   domain.RelationTupleRepository
+	engine      *rebac.Engine
+	decisionLog domain.DecisionLogger
+}
+
+func NewCheckRelation(tupleRepo domain.RelationTupleRepository, engine *rebac.Engine,
+	decisionLog domain.DecisionLogger) domain.CheckRelationUseCase {
+	return &checkRelationUseCase{
+		tupleRepo:   tupleRepo,
+		engine:      engine,
+		decisionLog: decisionLog,
+	}
+}
+
+func (u *checkRelationUseCase) Execute(ctx context.Context, object domain.ObjectRef, permission string,
+	subject domain.SubjectRef, consistencyToken string, callerID string, client domain.ClientInfo) (bool, string, error) {
+	start := time.Now()
+	token, err := ensureFresh(ctx, u.tupleRepo, consistencyToken)
+	if err != nil {
+		return false, "", err
+	}
+	allowed, path, err := u.engine.Check(ctx, object, permission, subject)
+	if err != nil {
+		return false, "", err
+	}
+
+	// the log is keyed by user, so the caller stands in for subjects that
+	// are not a single user
+	rec := domain.DecisionRecord{
+		UserID:     callerID,
+		Permission: domain.Permission{Resource: object.Type, Action: permission},
+		Allowed:    allowed,
+		ClientIP:   client.IPAddress,
+		ResourceAttributes: map[string]string{
+			"object":  object.String(),
+			"subject": subject.String(),
+		},
+		CreatedAt: time.Now(),
+	}
+	if allowed {
+		rec.MatchedBy = "relation:" + strings.Join(path, " > ")
+	} else {
+		rec.Reason = "no relation grants the permission"
+	}
+	rec.Latency = rec.CreatedAt.Sub(start)
+	u.decisionLog.Log(rec)
+	return allowed, token, nil
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+const consistencyTokenPrefix = "rev:"
+
+func encodeConsistencyToken(revision int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(consistencyTokenPrefix + strconv.FormatInt(revision, 10)))
+}
+
+func decodeConsistencyToken(token string) (int64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, domain.ErrInvalidConsistency
+	}
+	raw, ok := strings.CutPrefix(string(b), consistencyTokenPrefix)
+	if !ok {
+		return 0, domain.ErrInvalidConsistency
+	}
+	revision, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || revision < 0 {
+		return 0, domain.ErrInvalidConsistency
+	}
+	return revision, nil
+}
+
+// ensureFresh returns a token for the revision reads will observe, failing
+// with ErrConsistencyAhead when the store has not caught up with token yet
+// (e.g. a lagging replica).
+func ensureFresh(ctx context.Context, repo domain.RelationTupleRepository, token string) (string, error) {
+	current, err := repo.CurrentRevision(ctx)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		wanted, err := decodeConsistencyToken(token)
+		if err != nil {
+			return "", err
+		}
+		if wanted > current {
+			return "", domain.ErrConsistencyAhead
+		}
+	}
+	return encodeConsistencyToken(current), nil
+}
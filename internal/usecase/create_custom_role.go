@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type createCustomRoleUseCase struct {
+	membershipRepo domain.MembershipRepository
+	roleRepo       domain.CustomRoleRepository
+	permissionRepo domain.PermissionRepository
+	tx             domain.Transactor
+}
+
+func NewCreateCustomRole(membershipRepo domain.MembershipRepository, roleRepo domain.CustomRoleRepository,
+	permissionRepo domain.PermissionRepository, tx domain.Transactor) domain.CreateCustomRoleUseCase {
+	return &createCustomRoleUseCase{
+		membershipRepo: membershipRepo,
+		roleRepo:       roleRepo,
+		permissionRepo: permissionRepo,
+		tx:             tx,
+	}
+}
+
+func (u *createCustomRoleUseCase) Execute(ctx context.Context, callerID string, role *domain.CustomRole) (*domain.CustomRole, error) {
+	if err := validateCustomRoleName(role.Name); err != nil {
+		return nil, err
+	}
+	if _, err := requireMembershipRole(ctx, u.membershipRepo, callerID, role.BusinessID,
+		domain.MembershipRoleOwner); err != nil {
+		return nil, err
+	}
+	if err := rejectDeprecated(ctx, u.permissionRepo, role.Permissions, nil); err != nil {
+		return nil, err
+	}
+
+	role.CreatedBy = callerID
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.roleRepo.Create(ctx, role); err != nil {
+			return err
+		}
+		return u.roleRepo.SetPermissions(ctx, role.ID, role.Permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// validateCustomRoleName keeps custom role names apart from built-in roles,
+// since both end up in the business_role token claim.
+func validateCustomRoleName(name string) error {
+	if strings.TrimSpace(name) == "" || domain.IsBuiltinMembershipRole(strings.ToLower(name)) {
+		return domain.ErrInvalidRole
+	}
+	return nil
+}
+
+// rejectDeprecated fails with ErrPermissionDeprecated if perms adds a
+// deprecated permission that is not already in held.
+func rejectDeprecated(ctx context.Context, repo domain.PermissionRepository, perms, held []domain.Permission) error {
+	deprecated, err := repo.Deprecated(ctx, perms)
+	if err != nil {
+		return err
+	}
+	if len(deprecated) == 0 {
+		return nil
+	}
+	have := permissionSet(held)
+	for _, p := range perms {
+		if deprecated[p] && !have[p] {
+			return fmt.Errorf("%w: %s", domain.ErrPermissionDeprecated, p)
+		}
+	}
+	return nil
+}
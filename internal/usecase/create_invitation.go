@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+type createInvitationUseCase struct {
+	membershipRepo domain.MembershipRepository
+	invitationRepo domain.InvitationRepository
+	notifier       domain.Notifier
+	domainRules    domain.DomainRuleStore
+	ttl            time.Duration
+}
+
+func NewCreateInvitation(membershipRepo domain.MembershipRepository, invitationRepo domain.InvitationRepository,
+	notifier domain.Notifier, domainRules domain.DomainRuleStore, ttl time.Duration) domain.CreateInvitationUseCase {
+	return &createInvitationUseCase{
+		membershipRepo: membershipRepo,
+		invitationRepo: invitationRepo,
+		notifier:       notifier,
+		domainRules:    domainRules,
+		ttl:            ttl,
+	}
+}
+
+func (u *createInvitationUseCase) Execute(ctx context.Context, req domain.CreateInvitationRequest) (*domain.Invitation, error) {
+	switch req.Role {
+	case domain.MembershipRoleAdmin, domain.MembershipRoleMaster, domain.MembershipRoleViewer:
+	default:
+		return nil, domain.ErrInvalidRole
+	}
+
+	inviter, err := requireMembershipRole(ctx, u.membershipRepo, req.InvitedBy, req.BusinessID,
+		domain.MembershipRoleOwner, domain.MembershipRoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	// only owners may hand out admin rights
+	if req.Role == domain.MembershipRoleAdmin && inviter.Role != domain.MembershipRoleOwner {
+		return nil, domain.ErrPermissionDenied
+	}
+	if req.Email != "" {
+		if err := u.domainRules.Rules().Check(req.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	tokenRaw, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &domain.Invitation{
+		BusinessID: req.BusinessID,
+		Email:      req.Email,
+		Phone:      req.Phone,
+		Role:       req.Role,
+		TokenHash:  infrastructure.GenerateTokenHash(tokenRaw),
+		InvitedBy:  req.InvitedBy,
+		ExpiresAt:  time.Now().Add(u.ttl),
+	}
+	if err := u.invitationRepo.Create(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	if err := u.notifier.Notify(ctx, domain.Notification{
+		Template: "business_invitation",
+		Email:    inv.Email,
+		Phone:    inv.Phone,
+		Data: map[string]string{
+			"business_id": inv.BusinessID,
+			"role":        inv.Role,
+			"token":       tokenRaw,
+		},
+	}); err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
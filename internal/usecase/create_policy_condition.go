@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/policy"
+)
+
+type createPolicyConditionUseCase struct {
+	userRepo       domain.UserRepository
+	membershipRepo domain.MembershipRepository
+	permissionRepo domain.PermissionRepository
+	roleRepo       domain.CustomRoleRepository
+	conditionRepo  domain.PolicyConditionRepository
+	evaluator      *policy.Evaluator
+}
+
+func NewCreatePolicyCondition(userRepo domain.UserRepository, membershipRepo domain.MembershipRepository,
+	permissionRepo domain.PermissionRepository, roleRepo domain.CustomRoleRepository,
+	conditionRepo domain.PolicyConditionRepository, evaluator *policy.Evaluator) domain.CreatePolicyConditionUseCase {
+	return &createPolicyConditionUseCase{
+		userRepo:       userRepo,
+		membershipRepo: membershipRepo,
+		permissionRepo: permissionRepo,
+		roleRepo:       roleRepo,
+		conditionRepo:  conditionRepo,
+		evaluator:      evaluator,
+	}
+}
+
+// Execute validates and stores a condition. Platform admins manage
+// platform-wide conditions, business owners those of their business.
+func (u *createPolicyConditionUseCase) Execute(ctx context.Context, callerID string, c *domain.PolicyCondition) (*domain.PolicyCondition, error) {
+	if err := requireConditionManager(ctx, u.userRepo, u.membershipRepo, callerID, c.BusinessID); err != nil {
+		return nil, err
+	}
+	if err := u.validate(ctx, c); err != nil {
+		return nil, err
+	}
+
+	c.CreatedBy = callerID
+	if err := u.conditionRepo.Create(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (u *createPolicyConditionUseCase) validate(ctx context.Context, c *domain.PolicyCondition) error {
+	if c.Mode == "" {
+		c.Mode = domain.ConditionModeEnforce
+	}
+	if c.Mode != domain.ConditionModeEnforce && c.Mode != domain.ConditionModeShadow {
+		return fmt.Errorf("%w: unknown mode %q", domain.ErrInvalidCondition, c.Mode)
+	}
+	if c.Resource == "" && c.Action != "" {
+		return fmt.Errorf("%w: action requires a resource", domain.ErrInvalidCondition)
+	}
+	if c.Resource == "" && c.Role == "" && c.CustomRoleID == "" {
+		return fmt.Errorf("%w: a permission or role must be targeted", domain.ErrInvalidCondition)
+	}
+	if c.Role != "" && c.CustomRoleID != "" {
+		return fmt.Errorf("%w: role and custom role are mutually exclusive", domain.ErrInvalidCondition)
+	}
+
+	if c.Resource != "" && c.Action != "" {
+		p := domain.Permission{Resource: c.Resource, Action: c.Action}
+		known, err := u.permissionRepo.Known(ctx, []domain.Permission{p})
+		if err != nil {
+			return err
+		}
+		if !known[p] {
+			return domain.ErrUnknownPermission
+		}
+	}
+
+	switch {
+	case c.Role != "":
+		if c.RoleScope == "" {
+			c.RoleScope = domain.RoleScopeMembership
+		}
+		if c.RoleScope == domain.RoleScopeMembership && !domain.IsBuiltinMembershipRole(c.Role) {
+			return domain.ErrInvalidRole
+		}
+		// business conditions never apply to global role grants
+		if c.RoleScope == domain.RoleScopeGlobal && c.BusinessID != "" {
+			return fmt.Errorf("%w: global roles can only be targeted platform-wide", domain.ErrInvalidCondition)
+		}
+	case c.CustomRoleID != "":
+		role, err := u.roleRepo.GetByID(ctx, c.CustomRoleID)
+		if err != nil {
+			return err
+		}
+		if c.BusinessID != "" && role.BusinessID != c.BusinessID {
+			return domain.ErrRoleNotFound
+		}
+		c.RoleScope = domain.RoleScopeMembership
+	default:
+		c.RoleScope = ""
+	}
+
+	if err := u.evaluator.Compile(c.Expression); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidCondition, err)
+	}
+	return nil
+}
+
+// requireConditionManager allows platform admins to manage any condition and
+// business owners the conditions of their business.
+func requireConditionManager(ctx context.Context, userRepo domain.UserRepository, membershipRepo domain.MembershipRepository,
+	callerID, businessID string) error {
+	err := requirePlatformAdmin(ctx, userRepo, callerID)
+	if !errors.Is(err, domain.ErrPermissionDenied) || businessID == "" {
+		return err
+	}
+	_, err = requireMembershipRole(ctx, membershipRepo, callerID, businessID, domain.MembershipRoleOwner)
+	return err
+}
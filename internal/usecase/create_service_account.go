@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+type createServiceAccountUseCase struct {
+	userRepo    domain.UserRepository
+	accountRepo domain.ServiceAccountRepository
+}
+
+func NewCreateServiceAccount(userRepo domain.UserRepository, accountRepo domain.ServiceAccountRepository) domain.CreateServiceAccountUseCase {
+	return &createServiceAccountUseCase{
+		userRepo:    userRepo,
+		accountRepo: accountRepo,
+	}
+}
+
+// Execute creates a service account on behalf of a platform admin.
+func (u *createServiceAccountUseCase) Execute(ctx context.Context, callerID string,
+	account *domain.ServiceAccount) (*domain.ServiceAccount, string, error) {
+	if err := requirePlatformAdmin(ctx, u.userRepo, callerID); err != nil {
+		return nil, "", err
+	}
+	if strings.TrimSpace(account.Name) == "" {
+		return nil, "", domain.NewOAuthError(domain.OAuthInvalidRequest, "service account name required")
+	}
+	if len(account.Scopes) == 0 {
+		return nil, "", domain.NewOAuthError(domain.OAuthInvalidScope, "at least one scope required")
+	}
+	for _, s := range account.Scopes {
+		if !slices.Contains(domain.ServiceScopes, s) {
+			return nil, "", domain.NewOAuthError(domain.OAuthInvalidScope, fmt.Sprintf("unknown scope %q", s))
+		}
+	}
+
+	id, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+	account.ClientID = "svc-" + id[:28]
+	account.SecretHash = infrastructure.GenerateTokenHash(secret)
+	account.CreatedBy = callerID
+	if err := u.accountRepo.Create(ctx, account); err != nil {
+		return nil, "", err
+	}
+	return account, secret, nil
+}
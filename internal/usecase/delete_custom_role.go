@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type deleteCustomRoleUseCase struct {
+	membershipRepo domain.MembershipRepository
+	roleRepo       domain.CustomRoleRepository
+}
+
+func NewDeleteCustomRole(membershipRepo domain.MembershipRepository, roleRepo domain.CustomRoleRepository) domain.DeleteCustomRoleUseCase {
+	return &deleteCustomRoleUseCase{
+		membershipRepo: membershipRepo,
+		roleRepo:       roleRepo,
+	}
+}
+
+// Execute deletes the role. Roles still assigned to members are kept and
+// ErrRoleInUse is returned.
+func (u *deleteCustomRoleUseCase) Execute(ctx context.Context, callerID, roleID string) error {
+	role, err := u.roleRepo.GetByID(ctx, roleID)
+	if err != nil {
+		return err
+	}
+	if _, err := requireMembershipRole(ctx, u.membershipRepo, callerID, role.BusinessID,
+		domain.MembershipRoleOwner); err != nil {
+		return err
+	}
+	return u.roleRepo.Delete(ctx, role.ID)
+}
@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type deletePolicyConditionUseCase struct {
+	userRepo       domain.UserRepository
+	membershipRepo domain.MembershipRepository
+	conditionRepo  domain.PolicyConditionRepository
+}
+
+func NewDeletePolicyCondition(userRepo domain.UserRepository, membershipRepo domain.MembershipRepository,
+	conditionRepo domain.PolicyConditionRepository) domain.DeletePolicyConditionUseCase {
+	return &deletePolicyConditionUseCase{
+		userRepo:       userRepo,
+		membershipRepo: membershipRepo,
+		conditionRepo:  conditionRepo,
+	}
+}
+
+func (u *deletePolicyConditionUseCase) Execute(ctx context.Context, callerID, conditionID string) error {
+	c, err := u.conditionRepo.GetByID(ctx, conditionID)
+	if err != nil {
+		return err
+	}
+	if err := requireConditionManager(ctx, u.userRepo, u.membershipRepo, callerID, c.BusinessID); err != nil {
+		return err
+	}
+	return u.conditionRepo.Delete(ctx, c.ID)
+}
@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// userCodeAlphabet has no vowels, so user codes cannot spell words, and no
+// characters that are easily confused on a TV screen (RFC 8628 section 6.1).
+const userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+
+const (
+	userCodeLength       = 8
+	devicePollInterval   = 5
+	deviceCodeScopeLimit = 512
+)
+
+type startDeviceAuthorizationUseCase struct {
+	clientRepo      domain.OAuthClientRepository
+	deviceRepo      domain.DeviceAuthorizationRepository
+	verificationURI string
+	ttl             time.Duration
+}
+
+func NewStartDeviceAuthorization(clientRepo domain.OAuthClientRepository, deviceRepo domain.DeviceAuthorizationRepository,
+	verificationURI string, ttl time.Duration) domain.StartDeviceAuthorizationUseCase {
+	return &startDeviceAuthorizationUseCase{
+		clientRepo:      clientRepo,
+		deviceRepo:      deviceRepo,
+		verificationURI: verificationURI,
+		ttl:             ttl,
+	}
+}
+
+func (u *startDeviceAuthorizationUseCase) Execute(ctx context.Context, clientID, clientSecret,
+	scope string) (*domain.DeviceAuthorizationResponse, error) {
+	client, err := authenticateClient(ctx, u.clientRepo, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	scopes := domain.ParseScope(scope)
+	if len(scope) > deviceCodeScopeLimit || !client.AllowsScopes(scopes) {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidScope, "scope not allowed for this client")
+	}
+
+	deviceCode, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	userCode, err := generateUserCode()
+	if err != nil {
+		return nil, err
+	}
+	err = u.deviceRepo.Create(ctx, &domain.DeviceAuthorization{
+		DeviceCodeHash: infrastructure.GenerateTokenHash(deviceCode),
+		UserCode:       userCode,
+		ClientID:       client.ID,
+		Scope:          joinScope(scopes),
+		Interval:       devicePollInterval,
+		ExpiresAt:      time.Now().Add(u.ttl),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	display := userCode[:4] + "-" + userCode[4:]
+	return &domain.DeviceAuthorizationResponse{
+		DeviceCode:              deviceCode,
+		UserCode:                display,
+		VerificationURI:         u.verificationURI,
+		VerificationURIComplete: u.verificationURI + "?user_code=" + url.QueryEscape(display),
+		ExpiresIn:               int64(u.ttl.Seconds()),
+		Interval:                devicePollInterval,
+	}, nil
+}
+
+func generateUserCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(userCodeAlphabet)))
+	for range userCodeLength {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(userCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// normalizeUserCode accepts what users type: any case, with or without the
+// dash or spaces.
+func normalizeUserCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		if strings.ContainsRune(userCodeAlphabet, r) {
+			return r
+		}
+		return -1
+	}, code)
+}
+
+type lookupDeviceUseCase struct {
+	deviceRepo domain.DeviceAuthorizationRepository
+}
+
+func NewLookupDevice(deviceRepo domain.DeviceAuthorizationRepository) domain.LookupDeviceUseCase {
+	return &lookupDeviceUseCase{deviceRepo: deviceRepo}
+}
+
+func (u *lookupDeviceUseCase) Execute(ctx context.Context, userCode string) (*domain.DeviceAuthorization, error) {
+	return u.deviceRepo.GetPending(ctx, normalizeUserCode(userCode))
+}
+
+type approveDeviceUseCase struct {
+	userRepo    domain.UserRepository
+	deviceRepo  domain.DeviceAuthorizationRepository
+	consentRepo domain.OAuthConsentRepository
+	tx          domain.Transactor
+}
+
+func NewApproveDevice(userRepo domain.UserRepository, deviceRepo domain.DeviceAuthorizationRepository,
+	consentRepo domain.OAuthConsentRepository, tx domain.Transactor) domain.ApproveDeviceUseCase {
+	return &approveDeviceUseCase{
+		userRepo:    userRepo,
+		deviceRepo:  deviceRepo,
+		consentRepo: consentRepo,
+		tx:          tx,
+	}
+}
+
+// Execute resolves a code the user has looked up. A client other than the
+// one shown means the code was mistyped or replaced in between, and is
+// treated like an unknown code.
+func (u *approveDeviceUseCase) Execute(ctx context.Context, req domain.DeviceApproval) (*domain.DeviceAuthorization, error) {
+	user, err := u.signedInUser(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	device, err := u.deviceRepo.GetPending(ctx, normalizeUserCode(req.UserCode))
+	if err != nil {
+		return nil, err
+	}
+	if req.ClientID == "" || device.ClientID != req.ClientID {
+		return nil, domain.ErrDeviceCodeNotFound
+	}
+
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.deviceRepo.Resolve(ctx, device.UserCode, user.ID, req.Approve); err != nil {
+			return err
+		}
+		if scopes := domain.ParseScope(device.Scope); req.Approve && len(scopes) > 0 {
+			return u.consentRepo.Grant(ctx, user.ID, device.ClientID, scopes)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	device.UserID = user.ID
+	device.Status = domain.DeviceStatusDenied
+	if req.Approve {
+		device.Status = domain.DeviceStatusApproved
+	}
+	return device, nil
+}
+
+func (u *approveDeviceUseCase) signedInUser(ctx context.Context, req domain.DeviceApproval) (*domain.User, error) {
+	if req.UserID != "" {
+		user, err := u.userRepo.GetByID(ctx, req.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil || !user.IsActive {
+			return nil, domain.ErrUserNotActive
+		}
+		return user, nil
+	}
+
+	user, err := u.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil || user == nil {
+		return nil, domain.ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
+		return nil, domain.ErrInvalidCredentials
+	}
+	if !user.IsActive {
+		return nil, domain.ErrUserNotActive
+	}
+	return user, nil
+}
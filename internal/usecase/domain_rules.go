@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type getDomainRulesUseCase struct {
+	userRepo    domain.UserRepository
+	domainRules domain.DomainRuleStore
+}
+
+func NewGetDomainRules(userRepo domain.UserRepository, domainRules domain.DomainRuleStore) domain.GetDomainRulesUseCase {
+	return &getDomainRulesUseCase{
+		userRepo:    userRepo,
+		domainRules: domainRules,
+	}
+}
+
+func (u *getDomainRulesUseCase) Execute(ctx context.Context, callerID string) (domain.DomainRules, error) {
+	if err := requirePlatformAdmin(ctx, u.userRepo, callerID); err != nil {
+		return domain.DomainRules{}, err
+	}
+	return u.domainRules.Rules(), nil
+}
+
+type setDomainRulesUseCase struct {
+	userRepo    domain.UserRepository
+	domainRules domain.DomainRuleStore
+	auditRepo   domain.AuditRepository
+}
+
+func NewSetDomainRules(userRepo domain.UserRepository, domainRules domain.DomainRuleStore,
+	auditRepo domain.AuditRepository) domain.SetDomainRulesUseCase {
+	return &setDomainRulesUseCase{
+		userRepo:    userRepo,
+		domainRules: domainRules,
+		auditRepo:   auditRepo,
+	}
+}
+
+// Execute replaces the allow and deny lists of the running environment. They
+// apply at once and are written back to the rules file.
+func (u *setDomainRulesUseCase) Execute(ctx context.Context, callerID string, allow,
+	deny []string) (domain.DomainRules, error) {
+	if err := requirePlatformAdmin(ctx, u.userRepo, callerID); err != nil {
+		return domain.DomainRules{}, err
+	}
+	rules, err := u.domainRules.Set(allow, deny)
+	if err != nil {
+		return domain.DomainRules{}, err
+	}
+	err = u.auditRepo.Record(ctx, domain.AuditEntry{
+		ActorID: callerID,
+		Action:  "domain_rules.updated",
+		Details: map[string]string{
+			"environment": rules.Environment,
+			"allow":       strconv.Itoa(len(rules.Allow)),
+			"deny":        strconv.Itoa(len(rules.Deny)),
+		},
+	})
+	if err != nil {
+		return domain.DomainRules{}, err
+	}
+	return rules, nil
+}
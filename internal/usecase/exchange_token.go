@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+const maxAudienceLength = 255
+
+type exchangeTokenUseCase struct {
+	userRepo   domain.UserRepository
+	policyRepo domain.TokenExchangePolicyRepository
+	auditRepo  domain.AuditRepository
+	jwt        *infrastructure.JWTManager
+	ttl        time.Duration
+}
+
+func NewExchangeToken(userRepo domain.UserRepository, policyRepo domain.TokenExchangePolicyRepository,
+	auditRepo domain.AuditRepository, jwt *infrastructure.JWTManager, ttl time.Duration) domain.ExchangeTokenUseCase {
+	return &exchangeTokenUseCase{
+		userRepo:   userRepo,
+		policyRepo: policyRepo,
+		auditRepo:  auditRepo,
+		jwt:        jwt,
+		ttl:        ttl,
+	}
+}
+
+// Execute implements RFC 8693 token exchange for service accounts. The new
+// token never outlives the subject token, and its scope can only shrink:
+// it must be within both the caller's policy for the audience and, for OAuth
+// tokens, the subject token's own scope. Delegated exchanges are audited.
+func (u *exchangeTokenUseCase) Execute(ctx context.Context, req domain.TokenExchangeRequest) (*domain.TokenExchangeResult, error) {
+	if req.Audience == "" || len(req.Audience) > maxAudienceLength || strings.ContainsAny(req.Audience, " \t\n") {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "a single audience is required")
+	}
+	policy, err := u.policyRepo.Get(ctx, req.CallerClientID, req.Audience)
+	if err != nil {
+		if errors.Is(err, domain.ErrExchangePolicyNotFound) {
+			return nil, domain.ErrExchangeNotAllowed
+		}
+		return nil, err
+	}
+
+	subject, err := u.validate(ctx, req.SubjectToken, "subject_token")
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := domain.ParseScope(req.Scope)
+	if len(scopes) == 0 {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidScope, "scope required")
+	}
+	granted := domain.ParseScope(subject.Scope)
+	for _, s := range scopes {
+		if !slices.Contains(policy.Scopes, s) {
+			return nil, fmt.Errorf("%w: scope %q", domain.ErrExchangeNotAllowed, s)
+		}
+		// first-party tokens carry no scope and are not limited by one
+		if subject.ClientID != "" && !slices.Contains(granted, s) {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidScope, "scope exceeds the subject token")
+		}
+	}
+
+	// the outermost act is the current actor; earlier ones stay nested inside
+	act := subject.Act
+	var actor *domain.TokenClaims
+	if req.ActorToken != "" {
+		if actor, err = u.validate(ctx, req.ActorToken, "actor_token"); err != nil {
+			return nil, err
+		}
+		actorUser, err := u.userRepo.GetByID(ctx, actor.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if actorUser == nil || !slices.Contains(policy.ActorRoles, actorUser.Role) {
+			return nil, fmt.Errorf("%w: actor may not act on behalf of users", domain.ErrExchangeNotAllowed)
+		}
+		act = map[string]any{"sub": actor.UserID}
+		if subject.Act != nil {
+			act["act"] = subject.Act
+		}
+	}
+
+	expiry := time.Now().Add(u.ttl)
+	if !subject.ExpiresAt.IsZero() && subject.ExpiresAt.Before(expiry) {
+		expiry = subject.ExpiresAt
+	}
+	scope := joinScope(scopes)
+	token, err := u.jwt.GenerateExchangedToken(subject, req.CallerClientID, req.Audience, scope, act, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	if actor != nil {
+		err = u.auditRepo.Record(ctx, domain.AuditEntry{
+			ActorID:      actor.UserID,
+			Action:       "token.exchanged_on_behalf",
+			BusinessID:   subject.BusinessID,
+			TargetUserID: subject.UserID,
+			Details: map[string]string{
+				"client_id": req.CallerClientID,
+				"audience":  req.Audience,
+				"scope":     scope,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &domain.TokenExchangeResult{
+		AccessToken:     token,
+		IssuedTokenType: domain.TokenTypeAccessToken,
+		Scope:           scope,
+		ExpiresAt:       expiry,
+	}, nil
+}
+
+// validate accepts user access tokens only: service account tokens cannot be
+// exchanged, so a service cannot widen its own reach.
+func (u *exchangeTokenUseCase) validate(ctx context.Context, token, param string) (*domain.TokenClaims, error) {
+	if token == "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, param+" required")
+	}
+	claims, err := u.jwt.ValidateAccessToken(token)
+	if err != nil || claims.ServiceAccount {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, param+" is invalid or expired")
+	}
+	// guest and shared device tokens are limited to a few RPCs here; an
+	// exchanged token would not carry that restriction downstream
+	if claims.Guest || claims.DeviceID != "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, param+" cannot be exchanged")
+	}
+	user, err := u.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "user is not active")
+	}
+	return claims, nil
+}
@@ -0,0 +1,261 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+type listIdentityProvidersUseCase struct {
+	providers map[string]domain.IdentityProvider
+}
+
+func NewListIdentityProviders(providers map[string]domain.IdentityProvider) domain.ListIdentityProvidersUseCase {
+	return &listIdentityProvidersUseCase{providers: providers}
+}
+
+func (u *listIdentityProvidersUseCase) Execute(ctx context.Context) ([]domain.IdentityProvider, error) {
+	out := make([]domain.IdentityProvider, 0, len(u.providers))
+	for _, p := range u.providers {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID() < out[j].ID() })
+	return out, nil
+}
+
+type startFederatedLoginUseCase struct {
+	providers map[string]domain.IdentityProvider
+	stateRepo domain.FederatedLoginStateRepository
+	ttl       time.Duration
+}
+
+func NewStartFederatedLogin(providers map[string]domain.IdentityProvider, stateRepo domain.FederatedLoginStateRepository,
+	ttl time.Duration) domain.StartFederatedLoginUseCase {
+	return &startFederatedLoginUseCase{
+		providers: providers,
+		stateRepo: stateRepo,
+		ttl:       ttl,
+	}
+}
+
+// Execute returns the provider URL to send the user to. The app passes the
+// state and code it gets back on redirectURI to CompleteFederatedLogin.
+func (u *startFederatedLoginUseCase) Execute(ctx context.Context, providerID,
+	redirectURI string) (*domain.FederatedLoginStart, error) {
+	return u.begin(ctx, providerID, redirectURI, "")
+}
+
+// begin stores the flow state. userID is set when a signed-in user links the
+// provider; such a state cannot be used to sign in, nor the other way round.
+func (u *startFederatedLoginUseCase) begin(ctx context.Context, providerID, redirectURI,
+	userID string) (*domain.FederatedLoginStart, error) {
+	provider, ok := u.providers[providerID]
+	if !ok {
+		return nil, domain.ErrIdentityProviderNotFound
+	}
+	if !provider.AllowsRedirectURI(redirectURI) {
+		return nil, domain.ErrInvalidRedirectURI
+	}
+
+	state, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	authURL, err := provider.AuthorizationURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(sum[:]),
+		redirectURI)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &domain.FederatedLoginState{
+		StateHash:    infrastructure.GenerateTokenHash(state),
+		ProviderID:   providerID,
+		UserID:       userID,
+		RedirectURI:  redirectURI,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(u.ttl),
+	}
+	if err := u.stateRepo.Create(ctx, s); err != nil {
+		return nil, err
+	}
+	return &domain.FederatedLoginStart{
+		AuthorizationURL: authURL,
+		State:            state,
+		ExpiresAt:        s.ExpiresAt,
+	}, nil
+}
+
+type completeFederatedLoginUseCase struct {
+	providers map[string]domain.IdentityProvider
+	stateRepo domain.FederatedLoginStateRepository
+	signIn    *externalSignIn
+}
+
+func NewCompleteFederatedLogin(providers map[string]domain.IdentityProvider, stateRepo domain.FederatedLoginStateRepository,
+	userRepo domain.UserRepository, identityRepo domain.LinkedIdentityRepository, tokenRepo domain.TokenRepository,
+	tx domain.Transactor, policy *domain.RegistrationPolicy, domainRules domain.DomainRuleStore,
+	jwt *infrastructure.JWTManager, accessTTL, refreshTTL time.Duration) domain.CompleteFederatedLoginUseCase {
+	return &completeFederatedLoginUseCase{
+		providers: providers,
+		stateRepo: stateRepo,
+		signIn: &externalSignIn{
+			userRepo:     userRepo,
+			identityRepo: identityRepo,
+			tokenRepo:    tokenRepo,
+			tx:           tx,
+			policy:       policy,
+			domainRules:  domainRules,
+			jwt:          jwt,
+			accessTTL:    accessTTL,
+			refreshTTL:   refreshTTL,
+		},
+	}
+}
+
+func (u *completeFederatedLoginUseCase) Execute(ctx context.Context, providerID, state,
+	code string) (*domain.FederatedLoginResult, error) {
+	provider, ok := u.providers[providerID]
+	if !ok {
+		return nil, domain.ErrIdentityProviderNotFound
+	}
+	s, err := u.stateRepo.Consume(ctx, infrastructure.GenerateTokenHash(state), providerID)
+	if err != nil {
+		return nil, err
+	}
+	if s.UserID != "" {
+		return nil, domain.ErrFederatedStateInvalid
+	}
+	ext, err := provider.Exchange(ctx, code, s.CodeVerifier, s.RedirectURI, s.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	return u.signIn.execute(ctx, ext)
+}
+
+// externalSignIn signs in the user linked to a verified external identity,
+// creating the user on first sign-in.
+type externalSignIn struct {
+	userRepo     domain.UserRepository
+	identityRepo domain.LinkedIdentityRepository
+	tokenRepo    domain.TokenRepository
+	tx           domain.Transactor
+	policy       *domain.RegistrationPolicy
+	domainRules  domain.DomainRuleStore
+	jwt          *infrastructure.JWTManager
+	accessTTL    time.Duration
+	refreshTTL   time.Duration
+}
+
+func (u *externalSignIn) execute(ctx context.Context, ext *domain.ExternalIdentity) (*domain.FederatedLoginResult, error) {
+	var user *domain.User
+	created := false
+	link, err := u.identityRepo.Get(ctx, ext.ProviderID, ext.Subject)
+	switch {
+	case err == nil:
+		if user, err = u.userRepo.GetByID(ctx, link.UserID); err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, domain.ErrInvalidCredentials
+		}
+		if err := u.identityRepo.TouchLogin(ctx, ext.ProviderID, ext.Subject); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, domain.ErrLinkedIdentityNotFound):
+		if user, err = u.createUser(ctx, ext); err != nil {
+			return nil, err
+		}
+		created = true
+	default:
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, domain.ErrUserNotActive
+	}
+
+	token, err := issueAuthToken(ctx, u.jwt, u.tokenRepo, user, nil, u.accessTTL, u.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.FederatedLoginResult{User: user, Token: token, Created: created}, nil
+}
+
+// createUser signs up an unknown external identity. A verified email that is
+// already registered is not linked automatically: whoever controls the
+// provider account would otherwise take over the existing user. Without a
+// verified email the user gets an address under the reserved .invalid
+// domain, and the password hash bcrypt never matches. The user takes the
+// default role of the registration policy, which must be open, and a
+// verified email must pass the domain rules.
+func (u *externalSignIn) createUser(ctx context.Context, ext *domain.ExternalIdentity) (*domain.User, error) {
+	email := strings.ToLower(strings.TrimSpace(ext.Email))
+	verified := email != "" && ext.EmailVerified
+	if !verified {
+		email = ""
+	}
+	role, err := u.policy.CheckOpen("", email)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user := &domain.User{
+		FirstName: ext.FirstName,
+		LastName:  ext.LastName,
+		Password:  domain.NoPassword,
+		Role:      role,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if verified {
+		if err := u.domainRules.Rules().Check(email); err != nil {
+			return nil, err
+		}
+		existing, err := u.userRepo.GetByEmail(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, domain.ErrEmailExists
+		}
+		user.Email = email
+		user.EmailVerified = true
+	} else {
+		sum := sha256.Sum256([]byte(ext.Subject))
+		user.Email = fmt.Sprintf("%x@%s.federated.invalid", sum[:10], ext.ProviderID)
+	}
+
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		return u.identityRepo.Create(ctx, &domain.LinkedIdentity{
+			ProviderID: ext.ProviderID,
+			Subject:    ext.Subject,
+			UserID:     user.ID,
+			Email:      ext.Email,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
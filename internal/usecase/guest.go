@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+type createGuestUseCase struct {
+	userRepo     domain.UserRepository
+	identityRepo domain.LinkedIdentityRepository
+	tokenRepo    domain.TokenRepository
+	tx           domain.Transactor
+	policy       *domain.RegistrationPolicy
+	limiter      domain.RateLimiter
+	jwt          *infrastructure.JWTManager
+	accessTTL    time.Duration
+	refreshTTL   time.Duration
+}
+
+func NewCreateGuest(userRepo domain.UserRepository, identityRepo domain.LinkedIdentityRepository,
+	tokenRepo domain.TokenRepository, tx domain.Transactor, policy *domain.RegistrationPolicy,
+	limiter domain.RateLimiter, jwt *infrastructure.JWTManager, accessTTL, refreshTTL time.Duration) domain.CreateGuestUseCase {
+	return &createGuestUseCase{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		tokenRepo:    tokenRepo,
+		tx:           tx,
+		policy:       policy,
+		limiter:      limiter,
+		jwt:          jwt,
+		accessTTL:    accessTTL,
+		refreshTTL:   refreshTTL,
+	}
+}
+
+// Execute creates a guest and signs it in. The guest has a placeholder
+// email under the reserved .invalid domain and no password; its device gets
+// a secret to come back with. Guests are clients, so the policy must leave
+// client sign-up open. The phone is only a way to reach the guest: it is
+// not verified, so it does not claim the unique phone of an account.
+func (u *createGuestUseCase) Execute(ctx context.Context, req domain.GuestRequest) (*domain.GuestSession, error) {
+	if !u.limiter.Allow(req.IPAddress) {
+		return nil, domain.ErrTooManyRequests
+	}
+	if _, err := u.policy.CheckOpen(domain.UserRoleClient, ""); err != nil {
+		return nil, err
+	}
+	secret, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	handle, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	user := &domain.User{
+		Email:        "guest-" + handle[:20] + "@guest.invalid",
+		Password:     domain.NoPassword,
+		FirstName:    strings.TrimSpace(req.FirstName),
+		Role:         domain.UserRoleClient,
+		IsActive:     true,
+		IsGuest:      true,
+		ContactPhone: strings.TrimSpace(req.Phone),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		return u.identityRepo.Create(ctx, &domain.LinkedIdentity{
+			ProviderID: domain.GuestDeviceProviderID,
+			Subject:    infrastructure.GenerateTokenHash(secret),
+			UserID:     user.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := issueAuthToken(ctx, u.jwt, u.tokenRepo, user, nil, u.accessTTL, u.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.GuestSession{User: user, Token: token, DeviceSecret: secret}, nil
+}
+
+type guestLoginUseCase struct {
+	userRepo     domain.UserRepository
+	identityRepo domain.LinkedIdentityRepository
+	tokenRepo    domain.TokenRepository
+	jwt          *infrastructure.JWTManager
+	accessTTL    time.Duration
+	refreshTTL   time.Duration
+}
+
+func NewGuestLogin(userRepo domain.UserRepository, identityRepo domain.LinkedIdentityRepository,
+	tokenRepo domain.TokenRepository, jwt *infrastructure.JWTManager, accessTTL, refreshTTL time.Duration) domain.GuestLoginUseCase {
+	return &guestLoginUseCase{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		tokenRepo:    tokenRepo,
+		jwt:          jwt,
+		accessTTL:    accessTTL,
+		refreshTTL:   refreshTTL,
+	}
+}
+
+func (u *guestLoginUseCase) Execute(ctx context.Context, deviceSecret string) (*domain.User, *domain.AuthToken, error) {
+	subject := infrastructure.GenerateTokenHash(deviceSecret)
+	link, err := u.identityRepo.Get(ctx, domain.GuestDeviceProviderID, subject)
+	if err != nil {
+		if errors.Is(err, domain.ErrLinkedIdentityNotFound) {
+			return nil, nil, domain.ErrInvalidCredentials
+		}
+		return nil, nil, err
+	}
+	user, err := u.userRepo.GetByID(ctx, link.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, domain.ErrInvalidCredentials
+	}
+	if !user.IsActive {
+		return nil, nil, domain.ErrUserNotActive
+	}
+	if err := u.identityRepo.TouchLogin(ctx, domain.GuestDeviceProviderID, subject); err != nil {
+		return nil, nil, err
+	}
+
+	token, err := issueAuthToken(ctx, u.jwt, u.tokenRepo, user, nil, u.accessTTL, u.refreshTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, token, nil
+}
+
+type upgradeGuestUseCase struct {
+	userRepo     domain.UserRepository
+	identityRepo domain.LinkedIdentityRepository
+	tokenRepo    domain.TokenRepository
+	eventRepo    domain.UserEventRepository
+	auditRepo    domain.AuditRepository
+	policy       *domain.RegistrationPolicy
+	domainRules  domain.DomainRuleStore
+	tx           domain.Transactor
+	jwt          *infrastructure.JWTManager
+	accessTTL    time.Duration
+	refreshTTL   time.Duration
+}
+
+func NewUpgradeGuest(userRepo domain.UserRepository, identityRepo domain.LinkedIdentityRepository,
+	tokenRepo domain.TokenRepository, eventRepo domain.UserEventRepository, auditRepo domain.AuditRepository,
+	policy *domain.RegistrationPolicy, domainRules domain.DomainRuleStore, tx domain.Transactor,
+	jwt *infrastructure.JWTManager, accessTTL, refreshTTL time.Duration) domain.UpgradeGuestUseCase {
+	return &upgradeGuestUseCase{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		tokenRepo:    tokenRepo,
+		eventRepo:    eventRepo,
+		auditRepo:    auditRepo,
+		policy:       policy,
+		domainRules:  domainRules,
+		tx:           tx,
+		jwt:          jwt,
+		accessTTL:    accessTTL,
+		refreshTTL:   refreshTTL,
+	}
+}
+
+// Execute sets the credentials of a full account on the guest. The device
+// secret is dropped: from now on the user signs in with the password, and
+// the returned token is no longer restricted.
+func (u *upgradeGuestUseCase) Execute(ctx context.Context, userID string,
+	req domain.UpgradeGuestRequest) (*domain.User, *domain.AuthToken, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, domain.ErrUserNotFound
+	}
+	if !user.IsGuest {
+		return nil, nil, domain.ErrNotGuest
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if _, err := u.policy.CheckOpen(user.Role, email); err != nil {
+		return nil, nil, err
+	}
+	if err := u.domainRules.Rules().Check(email); err != nil {
+		return nil, nil, err
+	}
+
+	hash, err := infrastructure.GeneratePassworHash(req.Password)
+	if err != nil {
+		return nil, nil, err
+	}
+	user.Email = email
+	user.Password = string(hash)
+	user.Phone = strings.TrimSpace(req.Phone)
+	if user.Phone == "" {
+		user.Phone = user.ContactPhone
+	}
+	if req.FirstName != "" {
+		user.FirstName = req.FirstName
+	}
+	if req.LastName != "" {
+		user.LastName = req.LastName
+	}
+
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.UpgradeGuest(ctx, user); err != nil {
+			return err
+		}
+		err := u.identityRepo.Delete(ctx, userID, domain.GuestDeviceProviderID)
+		if err != nil && !errors.Is(err, domain.ErrLinkedIdentityNotFound) {
+			return err
+		}
+		if err := u.eventRepo.Publish(ctx, &domain.UserEvent{
+			Type:   domain.UserEventUpgraded,
+			UserID: userID,
+		}); err != nil {
+			return err
+		}
+		return u.auditRepo.Record(ctx, domain.AuditEntry{
+			ActorID:      userID,
+			Action:       domain.UserEventUpgraded,
+			TargetUserID: userID,
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token, err := issueAuthToken(ctx, u.jwt, u.tokenRepo, user, nil, u.accessTTL, u.refreshTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, token, nil
+}
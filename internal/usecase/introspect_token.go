@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+type introspectTokenUseCase struct {
+	userRepo   domain.UserRepository
+	tokenRepo  domain.TokenRepository
+	clientRepo domain.OAuthClientRepository
+	accounts   domain.ServiceAccountRepository
+	jwt        *infrastructure.JWTManager
+}
+
+func NewIntrospectToken(userRepo domain.UserRepository, tokenRepo domain.TokenRepository,
+	clientRepo domain.OAuthClientRepository, accounts domain.ServiceAccountRepository,
+	jwt *infrastructure.JWTManager) domain.IntrospectTokenUseCase {
+	return &introspectTokenUseCase{
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		clientRepo: clientRepo,
+		accounts:   accounts,
+		jwt:        jwt,
+	}
+}
+
+// Execute reports whether the token is currently valid. Unlike local JWT
+// verification it also takes deactivated users and revoked refresh tokens
+// into account. The hint only decides which kind of token is tried first.
+func (u *introspectTokenUseCase) Execute(ctx context.Context, req domain.ClientTokenRequest) (*domain.TokenIntrospection, error) {
+	if _, err := authenticateTokenCaller(ctx, u.clientRepo, u.accounts, req.ClientID, req.ClientSecret, false); err != nil {
+		return nil, err
+	}
+	if req.Token == "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "token required")
+	}
+
+	lookups := []func(context.Context, string) (*domain.TokenIntrospection, error){u.accessToken, u.refreshToken}
+	if req.TokenTypeHint == domain.TokenTypeHintRefreshToken {
+		lookups[0], lookups[1] = lookups[1], lookups[0]
+	}
+	for _, lookup := range lookups {
+		result, err := lookup(ctx, req.Token)
+		if err != nil || result != nil {
+			return result, err
+		}
+	}
+	return &domain.TokenIntrospection{Active: false}, nil
+}
+
+func (u *introspectTokenUseCase) accessToken(ctx context.Context, token string) (*domain.TokenIntrospection, error) {
+	claims, err := u.jwt.ValidateAccessToken(token)
+	if err != nil {
+		return nil, nil
+	}
+	if active, err := u.userActive(ctx, claims.UserID); err != nil || !active {
+		return &domain.TokenIntrospection{Active: false}, err
+	}
+	actorID, _ := claims.Act["sub"].(string)
+	return &domain.TokenIntrospection{
+		Active:     true,
+		TokenType:  "Bearer",
+		Scope:      claims.Scope,
+		ClientID:   claims.ClientID,
+		Subject:    claims.UserID,
+		BusinessID: claims.BusinessID,
+		Audience:   claims.Audience,
+		ActorID:    actorID,
+		IssuedAt:   claims.IssuedAt,
+		ExpiresAt:  claims.ExpiresAt,
+	}, nil
+}
+
+func (u *introspectTokenUseCase) refreshToken(ctx context.Context, token string) (*domain.TokenIntrospection, error) {
+	rt, err := u.tokenRepo.FindByHash(ctx, infrastructure.GenerateTokenHash(token))
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if active, err := u.userActive(ctx, rt.UserID); err != nil || !active {
+		return &domain.TokenIntrospection{Active: false}, err
+	}
+	return &domain.TokenIntrospection{
+		Active:     true,
+		TokenType:  domain.TokenTypeHintRefreshToken,
+		Scope:      rt.Scope,
+		ClientID:   rt.ClientID,
+		Subject:    rt.UserID,
+		BusinessID: rt.BusinessID,
+		IssuedAt:   rt.CreatedAt,
+		ExpiresAt:  rt.ExpiresAt,
+	}, nil
+}
+
+func (u *introspectTokenUseCase) userActive(ctx context.Context, userID string) (bool, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return user != nil && user.IsActive, nil
+}
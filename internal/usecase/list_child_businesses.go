@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type listChildBusinessesUseCase struct {
+	membershipRepo domain.MembershipRepository
+	unitRepo       domain.BusinessUnitRepository
+}
+
+func NewListChildBusinesses(membershipRepo domain.MembershipRepository, unitRepo domain.BusinessUnitRepository) domain.ListChildBusinessesUseCase {
+	return &listChildBusinessesUseCase{
+		membershipRepo: membershipRepo,
+		unitRepo:       unitRepo,
+	}
+}
+
+func (u *listChildBusinessesUseCase) Execute(ctx context.Context, callerID, businessID string) ([]string, error) {
+	if _, err := requireActiveMembership(ctx, u.membershipRepo, callerID, businessID); err != nil {
+		return nil, err
+	}
+	return u.unitRepo.Children(ctx, businessID)
+}
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type listCustomRolesUseCase struct {
+	membershipRepo domain.MembershipRepository
+	roleRepo       domain.CustomRoleRepository
+}
+
+func NewListCustomRoles(membershipRepo domain.MembershipRepository, roleRepo domain.CustomRoleRepository) domain.ListCustomRolesUseCase {
+	return &listCustomRolesUseCase{
+		membershipRepo: membershipRepo,
+		roleRepo:       roleRepo,
+	}
+}
+
+func (u *listCustomRolesUseCase) Execute(ctx context.Context, callerID, businessID string) ([]*domain.CustomRole, error) {
+	if _, err := requireMembershipRole(ctx, u.membershipRepo, callerID, businessID,
+		domain.MembershipRoleOwner, domain.MembershipRoleAdmin); err != nil {
+		return nil, err
+	}
+	return u.roleRepo.ListByBusiness(ctx, businessID)
+}
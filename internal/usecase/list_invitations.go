@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type listInvitationsUseCase struct {
+	membershipRepo domain.MembershipRepository
+	invitationRepo domain.InvitationRepository
+}
+
+func NewListInvitations(membershipRepo domain.MembershipRepository, invitationRepo domain.InvitationRepository) domain.ListInvitationsUseCase {
+	return &listInvitationsUseCase{
+		membershipRepo: membershipRepo,
+		invitationRepo: invitationRepo,
+	}
+}
+
+func (u *listInvitationsUseCase) Execute(ctx context.Context, callerID, businessID string) ([]*domain.Invitation, error) {
+	if _, err := requireMembershipRole(ctx, u.membershipRepo, callerID, businessID,
+		domain.MembershipRoleOwner, domain.MembershipRoleAdmin); err != nil {
+		return nil, err
+	}
+	return u.invitationRepo.ListByBusiness(ctx, businessID)
+}
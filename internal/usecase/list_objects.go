@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/rebac"
+)
+
+type listObjectsUseCase struct {
+	tupleRepo domain.RelationTupleRepository
+	engine    *rebac.Engine
+}
+
+func NewListObjects(tupleRepo domain.RelationTupleRepository, engine *rebac.Engine) domain.ListObjectsUseCase {
+	return &listObjectsUseCase{
+		tupleRepo: tupleRepo,
+		engine:    engine,
+	}
+}
+
+func (u *listObjectsUseCase) Execute(ctx context.Context, objectType, permission string,
+	subject domain.SubjectRef, consistencyToken string) ([]string, string, error) {
+	token, err := ensureFresh(ctx, u.tupleRepo, consistencyToken)
+	if err != nil {
+		return nil, "", err
+	}
+	ids, err := u.engine.ListObjects(ctx, objectType, permission, subject)
+	if err != nil {
+		return nil, "", err
+	}
+	return ids, token, nil
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type listPolicyConditionsUseCase struct {
+	userRepo       domain.UserRepository
+	membershipRepo domain.MembershipRepository
+	conditionRepo  domain.PolicyConditionRepository
+}
+
+func NewListPolicyConditions(userRepo domain.UserRepository, membershipRepo domain.MembershipRepository,
+	conditionRepo domain.PolicyConditionRepository) domain.ListPolicyConditionsUseCase {
+	return &listPolicyConditionsUseCase{
+		userRepo:       userRepo,
+		membershipRepo: membershipRepo,
+		conditionRepo:  conditionRepo,
+	}
+}
+
+// Execute lists the conditions defined on businessID, or the platform-wide
+// ones when businessID is empty.
+func (u *listPolicyConditionsUseCase) Execute(ctx context.Context, callerID, businessID string) ([]*domain.PolicyCondition, error) {
+	if err := requireConditionManager(ctx, u.userRepo, u.membershipRepo, callerID, businessID); err != nil {
+		return nil, err
+	}
+	return u.conditionRepo.ListByBusiness(ctx, businessID)
+}
@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/rebac"
+)
+
+type listSubjectsUseCase struct {
+	tupleRepo domain.RelationTupleRepository
+	engine    *rebac.Engine
+}
+
+func NewListSubjects(tupleRepo domain.RelationTupleRepository, engine *rebac.Engine) domain.ListSubjectsUseCase {
+	return &listSubjectsUseCase{
+		tupleRepo: tupleRepo,
+		engine:    engine,
+	}
+}
+
+func (u *listSubjectsUseCase) Execute(ctx context.Context, object domain.ObjectRef, permission, subjectType,
+	consistencyToken string) ([]string, string, error) {
+	token, err := ensureFresh(ctx, u.tupleRepo, consistencyToken)
+	if err != nil {
+		return nil, "", err
+	}
+	ids, err := u.engine.ListSubjects(ctx, object, permission, subjectType)
+	if err != nil {
+		return nil, "", err
+	}
+	return ids, token, nil
+}
@@ -30,12 +30,16 @@ func NewLogin(userRepo domain.UserRepository, tokenRepo domain.TokenRepository,
 
 func (u *loginUseCase) Execute(ctx context.Context, email, password string) (*domain.User, *domain.AuthToken, error) {
 	user, err := u.userRepo.GetByEmail(ctx, email)
-	if err != nil {
+	if err != nil || user == nil {
 		return nil, nil, domain.ErrInvalidCredentials
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		return nil, nil, domain.ErrInvalidCredentials
 	}
+	// registrations waiting for approval and merged users are inactive
+	if !user.IsActive {
+		return nil, nil, domain.ErrUserNotActive
+	}
 
 	accessExp := time.Now().Add(u.accessTTL)
 	accessToken, err := u.jwt.GenerateAccessToken(user, accessExp)
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+// requireActiveMembership fails with ErrPermissionDenied unless userID has an
+// active membership in businessID, whatever its role. Memberships granted at
+// an ancestor business count unless overridden lower in the hierarchy.
+func requireActiveMembership(ctx context.Context, repo domain.MembershipRepository,
+	userID, businessID string) (*domain.Membership, error) {
+	m, err := repo.GetEffective(ctx, userID, businessID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMembershipNotFound) {
+			return nil, domain.ErrPermissionDenied
+		}
+		return nil, err
+	}
+	if !m.IsActive {
+		return nil, domain.ErrPermissionDenied
+	}
+	return m, nil
+}
+
+// requireMembershipRole fails with ErrPermissionDenied unless userID has an
+// active membership in businessID with one of the built-in roles.
+func requireMembershipRole(ctx context.Context, repo domain.MembershipRepository,
+	userID, businessID string, roles ...string) (*domain.Membership, error) {
+	m, err := requireActiveMembership(ctx, repo, userID, businessID)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		if m.CustomRoleID == "" && m.Role == role {
+			return m, nil
+		}
+	}
+	return nil, domain.ErrPermissionDenied
+}
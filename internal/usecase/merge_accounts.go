@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+const (
+	defaultUserEventLimit = 100
+	maxUserEventLimit     = 1000
+)
+
+type listMergeRequestsUseCase struct {
+	userRepo  domain.UserRepository
+	mergeRepo domain.AccountMergeRequestRepository
+}
+
+func NewListMergeRequests(userRepo domain.UserRepository,
+	mergeRepo domain.AccountMergeRequestRepository) domain.ListMergeRequestsUseCase {
+	return &listMergeRequestsUseCase{
+		userRepo:  userRepo,
+		mergeRepo: mergeRepo,
+	}
+}
+
+// Execute lists the duplicate accounts found by users linking an identity
+// that already signs in to another account.
+func (u *listMergeRequestsUseCase) Execute(ctx context.Context, callerID string) ([]*domain.AccountMergeRequest, error) {
+	if err := requirePlatformAdmin(ctx, u.userRepo, callerID); err != nil {
+		return nil, err
+	}
+	return u.mergeRepo.ListPending(ctx)
+}
+
+type mergeAccountsUseCase struct {
+	userRepo      domain.UserRepository
+	identityRepo  domain.LinkedIdentityRepository
+	userMergeRepo domain.UserMergeRepository
+	eventRepo     domain.UserEventRepository
+	auditRepo     domain.AuditRepository
+	domainRules   domain.DomainRuleStore
+	tx            domain.Transactor
+	tupleRepo     domain.RelationTupleRepository
+}
+
+func NewMergeAccounts(userRepo domain.UserRepository, identityRepo domain.LinkedIdentityRepository,
+	userMergeRepo domain.UserMergeRepository, eventRepo domain.UserEventRepository, auditRepo domain.AuditRepository,
+	domainRules domain.DomainRuleStore, tx domain.Transactor,
+	tupleRepo domain.RelationTupleRepository) domain.MergeAccountsUseCase {
+	return &mergeAccountsUseCase{
+		userRepo:      userRepo,
+		identityRepo:  identityRepo,
+		userMergeRepo: userMergeRepo,
+		eventRepo:     eventRepo,
+		auditRepo:     auditRepo,
+		domainRules:   domainRules,
+		tx:            tx,
+		tupleRepo:     tupleRepo,
+	}
+}
+
+// Execute lets a platform admin fold a duplicate account into the one that
+// survives. Memberships, linked identities, sessions and relation tuples move
+// to the target, and the source becomes an inactive tombstone that resolves
+// to it. Both users holding an identity from the same provider is refused:
+// one of them has to be unlinked first. An email the domain rules refuse
+// stays behind on the tombstone instead of moving to the target.
+func (u *mergeAccountsUseCase) Execute(ctx context.Context, callerID, sourceUserID,
+	targetUserID string) (*domain.UserMerge, error) {
+	if err := requirePlatformAdmin(ctx, u.userRepo, callerID); err != nil {
+		return nil, err
+	}
+	if sourceUserID == targetUserID {
+		return nil, fmt.Errorf("%w: source and target are the same user", domain.ErrInvalidMerge)
+	}
+
+	var m *domain.UserMerge
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// checked with both users locked: a concurrent merge of either one
+		// has either committed its tombstone by now or waits for ours
+		if err := u.userMergeRepo.LockUsers(ctx, sourceUserID, targetUserID); err != nil {
+			return err
+		}
+		source, err := u.mergeable(ctx, sourceUserID)
+		if err != nil {
+			return err
+		}
+		target, err := u.mergeable(ctx, targetUserID)
+		if err != nil {
+			return err
+		}
+		if !target.IsActive {
+			return domain.ErrUserNotActive
+		}
+		if err := u.checkIdentities(ctx, sourceUserID, targetUserID); err != nil {
+			return err
+		}
+
+		m = &domain.UserMerge{
+			SourceUserID: sourceUserID,
+			TargetUserID: targetUserID,
+			MergedBy:     callerID,
+			MovePhone:    target.Phone == "" && source.Phone != "",
+			MoveEmail: isPlaceholderEmail(target.Email) && !isPlaceholderEmail(source.Email) &&
+				u.domainRules.Rules().Check(source.Email) == nil,
+		}
+		if err := u.userMergeRepo.Merge(ctx, m); err != nil {
+			return err
+		}
+		if _, err := u.tupleRepo.ReassignUser(ctx, sourceUserID, targetUserID); err != nil {
+			return err
+		}
+		data := map[string]string{
+			"source_user_id": sourceUserID,
+			"target_user_id": targetUserID,
+		}
+		if err := u.eventRepo.Publish(ctx, &domain.UserEvent{
+			Type:   domain.UserEventMerged,
+			UserID: sourceUserID,
+			Data:   data,
+		}); err != nil {
+			return err
+		}
+		return u.auditRepo.Record(ctx, domain.AuditEntry{
+			ActorID:      callerID,
+			Action:       domain.UserEventMerged,
+			TargetUserID: targetUserID,
+			Details:      data,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// mergeable loads a user that exists and has not been merged already.
+func (u *mergeAccountsUseCase) mergeable(ctx context.Context, userID string) (*domain.User, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	_, err = u.userMergeRepo.Resolve(ctx, userID)
+	if err == nil {
+		return nil, fmt.Errorf("%w: user %s was already merged", domain.ErrInvalidMerge, userID)
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (u *mergeAccountsUseCase) checkIdentities(ctx context.Context, sourceUserID, targetUserID string) error {
+	sourceLinks, err := u.identityRepo.ListByUser(ctx, sourceUserID)
+	if err != nil {
+		return err
+	}
+	targetLinks, err := u.identityRepo.ListByUser(ctx, targetUserID)
+	if err != nil {
+		return err
+	}
+	for _, s := range sourceLinks {
+		for _, t := range targetLinks {
+			if s.ProviderID == t.ProviderID {
+				return fmt.Errorf("%w: both users have a %s identity", domain.ErrProviderAlreadyLinked, s.ProviderID)
+			}
+		}
+	}
+	return nil
+}
+
+// isPlaceholderEmail reports addresses under the reserved .invalid domain,
+// given to users that signed up without a verified email.
+func isPlaceholderEmail(email string) bool {
+	return strings.HasSuffix(email, ".invalid")
+}
+
+type resolveUserIDUseCase struct {
+	userRepo      domain.UserRepository
+	userMergeRepo domain.UserMergeRepository
+}
+
+func NewResolveUserID(userRepo domain.UserRepository, userMergeRepo domain.UserMergeRepository) domain.ResolveUserIDUseCase {
+	return &resolveUserIDUseCase{
+		userRepo:      userRepo,
+		userMergeRepo: userMergeRepo,
+	}
+}
+
+func (u *resolveUserIDUseCase) Execute(ctx context.Context, userID string) (string, error) {
+	target, err := u.userMergeRepo.Resolve(ctx, userID)
+	if err == nil {
+		return target, nil
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return "", err
+	}
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", domain.ErrUserNotFound
+	}
+	return user.ID, nil
+}
+
+type listUserEventsUseCase struct {
+	eventRepo domain.UserEventRepository
+}
+
+func NewListUserEvents(eventRepo domain.UserEventRepository) domain.ListUserEventsUseCase {
+	return &listUserEventsUseCase{eventRepo: eventRepo}
+}
+
+func (u *listUserEventsUseCase) Execute(ctx context.Context, afterID int64, limit int) ([]*domain.UserEvent, error) {
+	if limit <= 0 {
+		limit = defaultUserEventLimit
+	}
+	if limit > maxUserEventLimit {
+		limit = maxUserEventLimit
+	}
+	return u.eventRepo.List(ctx, afterID, limit)
+}
@@ -0,0 +1,364 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+type oauthTokenUseCase struct {
+	userRepo   domain.UserRepository
+	tokenRepo  domain.TokenRepository
+	clientRepo domain.OAuthClientRepository
+	codeRepo   domain.AuthorizationCodeRepository
+	accounts   domain.ServiceAccountRepository
+	devices    domain.DeviceAuthorizationRepository
+	jwt        *infrastructure.JWTManager
+	idTokens   *infrastructure.IDTokenSigner
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewOAuthToken(userRepo domain.UserRepository, tokenRepo domain.TokenRepository,
+	clientRepo domain.OAuthClientRepository, codeRepo domain.AuthorizationCodeRepository,
+	accounts domain.ServiceAccountRepository, devices domain.DeviceAuthorizationRepository,
+	jwt *infrastructure.JWTManager, idTokens *infrastructure.IDTokenSigner, issuer string,
+	accessTTL, refreshTTL time.Duration) domain.OAuthTokenUseCase {
+	return &oauthTokenUseCase{
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		clientRepo: clientRepo,
+		codeRepo:   codeRepo,
+		accounts:   accounts,
+		devices:    devices,
+		jwt:        jwt,
+		idTokens:   idTokens,
+		issuer:     issuer,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+func (u *oauthTokenUseCase) Execute(ctx context.Context, req domain.TokenRequest) (*domain.OAuthTokenResponse, error) {
+	// service accounts are not OAuth clients and authenticate separately
+	if req.GrantType == domain.GrantTypeClientCredentials {
+		return u.clientCredentials(ctx, req)
+	}
+	client, err := authenticateClient(ctx, u.clientRepo, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case domain.GrantTypeAuthorizationCode:
+		return u.exchangeCode(ctx, client, req)
+	case domain.GrantTypeRefreshToken:
+		return u.refresh(ctx, client, req)
+	case domain.GrantTypeDeviceCode:
+		return u.deviceCode(ctx, client, req)
+	}
+	return nil, domain.NewOAuthError(domain.OAuthUnsupportedGrantType, "")
+}
+
+func (u *oauthTokenUseCase) exchangeCode(ctx context.Context, client *domain.OAuthClient, req domain.TokenRequest) (*domain.OAuthTokenResponse, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "code and code_verifier required")
+	}
+	code, err := u.codeRepo.Consume(ctx, infrastructure.GenerateTokenHash(req.Code))
+	if err != nil {
+		if errors.Is(err, domain.ErrAuthorizationCodeInvalid) {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, err.Error())
+		}
+		return nil, err
+	}
+	if code.ClientID != client.ID || code.RedirectURI != req.RedirectURI {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "code was issued to another client or redirect uri")
+	}
+	if !verifyPKCE(req.CodeVerifier, code.CodeChallenge) {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "code_verifier does not match code_challenge")
+	}
+
+	user, err := u.activeUser(ctx, code.UserID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.issue(ctx, user, client, code.Scope)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(domain.ParseScope(code.Scope), domain.ScopeOpenID) {
+		if resp.IDToken, err = u.idToken(user, client, code, resp.AccessToken); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// idToken builds the OpenID Connect ID token for a code exchange. It is only
+// issued here: refreshed tokens do not carry a new one, since auth_time is not
+// kept past the authorization code.
+func (u *oauthTokenUseCase) idToken(user *domain.User, client *domain.OAuthClient,
+	code *domain.AuthorizationCode, accessToken string) (string, error) {
+	now := time.Now()
+	// at_hash is the left half of the SHA-256 of the access token (RS256)
+	sum := sha256.Sum256([]byte(accessToken))
+	claims := jwt.MapClaims{
+		"iss":       u.issuer,
+		"sub":       user.ID,
+		"aud":       client.ID,
+		"exp":       now.Add(u.accessTTL).Unix(),
+		"iat":       now.Unix(),
+		"auth_time": code.AuthTime.Unix(),
+		"at_hash":   base64.RawURLEncoding.EncodeToString(sum[:len(sum)/2]),
+	}
+	if code.Nonce != "" {
+		claims["nonce"] = code.Nonce
+	}
+	for k, v := range standardClaims(user, domain.ParseScope(code.Scope)) {
+		claims[k] = v
+	}
+	return u.idTokens.Sign(claims)
+}
+
+// deviceCode answers a device poll (RFC 8628 section 3.4). The device keeps
+// polling on authorization_pending and slow_down and stops on any other error.
+func (u *oauthTokenUseCase) deviceCode(ctx context.Context, client *domain.OAuthClient, req domain.TokenRequest) (*domain.OAuthTokenResponse, error) {
+	if req.DeviceCode == "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "device_code required")
+	}
+	hash := infrastructure.GenerateTokenHash(req.DeviceCode)
+	device, slowDown, err := u.devices.Poll(ctx, hash)
+	if err != nil {
+		if errors.Is(err, domain.ErrDeviceCodeNotFound) {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, err.Error())
+		}
+		return nil, err
+	}
+	if device.ClientID != client.ID {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "device code was issued to another client")
+	}
+
+	switch {
+	case time.Now().After(device.ExpiresAt):
+		return nil, domain.NewOAuthError(domain.OAuthExpiredToken, "")
+	case device.Status == domain.DeviceStatusDenied:
+		return nil, domain.NewOAuthError(domain.OAuthAccessDenied, "the user denied the request")
+	case slowDown:
+		return nil, domain.NewOAuthError(domain.OAuthSlowDown, "")
+	case device.Status == domain.DeviceStatusPending:
+		return nil, domain.NewOAuthError(domain.OAuthAuthorizationPending, "")
+	}
+
+	user, err := u.activeUser(ctx, device.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.devices.Consume(ctx, hash); err != nil {
+		if errors.Is(err, domain.ErrDeviceCodeNotFound) {
+			// redeemed by a concurrent poll
+			return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, err.Error())
+		}
+		return nil, err
+	}
+	return u.issue(ctx, user, client, device.Scope)
+}
+
+func (u *oauthTokenUseCase) refresh(ctx context.Context, client *domain.OAuthClient, req domain.TokenRequest) (*domain.OAuthTokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "refresh_token required")
+	}
+	hash := infrastructure.GenerateTokenHash(req.RefreshToken)
+	rt, err := u.tokenRepo.FindByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, err.Error())
+		}
+		return nil, err
+	}
+	if rt.ClientID != client.ID {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "refresh token was issued to another client")
+	}
+
+	// the client may narrow, but never widen, the originally granted scope
+	scope := rt.Scope
+	if req.Scope != "" {
+		granted := domain.ParseScope(rt.Scope)
+		for _, s := range domain.ParseScope(req.Scope) {
+			if !slices.Contains(granted, s) {
+				return nil, domain.NewOAuthError(domain.OAuthInvalidScope, "scope exceeds the original grant")
+			}
+		}
+		scope = joinScope(domain.ParseScope(req.Scope))
+	}
+
+	user, err := u.activeUser(ctx, rt.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.tokenRepo.DeleteByHash(ctx, hash); err != nil {
+		if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+			// redeemed concurrently
+			return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, err.Error())
+		}
+		return nil, err
+	}
+	return u.issue(ctx, user, client, scope)
+}
+
+// clientCredentials issues a service account token (RFC 6749 section 4.4).
+// No refresh token is issued: the account can always ask for a new one.
+func (u *oauthTokenUseCase) clientCredentials(ctx context.Context, req domain.TokenRequest) (*domain.OAuthTokenResponse, error) {
+	account, err := authenticateServiceAccount(ctx, u.accounts, req.ClientID, req.ClientSecret)
+	if err != nil {
+		if errors.Is(err, domain.ErrServiceAccountNotFound) {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidClient, "unknown client")
+		}
+		return nil, err
+	}
+
+	scopes := account.Scopes
+	if req.Scope != "" {
+		scopes = domain.ParseScope(req.Scope)
+		if !account.AllowsScopes(scopes) {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidScope, "scope not allowed for this service account")
+		}
+	}
+	scope := joinScope(scopes)
+	accessToken, err := u.jwt.GenerateServiceAccessToken(account, scope, time.Now().Add(u.accessTTL))
+	if err != nil {
+		return nil, err
+	}
+	return &domain.OAuthTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(u.accessTTL.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+func (u *oauthTokenUseCase) activeUser(ctx context.Context, userID string) (*domain.User, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidGrant, "user is not active")
+	}
+	return user, nil
+}
+
+func (u *oauthTokenUseCase) issue(ctx context.Context, user *domain.User, client *domain.OAuthClient,
+	scope string) (*domain.OAuthTokenResponse, error) {
+	accessToken, err := u.jwt.GenerateOAuthAccessToken(user, client.ID, scope, time.Now().Add(u.accessTTL))
+	if err != nil {
+		return nil, err
+	}
+	refreshRaw, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	err = u.tokenRepo.CreateForClient(ctx, user.ID, client.ID, scope,
+		infrastructure.GenerateTokenHash(refreshRaw), time.Now().Add(u.refreshTTL))
+	if err != nil {
+		return nil, err
+	}
+	return &domain.OAuthTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(u.accessTTL.Seconds()),
+		RefreshToken: refreshRaw,
+		Scope:        scope,
+	}, nil
+}
+
+// authenticateClient checks the secret of confidential clients. Public
+// clients must not send one.
+func authenticateClient(ctx context.Context, clientRepo domain.OAuthClientRepository,
+	clientID, secret string) (*domain.OAuthClient, error) {
+	if clientID == "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidClient, "client authentication required")
+	}
+	client, err := clientRepo.GetByID(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, domain.ErrOAuthClientNotFound) {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidClient, "unknown client")
+		}
+		return nil, err
+	}
+	if client.IsConfidential() {
+		hash := infrastructure.GenerateTokenHash(secret)
+		if secret == "" || subtle.ConstantTimeCompare([]byte(hash), []byte(client.SecretHash)) != 1 {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidClient, "client authentication failed")
+		}
+	} else if secret != "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidClient, "public clients have no secret")
+	}
+	return client, nil
+}
+
+// authenticateServiceAccount checks the secret of a service account. An
+// unknown client_id is returned as ErrServiceAccountNotFound so callers can
+// fall back to OAuth clients.
+func authenticateServiceAccount(ctx context.Context, accounts domain.ServiceAccountRepository,
+	clientID, secret string) (*domain.ServiceAccount, error) {
+	if clientID == "" || secret == "" {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidClient, "client authentication required")
+	}
+	account, err := accounts.GetByClientID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	hash := infrastructure.GenerateTokenHash(secret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(account.SecretHash)) != 1 || !account.IsActive {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidClient, "client authentication failed")
+	}
+	return account, nil
+}
+
+// authenticateTokenCaller authenticates the caller of the introspection and
+// revocation endpoints, either a service account or an OAuth client, and
+// returns its client_id. Public clients are only accepted with allowPublic.
+func authenticateTokenCaller(ctx context.Context, clientRepo domain.OAuthClientRepository,
+	accounts domain.ServiceAccountRepository, clientID, secret string, allowPublic bool) (string, error) {
+	account, err := authenticateServiceAccount(ctx, accounts, clientID, secret)
+	if err == nil {
+		return account.ClientID, nil
+	}
+	var oerr *domain.OAuthError
+	if !errors.Is(err, domain.ErrServiceAccountNotFound) && !errors.As(err, &oerr) {
+		return "", err
+	}
+
+	client, err := authenticateClient(ctx, clientRepo, clientID, secret)
+	if err != nil {
+		return "", err
+	}
+	if !client.IsConfidential() && !allowPublic {
+		return "", domain.NewOAuthError(domain.OAuthInvalidClient, "public clients cannot use this endpoint")
+	}
+	return client.ID, nil
+}
+
+// verifyPKCE checks an S256 code_verifier (RFC 7636 section 4.6).
+func verifyPKCE(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+func joinScope(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyPKCE(t *testing.T) {
+	// challenge is base64url(sha256(verifier)) without padding
+	const (
+		verifier  = "dBjftJeZ4CVP-mJ92K1rbxS1DRmqbwowjPTJnmK6I0o"
+		challenge = "Od10JWW6V6ffuf7QWaLKQ-Piih_gTRQvf2SkQyRxr7s"
+	)
+	tests := []struct {
+		name      string
+		verifier  string
+		challenge string
+		want      bool
+	}{
+		{"s256", verifier, challenge, true},
+		{"wrong verifier", strings.Replace(verifier, "d", "e", 1), challenge, false},
+		{"plain challenge", verifier, verifier, false},
+		{"padded challenge", verifier, challenge + "=", false},
+		{"empty", "", "", false},
+		{"verifier too short", verifier[:42], challenge, false},
+		{"verifier too long", strings.Repeat("a", 129), challenge, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyPKCE(tt.verifier, tt.challenge); got != tt.want {
+				t.Errorf("verifyPKCE(%q, %q) = %v, want %v", tt.verifier, tt.challenge, got, tt.want)
+			}
+		})
+	}
+}
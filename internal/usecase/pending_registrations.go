@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type listPendingRegistrationsUseCase struct {
+	userRepo    domain.UserRepository
+	pendingRepo domain.PendingRegistrationRepository
+}
+
+func NewListPendingRegistrations(userRepo domain.UserRepository,
+	pendingRepo domain.PendingRegistrationRepository) domain.ListPendingRegistrationsUseCase {
+	return &listPendingRegistrationsUseCase{
+		userRepo:    userRepo,
+		pendingRepo: pendingRepo,
+	}
+}
+
+func (u *listPendingRegistrationsUseCase) Execute(ctx context.Context, callerID string) ([]*domain.PendingRegistration, error) {
+	if err := requirePlatformAdmin(ctx, u.userRepo, callerID); err != nil {
+		return nil, err
+	}
+	return u.pendingRepo.List(ctx)
+}
+
+type resolveRegistrationUseCase struct {
+	userRepo    domain.UserRepository
+	pendingRepo domain.PendingRegistrationRepository
+	auditRepo   domain.AuditRepository
+	tx          domain.Transactor
+}
+
+func NewResolveRegistration(userRepo domain.UserRepository, pendingRepo domain.PendingRegistrationRepository,
+	auditRepo domain.AuditRepository, tx domain.Transactor) domain.ResolveRegistrationUseCase {
+	return &resolveRegistrationUseCase{
+		userRepo:    userRepo,
+		pendingRepo: pendingRepo,
+		auditRepo:   auditRepo,
+		tx:          tx,
+	}
+}
+
+func (u *resolveRegistrationUseCase) Execute(ctx context.Context, callerID, userID string, approve bool) error {
+	if err := requirePlatformAdmin(ctx, u.userRepo, callerID); err != nil {
+		return err
+	}
+
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		action := "registration.approved"
+		if approve {
+			err = u.pendingRepo.Approve(ctx, userID)
+		} else {
+			action = "registration.rejected"
+			err = u.pendingRepo.Reject(ctx, userID)
+		}
+		if err != nil {
+			return err
+		}
+		return u.auditRepo.Record(ctx, domain.AuditEntry{
+			ActorID:      callerID,
+			Action:       action,
+			TargetUserID: userID,
+		})
+	})
+}
@@ -0,0 +1,224 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"sync"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+const (
+	// qrLoginPollWait bounds a single long poll; clients poll again after it.
+	qrLoginPollWait     = 25 * time.Second
+	qrLoginPollInterval = time.Second
+	maxUserAgentLength  = 512
+)
+
+type startQRLoginUseCase struct {
+	qrRepo  domain.QRLoginRepository
+	ttl     time.Duration
+	limiter domain.RateLimiter
+}
+
+func NewStartQRLogin(qrRepo domain.QRLoginRepository, ttl time.Duration,
+	limiter domain.RateLimiter) domain.StartQRLoginUseCase {
+	return &startQRLoginUseCase{
+		qrRepo:  qrRepo,
+		ttl:     ttl,
+		limiter: limiter,
+	}
+}
+
+// Execute creates a session. The call is unauthenticated, so sessions are
+// rate limited per client address.
+func (u *startQRLoginUseCase) Execute(ctx context.Context, client domain.ClientInfo) (*domain.QRLoginStart, error) {
+	if !u.limiter.Allow(client.IPAddress) {
+		return nil, domain.ErrTooManyRequests
+	}
+	id, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	if len(client.UserAgent) > maxUserAgentLength {
+		client.UserAgent = client.UserAgent[:maxUserAgentLength]
+	}
+	session := &domain.QRLoginSession{
+		ID:             id,
+		PollSecretHash: infrastructure.GenerateTokenHash(secret),
+		Client:         client,
+		ExpiresAt:      time.Now().Add(u.ttl),
+	}
+	if err := u.qrRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+	return &domain.QRLoginStart{
+		SessionID:  session.ID,
+		PollSecret: secret,
+		ExpiresAt:  session.ExpiresAt,
+	}, nil
+}
+
+type scanQRLoginUseCase struct {
+	qrRepo domain.QRLoginRepository
+}
+
+func NewScanQRLogin(qrRepo domain.QRLoginRepository) domain.ScanQRLoginUseCase {
+	return &scanQRLoginUseCase{qrRepo: qrRepo}
+}
+
+// Execute claims the session for the scanning user. A session can be scanned
+// once, so a code photographed off someone else's screen cannot be approved
+// from a second phone.
+func (u *scanQRLoginUseCase) Execute(ctx context.Context, userID, sessionID string) (*domain.QRLoginSession, error) {
+	return u.qrRepo.Scan(ctx, sessionID, userID)
+}
+
+type approveQRLoginUseCase struct {
+	qrRepo domain.QRLoginRepository
+}
+
+func NewApproveQRLogin(qrRepo domain.QRLoginRepository) domain.ApproveQRLoginUseCase {
+	return &approveQRLoginUseCase{qrRepo: qrRepo}
+}
+
+func (u *approveQRLoginUseCase) Execute(ctx context.Context, userID, sessionID string, approve bool) error {
+	return u.qrRepo.Resolve(ctx, sessionID, userID, approve)
+}
+
+type pollQRLoginUseCase struct {
+	polls      chan struct{}
+	limiter    domain.RateLimiter
+	mu         sync.Mutex
+	polling    map[string]struct{} // session IDs with a poll in progress
+	userRepo   domain.UserRepository
+	tokenRepo  domain.TokenRepository
+	qrRepo     domain.QRLoginRepository
+	jwt        *infrastructure.JWTManager
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewPollQRLogin returns the use case; at most maxPolls long polls wait at
+// the same time, one per session.
+func NewPollQRLogin(userRepo domain.UserRepository, tokenRepo domain.TokenRepository, qrRepo domain.QRLoginRepository,
+	jwt *infrastructure.JWTManager, accessTTL, refreshTTL time.Duration, maxPolls int,
+	limiter domain.RateLimiter) domain.PollQRLoginUseCase {
+	return &pollQRLoginUseCase{
+		polls:      make(chan struct{}, maxPolls),
+		limiter:    limiter,
+		polling:    make(map[string]struct{}),
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		qrRepo:     qrRepo,
+		jwt:        jwt,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// Execute checks the poll secret before taking a slot, so a client that only
+// saw the session ID on screen cannot hold the session's poll.
+func (u *pollQRLoginUseCase) Execute(ctx context.Context, client domain.ClientInfo, sessionID, pollSecret,
+	lastStatus string) (*domain.QRLoginPoll, error) {
+	if !u.limiter.Allow(client.IPAddress) {
+		return nil, domain.ErrTooManyRequests
+	}
+	if _, err := u.get(ctx, sessionID, pollSecret); err != nil {
+		return nil, err
+	}
+	if !u.claim(sessionID) {
+		return nil, domain.ErrQRLoginPolling
+	}
+	defer u.release(sessionID)
+	select {
+	case u.polls <- struct{}{}:
+		defer func() { <-u.polls }()
+	default:
+		return nil, domain.ErrQRLoginBusy
+	}
+
+	deadline := time.NewTimer(qrLoginPollWait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(qrLoginPollInterval)
+	defer ticker.Stop()
+
+	for {
+		session, err := u.get(ctx, sessionID, pollSecret)
+		if err != nil {
+			return nil, err
+		}
+		if session.Status == domain.QRLoginStatusApproved {
+			return u.consume(ctx, sessionID)
+		}
+		if session.Status != lastStatus {
+			return &domain.QRLoginPoll{Status: session.Status}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline.C:
+			return &domain.QRLoginPoll{Status: session.Status}, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// get returns the session if pollSecret matches it.
+func (u *pollQRLoginUseCase) get(ctx context.Context, sessionID, pollSecret string) (*domain.QRLoginSession, error) {
+	session, err := u.qrRepo.Get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	hash := infrastructure.GenerateTokenHash(pollSecret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(session.PollSecretHash)) != 1 {
+		return nil, domain.ErrQRLoginNotFound
+	}
+	return session, nil
+}
+
+func (u *pollQRLoginUseCase) claim(sessionID string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, ok := u.polling[sessionID]; ok {
+		return false
+	}
+	u.polling[sessionID] = struct{}{}
+	return true
+}
+
+func (u *pollQRLoginUseCase) release(sessionID string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.polling, sessionID)
+}
+
+func (u *pollQRLoginUseCase) consume(ctx context.Context, sessionID string) (*domain.QRLoginPoll, error) {
+	userID, err := u.qrRepo.Consume(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, domain.ErrInvalidCredentials
+	}
+	token, err := issueAuthToken(ctx, u.jwt, u.tokenRepo, user, nil, u.accessTTL, u.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.QRLoginPoll{
+		Status: domain.QRLoginStatusConsumed,
+		User:   user,
+		Token:  token,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+const (
+	defaultDecisionLogLimit = 100
+	maxDecisionLogLimit     = 1000
+)
+
+type queryDecisionLogUseCase struct {
+	userRepo        domain.UserRepository
+	membershipRepo  domain.MembershipRepository
+	decisionLogRepo domain.DecisionLogRepository
+}
+
+func NewQueryDecisionLog(userRepo domain.UserRepository, membershipRepo domain.MembershipRepository,
+	decisionLogRepo domain.DecisionLogRepository) domain.QueryDecisionLogUseCase {
+	return &queryDecisionLogUseCase{
+		userRepo:        userRepo,
+		membershipRepo:  membershipRepo,
+		decisionLogRepo: decisionLogRepo,
+	}
+}
+
+// Execute returns the newest decisions matching f. Platform admins may query
+// anything; business owners and admins only the decisions of their business.
+func (u *queryDecisionLogUseCase) Execute(ctx context.Context, callerID string, f domain.DecisionLogFilter) ([]*domain.DecisionRecord, error) {
+	if err := requirePlatformAdmin(ctx, u.userRepo, callerID); err != nil {
+		// everyone else needs a business they own or administer
+		if !errors.Is(err, domain.ErrPermissionDenied) || f.BusinessID == "" {
+			return nil, err
+		}
+		if _, err := requireMembershipRole(ctx, u.membershipRepo, callerID, f.BusinessID,
+			domain.MembershipRoleOwner, domain.MembershipRoleAdmin); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Limit <= 0 {
+		f.Limit = defaultDecisionLogLimit
+	}
+	if f.Limit > maxDecisionLogLimit {
+		f.Limit = maxDecisionLogLimit
+	}
+	return u.decisionLogRepo.Query(ctx, f)
+}
@@ -12,20 +12,23 @@ import (
 )
 
 type refreshUseCase struct {
-	userRepo   domain.UserRepository
-	tokenRepo  domain.TokenRepository
-	jwt        *infrastructure.JWTManager
-	accessTTL  time.Duration
-	refreshTTL time.Duration
+	userRepo       domain.UserRepository
+	tokenRepo      domain.TokenRepository
+	membershipRepo domain.MembershipRepository
+	jwt            *infrastructure.JWTManager
+	accessTTL      time.Duration
+	refreshTTL     time.Duration
 }
 
-func NewRefresh(userRepo domain.UserRepository, tokenRepo domain.TokenRepository, jwt *infrastructure.JWTManager, access, refresh time.Duration) domain.RefreshUseCase {
+func NewRefresh(userRepo domain.UserRepository, tokenRepo domain.TokenRepository, membershipRepo domain.MembershipRepository,
+	jwt *infrastructure.JWTManager, access, refresh time.Duration) domain.RefreshUseCase {
 	return &refreshUseCase{
-		userRepo:   userRepo,
-		tokenRepo:  tokenRepo,
-		jwt:        jwt,
-		accessTTL:  access,
-		refreshTTL: refresh,
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		membershipRepo: membershipRepo,
+		jwt:            jwt,
+		accessTTL:      access,
+		refreshTTL:     refresh,
 	}
 }
 
@@ -38,14 +41,37 @@ func (r *refreshUseCase) Execute(ctx context.Context, refreshToken string) (*dom
 		}
 		return nil, nil, status.Error(codes.Internal, "failed to refresh token")
 	}
+	// tokens issued to OAuth clients are refreshed at the token endpoint, which
+	// authenticates the client and keeps the granted scope
+	if rt.ClientID != "" {
+		return nil, nil, status.Error(codes.Unauthenticated, "refresh token not found or expired")
+	}
 	user, err := r.userRepo.GetByID(ctx, rt.UserID)
 	if err != nil {
 		return nil, nil, status.Error(codes.Internal, "failed to get user")
 	}
 
+	// keep the business context selected via SwitchBusiness, as long as the
+	// membership is still active
+	var membership *domain.Membership
+	if rt.BusinessID != "" {
+		membership, err = requireActiveMembership(ctx, r.membershipRepo, user.ID, rt.BusinessID)
+		if err != nil {
+			if errors.Is(err, domain.ErrPermissionDenied) {
+				return nil, nil, status.Error(codes.PermissionDenied, "business membership is no longer active")
+			}
+			return nil, nil, status.Error(codes.Internal, "failed to get membership")
+		}
+	}
+
 	expireTime := time.Now().Add(r.accessTTL)
 
-	accessToken, err := r.jwt.GenerateAccessToken(user, expireTime)
+	var accessToken string
+	if membership != nil {
+		accessToken, err = r.jwt.GenerateBusinessAccessToken(user, membership, expireTime)
+	} else {
+		accessToken, err = r.jwt.GenerateAccessToken(user, expireTime)
+	}
 	if err != nil {
 		return nil, nil, status.Error(codes.Internal, "failed to generate access token")
 	}
@@ -61,7 +87,12 @@ func (r *refreshUseCase) Execute(ctx context.Context, refreshToken string) (*dom
 		return nil, nil, status.Error(codes.Internal, "failed to delete old refresh token")
 	}
 
-	if err := r.tokenRepo.Create(ctx, user.ID, newHash, time.Now().Add(r.refreshTTL)); err != nil {
+	if membership != nil {
+		err = r.tokenRepo.CreateForBusiness(ctx, user.ID, membership.BusinessID, newHash, time.Now().Add(r.refreshTTL))
+	} else {
+		err = r.tokenRepo.Create(ctx, user.ID, newHash, time.Now().Add(r.refreshTTL))
+	}
+	if err != nil {
 		return nil, nil, status.Error(codes.Internal, "failed to create new refresh token")
 	}
 	return user, &domain.AuthToken{
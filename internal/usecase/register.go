@@ -2,36 +2,48 @@ package usecase
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/ialekseychuk/my-place-identity/internal/domain"
 	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
-	"github.com/jackc/pgx/v5"
 )
 
 type registerUC struct {
-	userRepo   domain.UserRepository
-	tokenRepo  domain.TokenRepository
-	jwt        *infrastructure.JWTManager
-	accessTTL  time.Duration
-	refreshTTL time.Duration
+	userRepo    domain.UserRepository
+	tokenRepo   domain.TokenRepository
+	pendingRepo domain.PendingRegistrationRepository
+	tx          domain.Transactor
+	policy      *domain.RegistrationPolicy
+	domainRules domain.DomainRuleStore
+	jwt         *infrastructure.JWTManager
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
 }
 
 func NewRegister(userRepo domain.UserRepository, tokenRepo domain.TokenRepository,
-	jwt *infrastructure.JWTManager, accessTTL, refreshTTL time.Duration) domain.RegisterUseCase {
+	pendingRepo domain.PendingRegistrationRepository, tx domain.Transactor, policy *domain.RegistrationPolicy,
+	domainRules domain.DomainRuleStore, jwt *infrastructure.JWTManager, accessTTL, refreshTTL time.Duration) domain.RegisterUseCase {
 	return &registerUC{
-		userRepo:   userRepo,
-		tokenRepo:  tokenRepo,
-		jwt:        jwt,
-		accessTTL:  accessTTL,
-		refreshTTL: refreshTTL,
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		pendingRepo: pendingRepo,
+		tx:          tx,
+		policy:      policy,
+		domainRules: domainRules,
+		jwt:         jwt,
+		accessTTL:   accessTTL,
+		refreshTTL:  refreshTTL,
 	}
 }
 
 func (r *registerUC) Execute(ctx context.Context, req domain.RegisterRequest) (*domain.User, *domain.AuthToken, error) {
-	_, err := r.userRepo.GetByEmail(ctx, req.Email)
-
-	if err != nil && err != pgx.ErrNoRows {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	role, mode, err := r.policy.Check(req.Role, email)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := r.domainRules.Rules().Check(email); err != nil {
 		return nil, nil, err
 	}
 
@@ -39,41 +51,40 @@ func (r *registerUC) Execute(ctx context.Context, req domain.RegisterRequest) (*
 	if err != nil {
 		return nil, nil, err
 	}
+	now := time.Now()
 	user := &domain.User{
-		Email:         req.Email,
+		Email:         email,
 		Password:      string(hash),
 		FirstName:     req.FirstName,
 		LastName:      req.LastName,
-		Role:          req.Role,
-		Phone:         req.Phone,
-		IsActive:      true,
+		Role:          role,
+		Phone:         strings.TrimSpace(req.Phone),
+		IsActive:      mode == domain.RegistrationOpen,
 		EmailVerified: false,
 		PhoneVerified: false,
+		CreatedAt:     now,
+		UpdatedAt:     now,
 	}
 
-	if err := r.userRepo.Create(ctx, user); err != nil {
-		return nil, nil, err
+	if mode == domain.RegistrationApproval {
+		err := r.tx.WithinTx(ctx, func(ctx context.Context) error {
+			if err := r.userRepo.Create(ctx, user); err != nil {
+				return err
+			}
+			return r.pendingRepo.Create(ctx, user.ID)
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return user, nil, nil
 	}
 
-	accessExp := time.Now().Add(r.accessTTL)
-	accessToken, err := r.jwt.GenerateAccessToken(user, accessExp)
-
-	if err != nil {
+	if err := r.userRepo.Create(ctx, user); err != nil {
 		return nil, nil, err
 	}
-
-	refreshRaw, _ := infrastructure.GenerateRefreshToken()
-	refreshHash := infrastructure.GenerateTokenHash(refreshRaw)
-
-	if err := r.tokenRepo.Create(ctx, user.ID, refreshHash, time.Now().Add(r.refreshTTL)); err != nil {
+	token, err := issueAuthToken(ctx, r.jwt, r.tokenRepo, user, nil, r.accessTTL, r.refreshTTL)
+	if err != nil {
 		return nil, nil, err
 	}
-
-	return user, &domain.AuthToken{
-		AccessToken:  accessToken,
-		RefreshToken: refreshRaw,
-		ExpiredAt:    accessExp,
-		TokenType:    "Bearer",
-	}, nil
-
+	return user, token, nil
 }
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+type registerOAuthClientUseCase struct {
+	userRepo   domain.UserRepository
+	clientRepo domain.OAuthClientRepository
+}
+
+func NewRegisterOAuthClient(userRepo domain.UserRepository, clientRepo domain.OAuthClientRepository) domain.RegisterOAuthClientUseCase {
+	return &registerOAuthClientUseCase{
+		userRepo:   userRepo,
+		clientRepo: clientRepo,
+	}
+}
+
+// Execute registers a client on behalf of a platform admin.
+func (u *registerOAuthClientUseCase) Execute(ctx context.Context, callerID string, client *domain.OAuthClient,
+	confidential bool) (*domain.OAuthClient, string, error) {
+	if err := requirePlatformAdmin(ctx, u.userRepo, callerID); err != nil {
+		return nil, "", err
+	}
+	if strings.TrimSpace(client.Name) == "" {
+		return nil, "", domain.NewOAuthError(domain.OAuthInvalidRequest, "client name required")
+	}
+	for _, uri := range client.RedirectURIs {
+		if err := validateRedirectURI(uri); err != nil {
+			return nil, "", err
+		}
+	}
+	for _, s := range client.Scopes {
+		if s == "" || strings.ContainsAny(s, " \t\n\"\\") {
+			return nil, "", domain.NewOAuthError(domain.OAuthInvalidScope, fmt.Sprintf("invalid scope %q", s))
+		}
+	}
+
+	id, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+	client.ID = id[:32]
+	client.CreatedBy = callerID
+
+	var secret string
+	if confidential {
+		if secret, err = infrastructure.GenerateRefreshToken(); err != nil {
+			return nil, "", err
+		}
+		client.SecretHash = infrastructure.GenerateTokenHash(secret)
+	}
+	if err := u.clientRepo.Create(ctx, client); err != nil {
+		return nil, "", err
+	}
+	return client, secret, nil
+}
+
+// validateRedirectURI accepts absolute https URIs, http on the loopback
+// interface and private-use schemes of native apps ("com.example.app:/cb").
+// Fragments are never allowed.
+func validateRedirectURI(uri string) error {
+	invalid := domain.NewOAuthError(domain.OAuthInvalidRequest, fmt.Sprintf("invalid redirect uri %q", uri))
+	u, err := url.Parse(uri)
+	if err != nil || !u.IsAbs() || u.Fragment != "" || strings.Contains(uri, "#") {
+		return invalid
+	}
+	switch u.Scheme {
+	case "https":
+		if u.Host == "" {
+			return invalid
+		}
+	case "http":
+		switch u.Hostname() {
+		case "localhost", "127.0.0.1", "::1":
+		default:
+			return invalid
+		}
+	default:
+		if !strings.Contains(u.Scheme, ".") {
+			return invalid
+		}
+	}
+	return nil
+}
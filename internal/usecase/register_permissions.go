@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+var namespacePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,31}$`)
+
+type registerPermissionsUseCase struct {
+	userRepo       domain.UserRepository
+	permissionRepo domain.PermissionRepository
+	auditRepo      domain.AuditRepository
+	tx             domain.Transactor
+}
+
+func NewRegisterPermissions(userRepo domain.UserRepository, permissionRepo domain.PermissionRepository,
+	auditRepo domain.AuditRepository, tx domain.Transactor) domain.RegisterPermissionsUseCase {
+	return &registerPermissionsUseCase{
+		userRepo:       userRepo,
+		permissionRepo: permissionRepo,
+		auditRepo:      auditRepo,
+		tx:             tx,
+	}
+}
+
+// Execute makes the namespace's catalog match req. Registrations older than
+// the stored version are ignored, so a rolling deploy cannot undo a newer
+// one. Permissions that are left out are deprecated rather than deleted:
+// they keep working for existing grants but can no longer be added to roles.
+func (u *registerPermissionsUseCase) Execute(ctx context.Context, req domain.RegisterPermissionsRequest) (*domain.RegisterPermissionsResult, error) {
+	caller, err := u.userRepo.GetByID(ctx, req.CallerID)
+	if err != nil {
+		return nil, err
+	}
+	if caller == nil || !caller.IsActive || caller.Role != domain.UserRoleService {
+		return nil, domain.ErrPermissionDenied
+	}
+	if err := validatePermissionDefinitions(req); err != nil {
+		return nil, err
+	}
+
+	result := &domain.RegisterPermissionsResult{}
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		ns, err := u.permissionRepo.ClaimNamespace(ctx, req.Namespace, req.CallerID)
+		if err != nil {
+			return err
+		}
+		if ns.OwnerID != req.CallerID {
+			return domain.ErrNamespaceOwned
+		}
+		if req.Version < ns.Version {
+			result.Version = ns.Version
+			return nil
+		}
+
+		if err := u.permissionRepo.UpsertDefinitions(ctx, req.Namespace, req.Permissions); err != nil {
+			return err
+		}
+		keep := make([]domain.Permission, 0, len(req.Permissions))
+		for _, d := range req.Permissions {
+			if err := u.permissionRepo.SetDefaultGrants(ctx, d.Permission, d.DefaultRoles); err != nil {
+				return err
+			}
+			keep = append(keep, d.Permission)
+		}
+		deprecated, err := u.permissionRepo.DeprecateMissing(ctx, req.Namespace, keep)
+		if err != nil {
+			return err
+		}
+		if err := u.permissionRepo.SetNamespaceVersion(ctx, req.Namespace, req.Version); err != nil {
+			return err
+		}
+
+		result.Version, result.Applied, result.Deprecated = req.Version, true, deprecated
+		return u.auditRepo.Record(ctx, domain.AuditEntry{
+			ActorID: req.CallerID,
+			Action:  "permissions.registered",
+			Details: map[string]string{
+				"namespace":   req.Namespace,
+				"version":     fmt.Sprint(req.Version),
+				"permissions": fmt.Sprint(len(req.Permissions)),
+				"deprecated":  fmt.Sprint(len(deprecated)),
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// validatePermissionDefinitions requires every resource to be prefixed with
+// the namespace ("booking.appointment"), so services cannot collide.
+func validatePermissionDefinitions(req domain.RegisterPermissionsRequest) error {
+	if !namespacePattern.MatchString(req.Namespace) || req.Namespace == domain.CorePermissionNamespace {
+		return domain.ErrInvalidNamespace
+	}
+	if req.Version < 1 {
+		return fmt.Errorf("%w: version must be positive", domain.ErrInvalidNamespace)
+	}
+
+	seen := make(map[domain.Permission]bool, len(req.Permissions))
+	for _, d := range req.Permissions {
+		p := d.Permission
+		if !strings.HasPrefix(p.Resource, req.Namespace+".") || len(p.Resource) == len(req.Namespace)+1 || p.Action == "" {
+			return fmt.Errorf("%w: %s must be <%s.resource>:<action>", domain.ErrInvalidNamespace, p, req.Namespace)
+		}
+		if seen[p] {
+			return fmt.Errorf("%w: %s declared twice", domain.ErrInvalidNamespace, p)
+		}
+		seen[p] = true
+		for _, role := range d.DefaultRoles {
+			if !domain.IsBuiltinMembershipRole(role) {
+				return domain.ErrInvalidRole
+			}
+		}
+	}
+	return nil
+}
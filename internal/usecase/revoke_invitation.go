@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type revokeInvitationUseCase struct {
+	membershipRepo domain.MembershipRepository
+	invitationRepo domain.InvitationRepository
+}
+
+func NewRevokeInvitation(membershipRepo domain.MembershipRepository, invitationRepo domain.InvitationRepository) domain.RevokeInvitationUseCase {
+	return &revokeInvitationUseCase{
+		membershipRepo: membershipRepo,
+		invitationRepo: invitationRepo,
+	}
+}
+
+func (u *revokeInvitationUseCase) Execute(ctx context.Context, callerID, invitationID string) error {
+	inv, err := u.invitationRepo.GetByID(ctx, invitationID)
+	if err != nil {
+		return err
+	}
+	if _, err := requireMembershipRole(ctx, u.membershipRepo, callerID, inv.BusinessID,
+		domain.MembershipRoleOwner, domain.MembershipRoleAdmin); err != nil {
+		return err
+	}
+	return u.invitationRepo.Revoke(ctx, inv.ID)
+}
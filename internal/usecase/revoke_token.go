@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+type revokeTokenUseCase struct {
+	tokenRepo  domain.TokenRepository
+	clientRepo domain.OAuthClientRepository
+	accounts   domain.ServiceAccountRepository
+	jwt        *infrastructure.JWTManager
+}
+
+func NewRevokeToken(tokenRepo domain.TokenRepository, clientRepo domain.OAuthClientRepository,
+	accounts domain.ServiceAccountRepository, jwt *infrastructure.JWTManager) domain.RevokeTokenUseCase {
+	return &revokeTokenUseCase{
+		tokenRepo:  tokenRepo,
+		clientRepo: clientRepo,
+		accounts:   accounts,
+		jwt:        jwt,
+	}
+}
+
+// Execute revokes refresh tokens only. Access tokens are self-contained
+// JWTs that stay valid until they expire, so they are answered with
+// unsupported_token_type as RFC 7009 section 2.2.1 allows.
+func (u *revokeTokenUseCase) Execute(ctx context.Context, req domain.ClientTokenRequest) error {
+	callerID, err := authenticateTokenCaller(ctx, u.clientRepo, u.accounts, req.ClientID, req.ClientSecret, true)
+	if err != nil {
+		return err
+	}
+	if req.Token == "" {
+		return domain.NewOAuthError(domain.OAuthInvalidRequest, "token required")
+	}
+
+	hash := infrastructure.GenerateTokenHash(req.Token)
+	rt, err := u.tokenRepo.FindByHash(ctx, hash)
+	if errors.Is(err, domain.ErrRefreshTokenNotFound) {
+		if _, verr := u.jwt.ValidateAccessToken(req.Token); verr == nil {
+			return domain.NewOAuthError(domain.OAuthUnsupportedTokenType, "access tokens expire on their own")
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if rt.ClientID != callerID {
+		return domain.NewOAuthError(domain.OAuthUnauthorizedClient, "token was issued to another client")
+	}
+	if err := u.tokenRepo.DeleteByHash(ctx, hash); err != nil && !errors.Is(err, domain.ErrRefreshTokenNotFound) {
+		return err
+	}
+	return nil
+}
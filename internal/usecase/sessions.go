@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type listSessionsUseCase struct {
+	tokenRepo domain.TokenRepository
+}
+
+func NewListSessions(tokenRepo domain.TokenRepository) domain.ListSessionsUseCase {
+	return &listSessionsUseCase{
+		tokenRepo: tokenRepo,
+	}
+}
+
+func (u *listSessionsUseCase) Execute(ctx context.Context, userID string) ([]*domain.Session, error) {
+	return u.tokenRepo.ListSessions(ctx, userID)
+}
+
+type revokeSessionUseCase struct {
+	tokenRepo domain.TokenRepository
+}
+
+func NewRevokeSession(tokenRepo domain.TokenRepository) domain.RevokeSessionUseCase {
+	return &revokeSessionUseCase{
+		tokenRepo: tokenRepo,
+	}
+}
+
+// Execute signs out one app or device. Its access token stays valid until it
+// expires, but it can no longer be refreshed.
+func (u *revokeSessionUseCase) Execute(ctx context.Context, userID, sessionID string) error {
+	return u.tokenRepo.DeleteSession(ctx, userID, sessionID)
+}
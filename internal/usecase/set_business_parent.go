@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type setBusinessParentUseCase struct {
+	membershipRepo domain.MembershipRepository
+	unitRepo       domain.BusinessUnitRepository
+	tx             domain.Transactor
+}
+
+func NewSetBusinessParent(membershipRepo domain.MembershipRepository, unitRepo domain.BusinessUnitRepository,
+	tx domain.Transactor) domain.SetBusinessParentUseCase {
+	return &setBusinessParentUseCase{
+		membershipRepo: membershipRepo,
+		unitRepo:       unitRepo,
+		tx:             tx,
+	}
+}
+
+// Execute moves businessID under parentID, or detaches it when parentID is
+// empty. The caller must own both businesses.
+func (u *setBusinessParentUseCase) Execute(ctx context.Context, callerID, businessID, parentID string) error {
+	if _, err := requireMembershipRole(ctx, u.membershipRepo, callerID, businessID,
+		domain.MembershipRoleOwner); err != nil {
+		return err
+	}
+	if parentID != "" {
+		if _, err := requireMembershipRole(ctx, u.membershipRepo, callerID, parentID,
+			domain.MembershipRoleOwner); err != nil {
+			return err
+		}
+	}
+
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// the cycle check below must see every move committed before ours
+		if err := u.unitRepo.LockHierarchy(ctx); err != nil {
+			return err
+		}
+		if parentID != "" {
+			ancestors, err := u.unitRepo.Ancestors(ctx, parentID)
+			if err != nil {
+				return err
+			}
+			for _, id := range ancestors {
+				if id == businessID {
+					return domain.ErrHierarchyCycle
+				}
+			}
+		}
+		return u.unitRepo.SetParent(ctx, businessID, parentID)
+	})
+}
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	maxDeviceNameLength = 100
+	pinMaxAttempts      = 5
+	pinLockout          = 15 * time.Minute
+	// 15 guesses in all before the PIN has to be set again
+	pinMaxLockouts = 3
+)
+
+var pinPattern = regexp.MustCompile(`^[0-9]{4,8}$`)
+
+type registerSharedDeviceUseCase struct {
+	membershipRepo domain.MembershipRepository
+	deviceRepo     domain.SharedDeviceRepository
+}
+
+func NewRegisterSharedDevice(membershipRepo domain.MembershipRepository,
+	deviceRepo domain.SharedDeviceRepository) domain.RegisterSharedDeviceUseCase {
+	return &registerSharedDeviceUseCase{
+		membershipRepo: membershipRepo,
+		deviceRepo:     deviceRepo,
+	}
+}
+
+func (u *registerSharedDeviceUseCase) Execute(ctx context.Context, callerID string,
+	d *domain.SharedDevice) (*domain.SharedDevice, string, error) {
+	d.Name = strings.TrimSpace(d.Name)
+	if d.Name == "" || len(d.Name) > maxDeviceNameLength {
+		return nil, "", domain.ErrInvalidDeviceName
+	}
+	if _, err := requireMembershipRole(ctx, u.membershipRepo, callerID, d.BusinessID,
+		domain.MembershipRoleOwner, domain.MembershipRoleAdmin); err != nil {
+		return nil, "", err
+	}
+
+	secret, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+	d.SecretHash = infrastructure.GenerateTokenHash(secret)
+	d.CreatedBy = callerID
+	if err := u.deviceRepo.Create(ctx, d); err != nil {
+		return nil, "", err
+	}
+	return d, secret, nil
+}
+
+type revokeSharedDeviceUseCase struct {
+	membershipRepo domain.MembershipRepository
+	deviceRepo     domain.SharedDeviceRepository
+}
+
+func NewRevokeSharedDevice(membershipRepo domain.MembershipRepository,
+	deviceRepo domain.SharedDeviceRepository) domain.RevokeSharedDeviceUseCase {
+	return &revokeSharedDeviceUseCase{
+		membershipRepo: membershipRepo,
+		deviceRepo:     deviceRepo,
+	}
+}
+
+func (u *revokeSharedDeviceUseCase) Execute(ctx context.Context, callerID, deviceID string) error {
+	d, err := u.deviceRepo.GetByID(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+	if _, err := requireMembershipRole(ctx, u.membershipRepo, callerID, d.BusinessID,
+		domain.MembershipRoleOwner, domain.MembershipRoleAdmin); err != nil {
+		return err
+	}
+	return u.deviceRepo.Revoke(ctx, deviceID)
+}
+
+type setDevicePinUseCase struct {
+	membershipRepo domain.MembershipRepository
+	deviceRepo     domain.SharedDeviceRepository
+}
+
+func NewSetDevicePin(membershipRepo domain.MembershipRepository,
+	deviceRepo domain.SharedDeviceRepository) domain.SetDevicePinUseCase {
+	return &setDevicePinUseCase{
+		membershipRepo: membershipRepo,
+		deviceRepo:     deviceRepo,
+	}
+}
+
+// Execute sets the caller's own PIN; any active member of the device's
+// business may have one.
+func (u *setDevicePinUseCase) Execute(ctx context.Context, userID, deviceID, pin string) error {
+	if !pinPattern.MatchString(pin) {
+		return domain.ErrInvalidPin
+	}
+	d, err := u.deviceRepo.GetByID(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+	if _, err := requireActiveMembership(ctx, u.membershipRepo, userID, d.BusinessID); err != nil {
+		return err
+	}
+	hash, err := infrastructure.GeneratePassworHash(pin)
+	if err != nil {
+		return err
+	}
+	return u.deviceRepo.SetPin(ctx, deviceID, userID, string(hash))
+}
+
+type listDeviceUsersUseCase struct {
+	membershipRepo domain.MembershipRepository
+	deviceRepo     domain.SharedDeviceRepository
+}
+
+func NewListDeviceUsers(membershipRepo domain.MembershipRepository,
+	deviceRepo domain.SharedDeviceRepository) domain.ListDeviceUsersUseCase {
+	return &listDeviceUsersUseCase{
+		membershipRepo: membershipRepo,
+		deviceRepo:     deviceRepo,
+	}
+}
+
+// Execute lists who can sign in on the device. Members who have left the
+// business keep their PIN row but are not shown.
+func (u *listDeviceUsersUseCase) Execute(ctx context.Context, deviceID, deviceSecret string) ([]*domain.DeviceUser, error) {
+	d, err := authenticateDevice(ctx, u.deviceRepo, deviceID, deviceSecret)
+	if err != nil {
+		return nil, err
+	}
+	users, err := u.deviceRepo.ListUsers(ctx, d.ID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*domain.DeviceUser, 0, len(users))
+	for _, du := range users {
+		_, err := requireActiveMembership(ctx, u.membershipRepo, du.UserID, d.BusinessID)
+		if err == nil {
+			out = append(out, du)
+		} else if !errors.Is(err, domain.ErrPermissionDenied) {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+type pinLoginUseCase struct {
+	userRepo       domain.UserRepository
+	membershipRepo domain.MembershipRepository
+	deviceRepo     domain.SharedDeviceRepository
+	jwt            *infrastructure.JWTManager
+	ttl            time.Duration
+}
+
+func NewPinLogin(userRepo domain.UserRepository, membershipRepo domain.MembershipRepository,
+	deviceRepo domain.SharedDeviceRepository, jwt *infrastructure.JWTManager, ttl time.Duration) domain.PinLoginUseCase {
+	return &pinLoginUseCase{
+		userRepo:       userRepo,
+		membershipRepo: membershipRepo,
+		deviceRepo:     deviceRepo,
+		jwt:            jwt,
+		ttl:            ttl,
+	}
+}
+
+func (u *pinLoginUseCase) Execute(ctx context.Context, req domain.PinLoginRequest) (*domain.User, *domain.AuthToken, error) {
+	d, err := authenticateDevice(ctx, u.deviceRepo, req.DeviceID, req.DeviceSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the attempt is counted before bcrypt runs and given back on success
+	pin, err := u.deviceRepo.ReservePinAttempt(ctx, d.ID, req.UserID, pinMaxAttempts, pinLockout, pinMaxLockouts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(pin.PinHash), []byte(req.Pin)); err != nil {
+		return nil, nil, domain.ErrInvalidCredentials
+	}
+	if err := u.deviceRepo.ResetPinFailures(ctx, d.ID, req.UserID); err != nil {
+		return nil, nil, err
+	}
+
+	user, err := u.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, domain.ErrInvalidCredentials
+	}
+	if !user.IsActive {
+		return nil, nil, domain.ErrUserNotActive
+	}
+	m, err := requireActiveMembership(ctx, u.membershipRepo, user.ID, d.BusinessID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	expiry := time.Now().Add(u.ttl)
+	accessToken, err := u.jwt.GenerateDeviceAccessToken(user, m, d.ID, expiry)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, &domain.AuthToken{
+		AccessToken: accessToken,
+		ExpiredAt:   expiry,
+		TokenType:   "Bearer",
+	}, nil
+}
+
+// authenticateDevice checks the device credential. Unknown, revoked and
+// mismatched devices all fail with ErrInvalidCredentials.
+func authenticateDevice(ctx context.Context, repo domain.SharedDeviceRepository,
+	deviceID, secret string) (*domain.SharedDevice, error) {
+	d, err := repo.GetByID(ctx, deviceID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSharedDeviceNotFound) {
+			return nil, domain.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	hash := infrastructure.GenerateTokenHash(secret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(d.SecretHash)) != 1 {
+		return nil, domain.ErrInvalidCredentials
+	}
+	return d, nil
+}
@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	confirmationCodeTTL         = 10 * time.Minute
+	confirmationCodeMaxAttempts = 5
+)
+
+type startOwnershipTransferUseCase struct {
+	userRepo       domain.UserRepository
+	membershipRepo domain.MembershipRepository
+	transferRepo   domain.OwnershipTransferRepository
+	auditRepo      domain.AuditRepository
+	tx             domain.Transactor
+	notifier       domain.Notifier
+	ttl            time.Duration
+	codeRepo       domain.VerificationCodeRepository
+	limiter        domain.RateLimiter
+}
+
+func NewStartOwnershipTransfer(userRepo domain.UserRepository, membershipRepo domain.MembershipRepository,
+	transferRepo domain.OwnershipTransferRepository, auditRepo domain.AuditRepository, tx domain.Transactor,
+	notifier domain.Notifier, ttl time.Duration, codeRepo domain.VerificationCodeRepository,
+	limiter domain.RateLimiter) domain.StartOwnershipTransferUseCase {
+	return &startOwnershipTransferUseCase{
+		userRepo:       userRepo,
+		membershipRepo: membershipRepo,
+		transferRepo:   transferRepo,
+		auditRepo:      auditRepo,
+		tx:             tx,
+		notifier:       notifier,
+		ttl:            ttl,
+		codeRepo:       codeRepo,
+		limiter:        limiter,
+	}
+}
+
+// Execute offers ownership of the business to the recipient. The owner has
+// to re-authenticate, so a stolen access token alone cannot give the
+// business away: with their password, or with a code sent to their email or
+// phone, which works for owners who sign in without one. Attempts are rate
+// limited per owner.
+func (u *startOwnershipTransferUseCase) Execute(ctx context.Context, req domain.StartOwnershipTransferRequest) (*domain.OwnershipTransfer, error) {
+	if !u.limiter.Allow(req.CallerID) {
+		return nil, domain.ErrTooManyRequests
+	}
+	owner, err := u.userRepo.GetByID(ctx, req.CallerID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, domain.ErrInvalidCredentials
+	}
+	if err := requireDirectOwner(ctx, u.membershipRepo, owner.ID, req.BusinessID); err != nil {
+		return nil, err
+	}
+	if err := u.reauthenticate(ctx, owner, req); err != nil {
+		return nil, err
+	}
+
+	recipient, err := u.userRepo.GetByEmail(ctx, req.RecipientEmail)
+	if err != nil {
+		return nil, err
+	}
+	if recipient == nil {
+		return nil, domain.ErrUserNotFound
+	}
+	if !recipient.IsActive {
+		return nil, domain.ErrUserNotActive
+	}
+	if recipient.ID == owner.ID {
+		return nil, domain.ErrPermissionDenied
+	}
+
+	t := &domain.OwnershipTransfer{
+		BusinessID: req.BusinessID,
+		FromUserID: owner.ID,
+		ToUserID:   recipient.ID,
+		ExpiresAt:  time.Now().Add(u.ttl),
+	}
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.transferRepo.Create(ctx, t); err != nil {
+			return err
+		}
+		return u.auditRepo.Record(ctx, domain.AuditEntry{
+			ActorID:      owner.ID,
+			Action:       "ownership_transfer.started",
+			BusinessID:   t.BusinessID,
+			TargetUserID: recipient.ID,
+			Details:      map[string]string{"transfer_id": t.ID},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	notifyTransferParties(ctx, u.notifier, t, owner, recipient, "ownership_transfer_started")
+	return t, nil
+}
+
+// reauthenticate checks the password or confirmation code in req. With
+// neither, it sends a new code and fails with ErrConfirmationRequired.
+func (u *startOwnershipTransferUseCase) reauthenticate(ctx context.Context, owner *domain.User,
+	req domain.StartOwnershipTransferRequest) error {
+	purpose := "ownership_transfer:" + req.BusinessID
+	switch {
+	case req.Password != "":
+		if bcrypt.CompareHashAndPassword([]byte(owner.Password), []byte(req.Password)) != nil {
+			return domain.ErrInvalidCredentials
+		}
+		return nil
+	case req.ConfirmationCode != "":
+		code, err := u.codeRepo.ReserveAttempt(ctx, owner.ID, purpose, confirmationCodeMaxAttempts)
+		if err != nil {
+			return err
+		}
+		hash := infrastructure.GenerateTokenHash(req.ConfirmationCode)
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(code.CodeHash)) != 1 {
+			return domain.ErrInvalidCredentials
+		}
+		return u.codeRepo.Delete(ctx, owner.ID, purpose)
+	}
+
+	if owner.Email == "" && owner.Phone == "" {
+		return fmt.Errorf("%w: no email or phone to send a confirmation code to", domain.ErrPermissionDenied)
+	}
+	code, err := generateConfirmationCode()
+	if err != nil {
+		return err
+	}
+	err = u.codeRepo.Create(ctx, &domain.VerificationCode{
+		UserID:    owner.ID,
+		Purpose:   purpose,
+		CodeHash:  infrastructure.GenerateTokenHash(code),
+		ExpiresAt: time.Now().Add(confirmationCodeTTL),
+	})
+	if err != nil {
+		return err
+	}
+	err = u.notifier.Notify(ctx, domain.Notification{
+		Template: "ownership_transfer_confirmation",
+		Email:    owner.Email,
+		Phone:    owner.Phone,
+		Data: map[string]string{
+			"code":            code,
+			"business_id":     req.BusinessID,
+			"recipient_email": req.RecipientEmail,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return domain.ErrConfirmationRequired
+}
+
+// generateConfirmationCode returns six random digits. Guessing is bounded
+// by confirmationCodeMaxAttempts.
+func generateConfirmationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// requireDirectOwner checks for an active owner membership granted at the
+// business itself; inherited ownership cannot be handed over.
+func requireDirectOwner(ctx context.Context, repo domain.MembershipRepository, userID, businessID string) error {
+	m, err := repo.GetByUserAndBusiness(ctx, userID, businessID)
+	if err != nil {
+		if errors.Is(err, domain.ErrMembershipNotFound) {
+			return domain.ErrPermissionDenied
+		}
+		return err
+	}
+	if !m.IsActive || m.CustomRoleID != "" || m.Role != domain.MembershipRoleOwner {
+		return domain.ErrPermissionDenied
+	}
+	return nil
+}
+
+// notifyTransferParties tells both sides about a transfer state change.
+// Delivery failures are logged and do not undo the transfer.
+func notifyTransferParties(ctx context.Context, notifier domain.Notifier, t *domain.OwnershipTransfer,
+	owner, recipient *domain.User, template string) {
+	data := map[string]string{
+		"transfer_id": t.ID,
+		"business_id": t.BusinessID,
+		"from_user":   owner.Email,
+		"to_user":     recipient.Email,
+		"expires_at":  t.ExpiresAt.Format(time.RFC3339),
+	}
+	for _, user := range []*domain.User{owner, recipient} {
+		if err := notifier.Notify(ctx, domain.Notification{
+			Template: template,
+			Email:    user.Email,
+			Phone:    user.Phone,
+			Data:     data,
+		}); err != nil {
+			logrus.Warnf("ownership transfer %s: notify %s: %v", t.ID, user.ID, err)
+		}
+	}
+}
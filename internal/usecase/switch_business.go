@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+type switchBusinessUseCase struct {
+	userRepo       domain.UserRepository
+	tokenRepo      domain.TokenRepository
+	membershipRepo domain.MembershipRepository
+	jwt            *infrastructure.JWTManager
+	accessTTL      time.Duration
+	refreshTTL     time.Duration
+}
+
+func NewSwitchBusiness(userRepo domain.UserRepository, tokenRepo domain.TokenRepository,
+	membershipRepo domain.MembershipRepository, jwt *infrastructure.JWTManager,
+	accessTTL, refreshTTL time.Duration) domain.SwitchBusinessUseCase {
+	return &switchBusinessUseCase{
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		membershipRepo: membershipRepo,
+		jwt:            jwt,
+		accessTTL:      accessTTL,
+		refreshTTL:     refreshTTL,
+	}
+}
+
+// Execute issues tokens bound to businessID. An empty businessID drops the
+// business context and returns global tokens.
+func (u *switchBusinessUseCase) Execute(ctx context.Context, userID, businessID string) (*domain.AuthToken, error) {
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain.ErrInvalidCredentials
+	}
+	if !user.IsActive {
+		return nil, domain.ErrUserNotActive
+	}
+
+	var m *domain.Membership
+	if businessID != "" {
+		m, err = requireActiveMembership(ctx, u.membershipRepo, userID, businessID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return issueAuthToken(ctx, u.jwt, u.tokenRepo, user, m, u.accessTTL, u.refreshTTL)
+}
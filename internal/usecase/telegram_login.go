@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+type telegramLoginUseCase struct {
+	verifier *infrastructure.TelegramAuthVerifier
+	signIn   *externalSignIn
+}
+
+// NewTelegramLogin returns the use case; with a nil verifier (no bot token
+// configured) every call fails with ErrIdentityProviderNotFound.
+func NewTelegramLogin(verifier *infrastructure.TelegramAuthVerifier, userRepo domain.UserRepository,
+	identityRepo domain.LinkedIdentityRepository, tokenRepo domain.TokenRepository, tx domain.Transactor,
+	policy *domain.RegistrationPolicy, domainRules domain.DomainRuleStore, jwt *infrastructure.JWTManager,
+	accessTTL, refreshTTL time.Duration) domain.TelegramLoginUseCase {
+	return &telegramLoginUseCase{
+		verifier: verifier,
+		signIn: &externalSignIn{
+			userRepo:     userRepo,
+			identityRepo: identityRepo,
+			tokenRepo:    tokenRepo,
+			tx:           tx,
+			policy:       policy,
+			domainRules:  domainRules,
+			jwt:          jwt,
+			accessTTL:    accessTTL,
+			refreshTTL:   refreshTTL,
+		},
+	}
+}
+
+func (u *telegramLoginUseCase) Execute(ctx context.Context, data map[string]string) (*domain.FederatedLoginResult, error) {
+	if u.verifier == nil {
+		return nil, domain.ErrIdentityProviderNotFound
+	}
+	ext, err := u.verifier.Verify(data, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return u.signIn.execute(ctx, ext)
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+// actorRoles are the global roles that may be allowed to act on behalf of
+// other users; service accounts never can.
+var actorRoles = []string{domain.UserRoleClient, domain.UserRoleOwner, domain.UserRoleAdmin, domain.UserRoleMaster}
+
+type setTokenExchangePolicyUseCase struct {
+	userRepo   domain.UserRepository
+	policyRepo domain.TokenExchangePolicyRepository
+}
+
+func NewSetTokenExchangePolicy(userRepo domain.UserRepository,
+	policyRepo domain.TokenExchangePolicyRepository) domain.SetTokenExchangePolicyUseCase {
+	return &setTokenExchangePolicyUseCase{
+		userRepo:   userRepo,
+		policyRepo: policyRepo,
+	}
+}
+
+func (u *setTokenExchangePolicyUseCase) Execute(ctx context.Context, callerID string,
+	p *domain.TokenExchangePolicy) (*domain.TokenExchangePolicy, error) {
+	if err := requirePlatformAdmin(ctx, u.userRepo, callerID); err != nil {
+		return nil, err
+	}
+	if p.ClientID == "" || p.Audience == "" || len(p.Audience) > maxAudienceLength || strings.ContainsAny(p.Audience, " \t\n") {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidRequest, "client id and a single audience required")
+	}
+	if len(p.Scopes) == 0 {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidScope, "at least one scope required")
+	}
+	for _, s := range p.Scopes {
+		if s == "" || strings.ContainsAny(s, " \t\n\"\\") {
+			return nil, domain.NewOAuthError(domain.OAuthInvalidScope, fmt.Sprintf("invalid scope %q", s))
+		}
+	}
+	for _, r := range p.ActorRoles {
+		if !slices.Contains(actorRoles, r) {
+			return nil, domain.ErrInvalidRole
+		}
+	}
+
+	p.CreatedBy = callerID
+	if err := u.policyRepo.Upsert(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+type deleteTokenExchangePolicyUseCase struct {
+	userRepo   domain.UserRepository
+	policyRepo domain.TokenExchangePolicyRepository
+}
+
+func NewDeleteTokenExchangePolicy(userRepo domain.UserRepository,
+	policyRepo domain.TokenExchangePolicyRepository) domain.DeleteTokenExchangePolicyUseCase {
+	return &deleteTokenExchangePolicyUseCase{
+		userRepo:   userRepo,
+		policyRepo: policyRepo,
+	}
+}
+
+func (u *deleteTokenExchangePolicyUseCase) Execute(ctx context.Context, callerID, clientID, audience string) error {
+	if err := requirePlatformAdmin(ctx, u.userRepo, callerID); err != nil {
+		return err
+	}
+	return u.policyRepo.Delete(ctx, clientID, audience)
+}
+
+func requirePlatformAdmin(ctx context.Context, userRepo domain.UserRepository, callerID string) error {
+	caller, err := userRepo.GetByID(ctx, callerID)
+	if err != nil {
+		return err
+	}
+	if caller == nil || !caller.IsActive || caller.Role != domain.UserRoleAdmin {
+		return domain.ErrPermissionDenied
+	}
+	return nil
+}
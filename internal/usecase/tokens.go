@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+// issueAuthToken signs an access token for user and stores a new refresh token.
+// With a non-nil membership both tokens are bound to that business.
+func issueAuthToken(ctx context.Context, jwt *infrastructure.JWTManager, tokenRepo domain.TokenRepository,
+	user *domain.User, m *domain.Membership, accessTTL, refreshTTL time.Duration) (*domain.AuthToken, error) {
+	accessExp := time.Now().Add(accessTTL)
+
+	var accessToken string
+	var err error
+	if m != nil {
+		accessToken, err = jwt.GenerateBusinessAccessToken(user, m, accessExp)
+	} else {
+		accessToken, err = jwt.GenerateAccessToken(user, accessExp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	refreshRaw, err := infrastructure.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	refreshHash := infrastructure.GenerateTokenHash(refreshRaw)
+	refreshExp := time.Now().Add(refreshTTL)
+
+	if m != nil {
+		err = tokenRepo.CreateForBusiness(ctx, user.ID, m.BusinessID, refreshHash, refreshExp)
+	} else {
+		err = tokenRepo.Create(ctx, user.ID, refreshHash, refreshExp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AuthToken{
+		AccessToken:  accessToken,
+		RefreshToken: refreshRaw,
+		ExpiredAt:    accessExp,
+		TokenType:    "Bearer",
+	}, nil
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type updateCustomRoleUseCase struct {
+	membershipRepo domain.MembershipRepository
+	roleRepo       domain.CustomRoleRepository
+	permissionRepo domain.PermissionRepository
+	tx             domain.Transactor
+}
+
+func NewUpdateCustomRole(membershipRepo domain.MembershipRepository, roleRepo domain.CustomRoleRepository,
+	permissionRepo domain.PermissionRepository, tx domain.Transactor) domain.UpdateCustomRoleUseCase {
+	return &updateCustomRoleUseCase{
+		membershipRepo: membershipRepo,
+		roleRepo:       roleRepo,
+		permissionRepo: permissionRepo,
+		tx:             tx,
+	}
+}
+
+// Execute replaces the name, description and permission set of the role.
+func (u *updateCustomRoleUseCase) Execute(ctx context.Context, callerID string, role *domain.CustomRole) (*domain.CustomRole, error) {
+	if err := validateCustomRoleName(role.Name); err != nil {
+		return nil, err
+	}
+	current, err := u.roleRepo.GetByID(ctx, role.ID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := requireMembershipRole(ctx, u.membershipRepo, callerID, current.BusinessID,
+		domain.MembershipRoleOwner); err != nil {
+		return nil, err
+	}
+	if err := rejectDeprecated(ctx, u.permissionRepo, role.Permissions, current.Permissions); err != nil {
+		return nil, err
+	}
+
+	current.Name = role.Name
+	current.Description = role.Description
+	current.Permissions = role.Permissions
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.roleRepo.Update(ctx, current); err != nil {
+			return err
+		}
+		return u.roleRepo.SetPermissions(ctx, current.ID, current.Permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return current, nil
+}
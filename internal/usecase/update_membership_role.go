@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+)
+
+type updateMembershipRoleUseCase struct {
+	membershipRepo domain.MembershipRepository
+	roleRepo       domain.CustomRoleRepository
+}
+
+func NewUpdateMembershipRole(membershipRepo domain.MembershipRepository, roleRepo domain.CustomRoleRepository) domain.UpdateMembershipRoleUseCase {
+	return &updateMembershipRoleUseCase{
+		membershipRepo: membershipRepo,
+		roleRepo:       roleRepo,
+	}
+}
+
+// Execute assigns a built-in or custom role to a member. The owner role can
+// neither be granted nor taken away here.
+func (u *updateMembershipRoleUseCase) Execute(ctx context.Context, req domain.UpdateMembershipRoleRequest) (*domain.Membership, error) {
+	if (req.Role == "") == (req.CustomRoleID == "") {
+		return nil, domain.ErrInvalidRole
+	}
+	if req.Role != "" && (!domain.IsBuiltinMembershipRole(req.Role) || req.Role == domain.MembershipRoleOwner) {
+		return nil, domain.ErrInvalidRole
+	}
+	if _, err := requireMembershipRole(ctx, u.membershipRepo, req.CallerID, req.BusinessID,
+		domain.MembershipRoleOwner); err != nil {
+		return nil, err
+	}
+
+	m, err := u.membershipRepo.GetByUserAndBusiness(ctx, req.UserID, req.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+	if m.CustomRoleID == "" && m.Role == domain.MembershipRoleOwner {
+		return nil, domain.ErrPermissionDenied
+	}
+
+	m.Role, m.CustomRoleID, m.CustomRoleName = req.Role, "", ""
+	if req.CustomRoleID != "" {
+		role, err := u.roleRepo.GetByID(ctx, req.CustomRoleID)
+		if err != nil {
+			return nil, err
+		}
+		if role.BusinessID != req.BusinessID {
+			return nil, domain.ErrRoleNotFound
+		}
+		m.CustomRoleID, m.CustomRoleName = role.ID, role.Name
+	}
+
+	if err := u.membershipRepo.UpdateRole(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
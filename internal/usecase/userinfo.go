@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/infrastructure"
+)
+
+type userInfoUseCase struct {
+	userRepo domain.UserRepository
+	jwt      *infrastructure.JWTManager
+}
+
+func NewUserInfo(userRepo domain.UserRepository, jwt *infrastructure.JWTManager) domain.UserInfoUseCase {
+	return &userInfoUseCase{
+		userRepo: userRepo,
+		jwt:      jwt,
+	}
+}
+
+func (u *userInfoUseCase) Execute(ctx context.Context, accessToken string) (map[string]any, error) {
+	claims, err := u.jwt.ValidateAccessToken(accessToken)
+	if err != nil {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidToken, "access token is invalid or expired")
+	}
+	// first-party tokens carry no scope and are not meant for userinfo
+	scopes := domain.ParseScope(claims.Scope)
+	if claims.ClientID == "" || !slices.Contains(scopes, domain.ScopeOpenID) {
+		return nil, domain.NewOAuthError(domain.OAuthInsufficientScope, "the openid scope is required")
+	}
+
+	user, err := u.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, domain.NewOAuthError(domain.OAuthInvalidToken, "user is not active")
+	}
+	out := map[string]any{"sub": user.ID}
+	for k, v := range standardClaims(user, scopes) {
+		out[k] = v
+	}
+	return out, nil
+}
+
+// standardClaims maps user fields to the OpenID Connect standard claims
+// released by scopes. Empty fields are left out rather than sent blank.
+func standardClaims(user *domain.User, scopes []string) map[string]any {
+	out := map[string]any{}
+	if slices.Contains(scopes, domain.ScopeProfile) {
+		if user.FirstName != "" {
+			out["given_name"] = user.FirstName
+		}
+		if user.LastName != "" {
+			out["family_name"] = user.LastName
+		}
+	}
+	if slices.Contains(scopes, domain.ScopeEmail) && user.Email != "" {
+		out["email"] = user.Email
+		out["email_verified"] = user.EmailVerified
+	}
+	if slices.Contains(scopes, domain.ScopePhone) && user.Phone != "" {
+		out["phone_number"] = user.Phone
+		out["phone_number_verified"] = user.PhoneVerified
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/ialekseychuk/my-place-identity/internal/domain"
+	"github.com/ialekseychuk/my-place-identity/internal/rebac"
+)
+
+type writeRelationsUseCase struct {
+	userRepo  domain.UserRepository
+	tupleRepo domain.RelationTupleRepository
+	tx        domain.Transactor
+	schema    *rebac.Schema
+}
+
+func NewWriteRelations(userRepo domain.UserRepository, tupleRepo domain.RelationTupleRepository,
+	tx domain.Transactor, schema *rebac.Schema) domain.WriteRelationsUseCase {
+	return &writeRelationsUseCase{
+		userRepo:  userRepo,
+		tupleRepo: tupleRepo,
+		tx:        tx,
+		schema:    schema,
+	}
+}
+
+// Execute applies the batch atomically and returns a consistency token that
+// later reads can pass to be sure they observe it. Platform admins and the
+// services that own the objects write tuples; service accounts only reach
+// this with the identity.relations.write scope.
+func (u *writeRelationsUseCase) Execute(ctx context.Context, callerID string, writes, deletes []domain.RelationTuple) (string, error) {
+	caller, err := u.userRepo.GetByID(ctx, callerID)
+	if err != nil {
+		return "", err
+	}
+	if caller == nil || !caller.IsActive ||
+		(caller.Role != domain.UserRoleAdmin && caller.Role != domain.UserRoleService) {
+		return "", domain.ErrPermissionDenied
+	}
+
+	for _, t := range writes {
+		if err := u.schema.ValidateTuple(t); err != nil {
+			return "", err
+		}
+	}
+
+	var revision int64
+	err = u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		revision, err = u.tupleRepo.Write(ctx, writes, deletes)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return encodeConsistencyToken(revision), nil
+}
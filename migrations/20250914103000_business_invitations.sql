@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE business_invitations (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id  UUID NOT NULL,
+    email        TEXT,
+    phone        TEXT,
+    role         TEXT NOT NULL CHECK (role IN ('admin','master','viewer')),
+    token_hash   TEXT UNIQUE NOT NULL,
+    invited_by   UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    accepted_by  UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    accepted_at  TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (email IS NOT NULL OR phone IS NOT NULL)
+);
+
+CREATE INDEX idx_invitations_business ON business_invitations(business_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS business_invitations;
+-- +goose StatementEnd
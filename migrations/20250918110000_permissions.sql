@@ -0,0 +1,76 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE permissions (
+    resource     TEXT NOT NULL,
+    action       TEXT NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (resource, action)
+);
+
+-- scope 'global' grants to users.role, scope 'membership' to user_business_memberships.role
+CREATE TABLE role_permissions (
+    scope     TEXT NOT NULL CHECK (scope IN ('global','membership')),
+    role      TEXT NOT NULL,
+    resource  TEXT NOT NULL,
+    action    TEXT NOT NULL,
+    PRIMARY KEY (scope, role, resource, action),
+    FOREIGN KEY (resource, action) REFERENCES permissions(resource, action) ON DELETE CASCADE
+);
+
+INSERT INTO permissions (resource, action, description) VALUES
+    ('business',    'read',   'View business profile'),
+    ('business',    'update', 'Edit business profile'),
+    ('business',    'delete', 'Delete business'),
+    ('staff',       'read',   'View staff list'),
+    ('staff',       'invite', 'Invite staff members'),
+    ('staff',       'manage', 'Change or remove staff memberships'),
+    ('appointment', 'read',   'View appointments'),
+    ('appointment', 'create', 'Create appointments'),
+    ('appointment', 'update', 'Reschedule or edit appointments'),
+    ('appointment', 'cancel', 'Cancel appointments'),
+    ('schedule',    'read',   'View working schedules'),
+    ('schedule',    'update', 'Edit working schedules'),
+    ('service',     'read',   'View services and prices'),
+    ('service',     'manage', 'Edit services and prices'),
+    ('client',      'read',   'View client cards'),
+    ('client',      'manage', 'Edit client cards'),
+    ('report',      'read',   'View reports'),
+    ('billing',     'read',   'View billing'),
+    ('billing',     'manage', 'Change plan and payment methods');
+
+-- platform admins may do everything
+INSERT INTO role_permissions (scope, role, resource, action)
+SELECT 'global', 'admin', resource, action FROM permissions;
+
+INSERT INTO role_permissions (scope, role, resource, action)
+SELECT 'membership', 'owner', resource, action FROM permissions;
+
+INSERT INTO role_permissions (scope, role, resource, action)
+SELECT 'membership', 'admin', resource, action FROM permissions
+WHERE (resource, action) NOT IN (('business', 'delete'), ('billing', 'manage'));
+
+INSERT INTO role_permissions (scope, role, resource, action) VALUES
+    ('membership', 'master', 'business',    'read'),
+    ('membership', 'master', 'staff',       'read'),
+    ('membership', 'master', 'appointment', 'read'),
+    ('membership', 'master', 'appointment', 'create'),
+    ('membership', 'master', 'appointment', 'update'),
+    ('membership', 'master', 'schedule',    'read'),
+    ('membership', 'master', 'schedule',    'update'),
+    ('membership', 'master', 'service',     'read'),
+    ('membership', 'master', 'client',      'read');
+
+INSERT INTO role_permissions (scope, role, resource, action)
+SELECT 'membership', 'viewer', resource, action FROM permissions
+WHERE action = 'read';
+
+CREATE INDEX idx_role_permissions_role ON role_permissions(scope, role);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+-- +goose StatementEnd
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE business_roles (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id  UUID NOT NULL,
+    name         TEXT NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    created_by   UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (business_id, name)
+);
+
+CREATE TABLE business_role_permissions (
+    role_id   UUID NOT NULL REFERENCES business_roles(id) ON DELETE CASCADE,
+    resource  TEXT NOT NULL,
+    action    TEXT NOT NULL,
+    PRIMARY KEY (role_id, resource, action),
+    FOREIGN KEY (resource, action) REFERENCES permissions(resource, action) ON DELETE CASCADE
+);
+
+-- a membership holds either a built-in role or a custom one
+ALTER TABLE user_business_memberships
+    ALTER COLUMN role DROP NOT NULL,
+    ADD COLUMN custom_role_id UUID REFERENCES business_roles(id) ON DELETE RESTRICT,
+    ADD CONSTRAINT chk_membership_role CHECK ((role IS NULL) <> (custom_role_id IS NULL));
+
+CREATE INDEX idx_business_roles_business ON business_roles(business_id);
+CREATE INDEX idx_memberships_custom_role ON user_business_memberships(custom_role_id);
+
+CREATE TRIGGER trg_business_roles_updated
+    BEFORE UPDATE ON business_roles
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_business_roles_updated ON business_roles;
+
+DELETE FROM user_business_memberships WHERE custom_role_id IS NOT NULL;
+ALTER TABLE user_business_memberships
+    DROP CONSTRAINT IF EXISTS chk_membership_role,
+    DROP COLUMN IF EXISTS custom_role_id,
+    ALTER COLUMN role SET NOT NULL;
+
+DROP TABLE IF EXISTS business_role_permissions;
+DROP TABLE IF EXISTS business_roles;
+-- +goose StatementEnd
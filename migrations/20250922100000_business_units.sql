@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- business hierarchy: organizations with child locations. Businesses without a
+-- row here are standalone.
+CREATE TABLE business_units (
+    id          UUID PRIMARY KEY,
+    parent_id   UUID REFERENCES business_units(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (parent_id IS NULL OR parent_id <> id)
+);
+
+CREATE INDEX idx_business_units_parent ON business_units(parent_id);
+
+CREATE TRIGGER trg_business_units_updated
+    BEFORE UPDATE ON business_units
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_business_units_updated ON business_units;
+DROP TABLE IF EXISTS business_units;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE audit_log (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor_id        UUID,
+    action          TEXT NOT NULL,
+    business_id     UUID,
+    target_user_id  UUID,
+    details         JSONB NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_audit_log_business ON audit_log(business_id, created_at);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_id, created_at);
+
+CREATE TABLE ownership_transfers (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id   UUID NOT NULL,
+    from_user_id  UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status        TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','accepted','cancelled')),
+    expires_at    TIMESTAMPTZ NOT NULL,
+    resolved_at   TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- at most one open transfer per business
+CREATE UNIQUE INDEX idx_ownership_transfers_pending ON ownership_transfers(business_id) WHERE status = 'pending';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ownership_transfers;
+DROP TABLE IF EXISTS audit_log;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every write to relation_tuples takes the next revision; clients get it back
+-- as a consistency token
+CREATE SEQUENCE relation_tuple_revision;
+
+CREATE TABLE relation_tuples (
+    object_type       TEXT NOT NULL,
+    object_id         TEXT NOT NULL,
+    relation          TEXT NOT NULL,
+    subject_type      TEXT NOT NULL,
+    subject_id        TEXT NOT NULL,
+    subject_relation  TEXT NOT NULL DEFAULT '',
+    created_revision  BIGINT NOT NULL,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (object_type, object_id, relation, subject_type, subject_id, subject_relation)
+);
+
+CREATE INDEX idx_relation_tuples_subject ON relation_tuples(subject_type, subject_id, subject_relation);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS relation_tuples;
+DROP SEQUENCE IF EXISTS relation_tuple_revision;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE policy_conditions (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id     UUID,
+    resource        TEXT NOT NULL DEFAULT '',
+    action          TEXT NOT NULL DEFAULT '',
+    role_scope      TEXT NOT NULL DEFAULT '' CHECK (role_scope IN ('','global','membership')),
+    role            TEXT NOT NULL DEFAULT '',
+    custom_role_id  UUID REFERENCES business_roles(id) ON DELETE CASCADE,
+    expression      TEXT NOT NULL,
+    mode            TEXT NOT NULL DEFAULT 'enforce' CHECK (mode IN ('enforce','shadow')),
+    description     TEXT NOT NULL DEFAULT '',
+    created_by      UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- a condition must target at least a permission or a role
+    CHECK (resource <> '' OR role <> '' OR custom_role_id IS NOT NULL)
+);
+
+CREATE INDEX idx_policy_conditions_business ON policy_conditions(business_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS policy_conditions;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE decision_log (
+    id                   BIGSERIAL PRIMARY KEY,
+    user_id              UUID NOT NULL,
+    business_id          UUID,
+    resource             TEXT NOT NULL,
+    action               TEXT NOT NULL,
+    allowed              BOOLEAN NOT NULL,
+    reason               TEXT NOT NULL DEFAULT '',
+    matched_by           TEXT NOT NULL DEFAULT '',
+    client_ip            TEXT NOT NULL DEFAULT '',
+    resource_attributes  JSONB NOT NULL DEFAULT '{}',
+    latency_us           BIGINT NOT NULL DEFAULT 0,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_decision_log_user ON decision_log(user_id, created_at DESC);
+CREATE INDEX idx_decision_log_business ON decision_log(business_id, created_at DESC);
+CREATE INDEX idx_decision_log_created ON decision_log(created_at DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS decision_log;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE permission_namespaces (
+    namespace   TEXT PRIMARY KEY,
+    owner_id    UUID REFERENCES users(id) ON DELETE SET NULL,
+    version     INT NOT NULL DEFAULT 0,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- permissions seeded by migrations; owned by no service
+INSERT INTO permission_namespaces (namespace, version) VALUES ('core', 1);
+
+ALTER TABLE permissions
+    ADD COLUMN namespace TEXT NOT NULL DEFAULT 'core' REFERENCES permission_namespaces(namespace),
+    ADD COLUMN deprecated_at TIMESTAMPTZ;
+
+CREATE INDEX idx_permissions_namespace ON permissions(namespace);
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('client','owner','admin','master','service'));
+
+CREATE TRIGGER trg_permission_namespaces_updated
+    BEFORE UPDATE ON permission_namespaces
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trg_permission_namespaces_updated ON permission_namespaces;
+
+DELETE FROM users WHERE role = 'service';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('client','owner','admin','master'));
+
+DELETE FROM permissions WHERE namespace <> 'core';
+ALTER TABLE permissions
+    DROP COLUMN IF EXISTS deprecated_at,
+    DROP COLUMN IF EXISTS namespace;
+
+DROP TABLE IF EXISTS permission_namespaces;
+-- +goose StatementEnd
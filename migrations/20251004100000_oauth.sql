@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE oauth_clients (
+    client_id      TEXT PRIMARY KEY,
+    secret_hash    TEXT,
+    name           TEXT NOT NULL,
+    redirect_uris  TEXT[] NOT NULL DEFAULT '{}',
+    scopes         TEXT[] NOT NULL DEFAULT '{}',
+    created_by     UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE oauth_authorization_codes (
+    code_hash       TEXT PRIMARY KEY,
+    client_id       TEXT NOT NULL REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri    TEXT NOT NULL,
+    scope           TEXT NOT NULL DEFAULT '',
+    code_challenge  TEXT NOT NULL,
+    auth_time       TIMESTAMPTZ NOT NULL,
+    expires_at      TIMESTAMPTZ NOT NULL,
+    used_at         TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_oauth_codes_expires ON oauth_authorization_codes(expires_at);
+
+CREATE TABLE oauth_consents (
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_id   TEXT NOT NULL REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+    scopes      TEXT[] NOT NULL DEFAULT '{}',
+    granted_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, client_id)
+);
+
+ALTER TABLE refresh_tokens
+    ADD COLUMN client_id TEXT REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+    ADD COLUMN scope TEXT NOT NULL DEFAULT '';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM refresh_tokens WHERE client_id IS NOT NULL;
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS scope,
+    DROP COLUMN IF EXISTS client_id;
+
+DROP TABLE IF EXISTS oauth_consents;
+DROP TABLE IF EXISTS oauth_authorization_codes;
+DROP TABLE IF EXISTS oauth_clients;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE oauth_authorization_codes
+    ADD COLUMN nonce TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE oauth_authorization_codes
+    DROP COLUMN IF EXISTS nonce;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- service accounts act through a users row with role 'service', so ownership,
+-- audit and permission checks keep working on user ids
+CREATE TABLE service_accounts (
+    client_id    TEXT PRIMARY KEY,
+    user_id      UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    name         TEXT NOT NULL,
+    secret_hash  TEXT NOT NULL,
+    scopes       TEXT[] NOT NULL DEFAULT '{}',
+    created_by   UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM users WHERE id IN (SELECT user_id FROM service_accounts);
+DROP TABLE IF EXISTS service_accounts;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE oauth_device_codes (
+    device_code_hash  TEXT PRIMARY KEY,
+    user_code         TEXT NOT NULL UNIQUE,
+    client_id         TEXT NOT NULL REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+    scope             TEXT NOT NULL DEFAULT '',
+    user_id           UUID REFERENCES users(id) ON DELETE CASCADE,
+    status            TEXT NOT NULL DEFAULT 'pending'
+                      CHECK (status IN ('pending','approved','denied','consumed')),
+    interval_seconds  INT NOT NULL,
+    last_polled_at    TIMESTAMPTZ,
+    expires_at        TIMESTAMPTZ NOT NULL,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_oauth_device_codes_expires ON oauth_device_codes(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS oauth_device_codes;
+-- +goose StatementEnd
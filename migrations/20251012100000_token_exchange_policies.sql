@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE token_exchange_policies (
+    client_id    TEXT NOT NULL REFERENCES service_accounts(client_id) ON DELETE CASCADE,
+    audience     TEXT NOT NULL,
+    scopes       TEXT[] NOT NULL DEFAULT '{}',
+    -- global roles allowed to act on behalf of a subject; empty disables delegation
+    actor_roles  TEXT[] NOT NULL DEFAULT '{}',
+    created_by   UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (client_id, audience)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS token_exchange_policies;
+-- +goose StatementEnd
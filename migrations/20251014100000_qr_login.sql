@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE qr_login_sessions (
+    id                TEXT PRIMARY KEY,
+    poll_secret_hash  TEXT NOT NULL,
+    status            TEXT NOT NULL DEFAULT 'pending'
+                      CHECK (status IN ('pending','scanned','approved','denied','consumed')),
+    user_id           UUID REFERENCES users(id) ON DELETE CASCADE,
+    ip_address        TEXT NOT NULL DEFAULT '',
+    user_agent        TEXT NOT NULL DEFAULT '',
+    expires_at        TIMESTAMPTZ NOT NULL,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_qr_login_sessions_expires ON qr_login_sessions(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS qr_login_sessions;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE shared_devices (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id  UUID NOT NULL,
+    name         TEXT NOT NULL,
+    secret_hash  TEXT NOT NULL,
+    created_by   UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX idx_shared_devices_business ON shared_devices(business_id);
+
+CREATE TABLE shared_device_pins (
+    device_id        UUID NOT NULL REFERENCES shared_devices(id) ON DELETE CASCADE,
+    user_id          UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pin_hash         TEXT NOT NULL,
+    failed_attempts  INT NOT NULL DEFAULT 0,
+    locked_until     TIMESTAMPTZ,
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (device_id, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS shared_device_pins;
+DROP TABLE IF EXISTS shared_devices;
+-- +goose StatementEnd
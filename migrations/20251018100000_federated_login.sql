@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE linked_identities (
+    provider_id    TEXT NOT NULL,
+    subject        TEXT NOT NULL,
+    user_id        UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email          TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_login_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (provider_id, subject),
+    UNIQUE (user_id, provider_id)
+);
+
+CREATE TABLE federated_login_states (
+    state_hash     TEXT PRIMARY KEY,
+    provider_id    TEXT NOT NULL,
+    redirect_uri   TEXT NOT NULL,
+    code_verifier  TEXT NOT NULL,
+    nonce          TEXT NOT NULL,
+    expires_at     TIMESTAMPTZ NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_federated_login_states_expires ON federated_login_states(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS federated_login_states;
+DROP TABLE IF EXISTS linked_identities;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- set when a signed-in user links a provider rather than signing in with it
+ALTER TABLE federated_login_states
+    ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE TABLE account_merge_requests (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_user_id  UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_user_id  UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider_id     TEXT NOT NULL,
+    subject         TEXT NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','completed')),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (source_user_id <> target_user_id)
+);
+
+CREATE UNIQUE INDEX idx_account_merge_requests_pending ON account_merge_requests(source_user_id, target_user_id)
+    WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS account_merge_requests;
+ALTER TABLE federated_login_states DROP COLUMN IF EXISTS user_id;
+-- +goose StatementEnd
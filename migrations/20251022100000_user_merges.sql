@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a merged user stays as an inactive row; this maps it to the survivor
+CREATE TABLE user_merges (
+    source_user_id  UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    target_user_id  UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    merged_by       UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (source_user_id <> target_user_id)
+);
+
+CREATE INDEX idx_user_merges_target ON user_merges(target_user_id);
+
+-- feed of user changes other services follow by ID
+CREATE TABLE user_events (
+    id          BIGSERIAL PRIMARY KEY,
+    type        TEXT NOT NULL,
+    user_id     UUID NOT NULL,
+    data        JSONB NOT NULL DEFAULT '{}',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_events;
+DROP TABLE IF EXISTS user_merges;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- guests sign in with a device secret (a linked identity) and have no
+-- password until they upgrade in place
+ALTER TABLE users ADD COLUMN is_guest BOOLEAN NOT NULL DEFAULT false;
+
+-- an empty phone used to be stored as '' and took the unique slot
+UPDATE users SET phone = NULL WHERE phone = '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN IF EXISTS is_guest;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- self-registered users kept inactive until an admin decides
+CREATE TABLE pending_registrations (
+    user_id     UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pending_registrations;
+-- +goose StatementEnd
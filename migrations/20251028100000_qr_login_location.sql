@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE qr_login_sessions ADD COLUMN location TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE qr_login_sessions DROP COLUMN IF EXISTS location;
+-- +goose StatementEnd
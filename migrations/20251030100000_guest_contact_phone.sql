@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a guest's phone is unverified and must not take the unique phone of an
+-- account
+ALTER TABLE users ADD COLUMN contact_phone TEXT NOT NULL DEFAULT '';
+
+UPDATE users SET contact_phone = phone, phone = NULL
+WHERE is_guest AND phone IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE users u SET phone = u.contact_phone
+WHERE u.is_guest AND u.contact_phone <> '' AND u.phone IS NULL
+AND NOT EXISTS (SELECT 1 FROM users o WHERE o.phone = u.contact_phone);
+
+ALTER TABLE users DROP COLUMN IF EXISTS contact_phone;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the current revision lives in a row updated by each write transaction, so
+-- it becomes visible together with the tuples it covers, on replicas too. A
+-- sequence advances outside the transaction and says nothing about them.
+CREATE TABLE relation_tuple_head (
+    id        BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    revision  BIGINT NOT NULL
+);
+
+INSERT INTO relation_tuple_head (revision)
+SELECT CASE WHEN is_called THEN last_value ELSE 0 END FROM relation_tuple_revision;
+
+DROP SEQUENCE relation_tuple_revision;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE SEQUENCE relation_tuple_revision;
+
+SELECT setval('relation_tuple_revision', GREATEST(revision, 1), revision > 0) FROM relation_tuple_head;
+
+DROP TABLE IF EXISTS relation_tuple_head;
+-- +goose StatementEnd
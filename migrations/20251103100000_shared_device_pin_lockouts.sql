@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- lockouts since the PIN was set; each lasts longer and enough of them
+-- disable the PIN until it is set again
+ALTER TABLE shared_device_pins ADD COLUMN lockouts INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE shared_device_pins DROP COLUMN IF EXISTS lockouts;
+-- +goose StatementEnd
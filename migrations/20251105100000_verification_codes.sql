@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- one-time codes sent to a user's email or phone to confirm a sensitive
+-- action. They work whatever way the user signs in.
+CREATE TABLE verification_codes (
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose     TEXT NOT NULL,
+    code_hash   TEXT NOT NULL,
+    attempts    INT NOT NULL DEFAULT 0,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, purpose)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS verification_codes;
+-- +goose StatementEnd